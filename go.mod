@@ -6,6 +6,8 @@ require (
 	fyne.io/fyne/v2 v2.6.3
 	github.com/ncruces/zenity v0.10.14
 	github.com/pdfcpu/pdfcpu v0.11.0
+	golang.org/x/image v0.27.0
+	software.sslmate.com/src/go-pkcs12 v0.5.0
 )
 
 require (
@@ -47,7 +49,6 @@ require (
 	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/yuin/goldmark v1.7.8 // indirect
 	golang.org/x/crypto v0.38.0 // indirect
-	golang.org/x/image v0.27.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
@@ -0,0 +1,207 @@
+// Package cli runs PDF Toolbox operations from the command line
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"pdf-toolbox/internal/pdf"
+	"pdf-toolbox/pkg/models"
+)
+
+// command is a subcommand of the command line
+type command struct {
+	usage string
+	run   func(s *pdf.Service, args []string) error
+}
+
+const (
+	insertUsage = "insert [-after] [-page N] (-blank N | -from source.pdf [-pages 1-3,5]) input.pdf output.pdf"
+	fontsUsage  = "fonts [-json] input.pdf"
+)
+
+var commands = map[string]command{
+	"insert": {usage: insertUsage, run: runInsert},
+	"fonts":  {usage: fontsUsage, run: runFonts},
+}
+
+// IsCommand reports whether arg names a command or asks for help, as opposed
+// to e.g. a file to open in the graphical interface
+func IsCommand(arg string) bool {
+	_, ok := commands[arg]
+	return ok || isHelp(arg)
+}
+
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "--help"
+}
+
+// Run runs the subcommand named by args[0] and returns the exit code
+func Run(args []string) int {
+	if len(args) == 0 || isHelp(args[0]) {
+		usage(os.Stdout)
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		usage(os.Stderr)
+		return 2
+	}
+	if err := cmd.run(pdf.NewService(), args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// usage lists the commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: pdf-toolbox [command] [flags] [files]")
+	fmt.Fprintln(w, "Without a command the graphical interface starts. Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(w, "  pdf-toolbox", commands[name].usage)
+	}
+}
+
+// newFlagSet returns the flag set of a command, printing its usage on errors
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pdf-toolbox", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func runInsert(s *pdf.Service, args []string) error {
+	fs := newFlagSet("insert", insertUsage)
+	page := fs.Int("page", 1, "reference page of the input")
+	after := fs.Bool("after", false, "insert after the reference page instead of before it")
+	blank := fs.Int("blank", 0, "number of blank pages to insert, sized like the reference page")
+	source := fs.String("from", "", "PDF to insert pages from")
+	pages := fs.String("pages", "", "pages of the source PDF to insert, e.g. 1-3,5; all when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("insert needs an input and an output file")
+	}
+	if (*blank > 0) == (*source != "") {
+		return fmt.Errorf("give either -blank or -from")
+	}
+
+	config := models.InsertConfig{
+		InputFile:  fs.Arg(0),
+		OutputFile: fs.Arg(1),
+		Page:       *page,
+		After:      *after,
+		BlankPages: *blank,
+		SourceFile: *source,
+	}
+	if *pages != "" {
+		if *source == "" {
+			return fmt.Errorf("-pages needs -from")
+		}
+		selected, err := sourcePages(s, *source, *pages)
+		if err != nil {
+			return err
+		}
+		config.SourcePages = selected
+	}
+
+	if err := s.Insert(config); err != nil {
+		return err
+	}
+	fmt.Println("Wrote", config.OutputFile)
+	return nil
+}
+
+// sourcePages returns the pages of a PDF selected by a page range, in order
+func sourcePages(s *pdf.Service, path, pageRange string) ([]int, error) {
+	count, err := s.GetPageCount(path)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := api.ParsePageSelection(pageRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page range %q: %w", pageRange, err)
+	}
+	set, err := api.PagesForPageSelection(count, selection, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page range %q: %w", pageRange, err)
+	}
+	var selected []int
+	for page, ok := range set {
+		if ok {
+			selected = append(selected, page)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("page range %q selects no pages of %s", pageRange, path)
+	}
+	sort.Ints(selected)
+	return selected, nil
+}
+
+func runFonts(s *pdf.Service, args []string) error {
+	fs := newFlagSet("fonts", fontsUsage)
+	asJSON := fs.Bool("json", false, "print the fonts as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("fonts needs an input file")
+	}
+
+	fonts, err := s.ListFonts(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if fonts == nil {
+			fonts = []models.Font{}
+		}
+		data, err := json.MarshalIndent(fonts, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tENCODING\tEMBEDDED\tPAGES")
+	for _, f := range fonts {
+		embedded := "no"
+		switch {
+		case f.Subset:
+			embedded = "subset"
+		case f.Embedded:
+			embedded = "yes"
+		}
+		pages := make([]string, len(f.Pages))
+		for i, p := range f.Pages {
+			pages[i] = fmt.Sprint(p)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Name, f.Type, f.Encoding, embedded, strings.Join(pages, ","))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,185 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+const anyChoice = "Any"
+
+func (a *App) makeAnnotationsTab() fyne.CanvasObject {
+	var selectedFile string
+	var annotations []models.Annotation
+	fileLabel := widget.NewLabel("No file selected")
+	countLabel := widget.NewLabel("")
+
+	typeSelect := widget.NewSelect([]string{anyChoice}, nil)
+	typeSelect.SetSelected(anyChoice)
+	authorSelect := widget.NewSelect([]string{anyChoice}, nil)
+	authorSelect.SetSelected(anyChoice)
+
+	matches := func(an models.Annotation) bool {
+		return (typeSelect.Selected == anyChoice || an.Type == typeSelect.Selected) &&
+			(authorSelect.Selected == anyChoice || an.Author == authorSelect.Selected)
+	}
+
+	list := widget.NewList(
+		func() int { return len(annotations) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			an := annotations[i]
+			text := fmt.Sprintf("p.%d  %s", an.Page, an.Type)
+			if an.Author != "" {
+				text += "  by " + an.Author
+			}
+			if contents := strings.Join(strings.Fields(an.Contents), " "); contents != "" {
+				text += ": " + contents
+			}
+			if !matches(an) {
+				text = "   " + text
+			} else {
+				text = "▸ " + text
+			}
+			o.(*widget.Label).SetText(text)
+		},
+	)
+	listArea := container.NewScroll(list)
+	listArea.SetMinSize(fyne.NewSize(0, 300))
+
+	updateCount := func() {
+		n := 0
+		for _, an := range annotations {
+			if matches(an) {
+				n++
+			}
+		}
+		countLabel.SetText(fmt.Sprintf("%d annotation(s), %d matching the filters", len(annotations), n))
+		list.Refresh()
+	}
+	typeSelect.OnChanged = func(string) { updateCount() }
+	authorSelect.OnChanged = func(string) { updateCount() }
+
+	load := func() {
+		var err error
+		annotations, err = a.pdfService.ListAnnotations(selectedFile)
+		if err != nil {
+			annotations = nil
+			countLabel.SetText("Error: " + err.Error())
+			list.Refresh()
+			return
+		}
+		var types, authors []string
+		for _, an := range annotations {
+			if !slices.Contains(types, an.Type) {
+				types = append(types, an.Type)
+			}
+			if an.Author != "" && !slices.Contains(authors, an.Author) {
+				authors = append(authors, an.Author)
+			}
+		}
+		slices.Sort(types)
+		slices.Sort(authors)
+		typeSelect.Options = append([]string{anyChoice}, types...)
+		authorSelect.Options = append([]string{anyChoice}, authors...)
+		typeSelect.SetSelected(anyChoice)
+		authorSelect.SetSelected(anyChoice)
+		updateCount()
+	}
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			load()
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	exportBtn := widget.NewButton("Export JSON/CSV", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_annotations.json"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{
+			{Name: "JSON", Patterns: []string{"*.json"}},
+			{Name: "CSV", Patterns: []string{"*.csv"}},
+		})
+		if err != nil || outputFile == "" {
+			return
+		}
+		if err := a.pdfService.ExportAnnotations(selectedFile, outputFile); err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		dialog.ShowInformation("Success", "Annotations exported successfully!", a.window)
+	})
+
+	removeBtn := widget.NewButton("Remove Matching", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		config := models.RemoveAnnotationsConfig{InputFile: selectedFile}
+		if typeSelect.Selected != anyChoice {
+			config.Types = []string{typeSelect.Selected}
+		}
+		if authorSelect.Selected != anyChoice {
+			config.Authors = []string{authorSelect.Selected}
+		}
+		if len(config.Types) == 0 && len(config.Authors) == 0 {
+			dialog.ShowError(fmt.Errorf("please choose a type or author to remove"), a.window)
+			return
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_cleaned.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+		config.OutputFile = outputFile
+
+		go func() {
+			if err := a.pdfService.RemoveAnnotations(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "Annotations removed successfully!", a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("List, export and remove comments, highlights and other annotations"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewGridWithColumns(4,
+			widget.NewLabel("Type:"), typeSelect,
+			widget.NewLabel("Author:"), authorSelect,
+		),
+		countLabel,
+		listArea,
+		container.NewHBox(exportBtn, removeBtn),
+	)
+}
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
     "unicode"
 
 	"fyne.io/fyne/v2"
@@ -24,6 +25,10 @@ type App struct {
 	fyneApp    fyne.App
 	window     fyne.Window
 	pdfService *pdf.Service
+	// sanitized maps copies sanitized on import to their originals; worker
+	// goroutines read it while the UI adds to it
+	sanitized   map[string]string
+	sanitizedMu sync.Mutex
 }
 
 // NewApp creates a new GUI application
@@ -31,6 +36,7 @@ func NewApp() *App {
 	return &App{
         fyneApp:    app.NewWithID("com.dallakyan.pdftoolbox"),
 		pdfService: pdf.NewService(),
+		sanitized:  map[string]string{},
 	}
 }
 
@@ -40,6 +46,7 @@ func (a *App) Run() {
 	a.window.Resize(fyne.NewSize(800, 600))
 	a.window.SetContent(a.makeUI())
 	a.window.ShowAndRun()
+	a.removeSanitizedCopies()
 }
 
 func (a *App) makeUI() fyne.CanvasObject {
@@ -48,11 +55,29 @@ func (a *App) makeUI() fyne.CanvasObject {
 		container.NewTabItem("Merge PDFs", a.makeMergeTab()),
 		container.NewTabItem("Delete Pages", a.makeDeletePagesTab()),
 		container.NewTabItem("Images to PDF", a.makeImagesToPDFTab()),
+		container.NewTabItem("Page Numbers", a.makePageNumbersTab()),
+		container.NewTabItem("Header/Footer", a.makeHeaderFooterTab()),
+		container.NewTabItem("N-up", a.makeNUpTab()),
+		container.NewTabItem("Booklet", a.makeBookletTab()),
+		container.NewTabItem("Crop", a.makeCropTab()),
+		container.NewTabItem("Resize", a.makeResizeTab()),
+		container.NewTabItem("Insert", a.makeInsertTab()),
+		container.NewTabItem("Replace", a.makeReplaceTab()),
+		container.NewTabItem("Forms", a.makeFormsTab()),
+		container.NewTabItem("Flatten", a.makeFlattenTab()),
+		container.NewTabItem("Annotations", a.makeAnnotationsTab()),
+		container.NewTabItem("Redact", a.makeRedactTab()),
+		container.NewTabItem("Sign", a.makeSignTab()),
+		container.NewTabItem("PDF/A", a.makePDFATab()),
+		container.NewTabItem("Grayscale", a.makeGrayscaleTab()),
+		container.NewTabItem("Sanitize", a.makeSanitizeTab()),
+		container.NewTabItem("Page Labels", a.makePageLabelsTab()),
 		container.NewTabItem("Info", a.makeInfoTab()),
 	)
 
 	return container.NewBorder(
-		widget.NewLabel("PDF Toolbox - Split, Merge, and Manage PDFs"),
+		container.NewBorder(nil, nil, nil, a.makeSanitizeImportsCheck(),
+			widget.NewLabel("PDF Toolbox - Split, Merge, and Manage PDFs")),
 		nil, nil, nil,
 		tabs,
 	)
@@ -66,14 +91,58 @@ func (a *App) makeSplitTab() fyne.CanvasObject {
 	pagesEntry.SetPlaceHolder("Pages per file (e.g., 5)")
 	pagesEntry.Text = "5"
 
+	sheetSelect := widget.NewSelect(nupSheetChoices, nil)
+	sheetSelect.SetSelected("1")
+
 	outputDirLabel := widget.NewLabel("Output: Same as input file")
 	var outputDir string
 
+	// Colour mode splits into colour and mono pages for separate printers
+	duplexCheck := widget.NewCheck("Double-sided (keep both sides of a sheet together)", nil)
+	colorResultLabel := widget.NewLabel("")
+	colorResultLabel.Wrapping = fyne.TextWrapWord
+	analyzeBtn := widget.NewButton("Find Colour Pages", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		pages, colored, err := a.pdfService.ColorPages(selectedFile)
+		if err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		if len(colored) == 0 {
+			colorResultLabel.SetText(fmt.Sprintf("All %d pages are mono", pages))
+			return
+		}
+		colorResultLabel.SetText(fmt.Sprintf("%d of %d pages are colour: %s", len(colored), pages, pageList(colored)))
+	})
+	countBox := container.NewVBox(
+		widget.NewLabel("Pages per file:"),
+		pagesEntry,
+		widget.NewLabel("Pages per sheet (N-up on A4; pages per file then counts sheets):"),
+		sheetSelect,
+	)
+	colorBox := container.NewVBox(duplexCheck, analyzeBtn, colorResultLabel)
+	colorBox.Hide()
+	modeRadio := widget.NewRadioGroup([]string{"By page count", "Colour and mono pages"}, func(mode string) {
+		if mode == "By page count" {
+			countBox.Show()
+			colorBox.Hide()
+		} else {
+			countBox.Hide()
+			colorBox.Show()
+		}
+	})
+	modeRadio.Horizontal = true
+	modeRadio.SetSelected("By page count")
+
     selectFileBtn := widget.NewButton("Browse PDF File", func() {
         path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
         if err == nil && path != "" {
             selectedFile = path
             fileLabel.SetText(filepath.Base(selectedFile))
+            colorResultLabel.SetText("")
         }
     })
 	
@@ -100,6 +169,35 @@ func (a *App) makeSplitTab() fyne.CanvasObject {
 			return
 		}
 
+		if modeRadio.Selected != "By page count" {
+			dir := outputDir
+			if dir == "" {
+				dir = filepath.Dir(a.originalPath(selectedFile))
+			}
+			duplex := duplexCheck.Checked
+			go func() {
+				split, err := a.pdfService.SplitByColor(selectedFile, dir, duplex)
+				if err != nil {
+					a.showError(err, selectedFile)
+					return
+				}
+				var b strings.Builder
+				if len(split.ColorPages) == 0 {
+					fmt.Fprintf(&b, "All %d pages are mono", split.Pages)
+				} else {
+					fmt.Fprintf(&b, "%d of %d pages are colour: %s", len(split.ColorPages), split.Pages, pageList(split.ColorPages))
+				}
+				for _, f := range []string{split.ColorFile, split.MonoFile, split.MapFile} {
+					if f != "" {
+						fmt.Fprintf(&b, "\nSaved %s", filepath.Base(f))
+					}
+				}
+				colorResultLabel.SetText(b.String())
+				dialog.ShowInformation("Success", "PDF split into colour and mono pages; the page map shows how to collate the printed sheets.", a.window)
+			}()
+			return
+		}
+
 		pagesPerFile, err := strconv.Atoi(pagesEntry.Text)
 		if err != nil || pagesPerFile < 1 {
 			dialog.ShowError(fmt.Errorf("please enter a valid number of pages"), a.window)
@@ -107,18 +205,21 @@ func (a *App) makeSplitTab() fyne.CanvasObject {
 		}
 
 		if outputDir == "" {
-			outputDir = filepath.Dir(selectedFile)
+			outputDir = filepath.Dir(a.originalPath(selectedFile))
 		}
 
 		config := models.SplitConfig{
 			PagesPerFile: pagesPerFile,
 			OutputDir:    outputDir,
 		}
+		if n, _ := strconv.Atoi(sheetSelect.Selected); n > 1 {
+			config.NUp = models.NUpOptions{PagesPerSheet: n, PaperSize: models.PaperA4, Border: true}
+		}
 
 		go func() {
 			err := a.pdfService.Split(config, selectedFile)
 			if err != nil {
-				dialog.ShowError(err, a.window)
+				a.showError(err, selectedFile)
 			} else {
 				dialog.ShowInformation("Success", "PDF split successfully!", a.window)
 			}
@@ -130,8 +231,9 @@ func (a *App) makeSplitTab() fyne.CanvasObject {
 		selectFileBtn,
 		fileLabel,
 		previewBtn,
-		widget.NewLabel("Pages per file:"),
-		pagesEntry,
+		modeRadio,
+		countBox,
+		colorBox,
 		selectOutputBtn,
 		outputDirLabel,
 		splitBtn,
@@ -156,6 +258,8 @@ func (a *App) makeMergeTab() fyne.CanvasObject {
     }
     // ensure list area is visible enough by wrapping in a scroll with min size
     countLabel := widget.NewLabel("0 files selected")
+	normalizeSelect := widget.NewSelect(append([]string{"Keep original sizes"}, resizePapers...), nil)
+	normalizeSelect.SetSelected("Keep original sizes")
     // track focus index for ↑/↓ based on last selected checkbox
     selectedIndex = -1
 
@@ -235,9 +339,12 @@ func (a *App) makeMergeTab() fyne.CanvasObject {
             outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
             if err != nil || outputFile == "" { return }
             config := models.MergeConfig{ InputFiles: inputs, OutputFile: outputFile }
+			if normalizeSelect.SelectedIndex() > 0 {
+				config.Normalize = models.ResizeOptions{PaperSize: models.PaperSize(normalizeSelect.Selected), Center: true}
+			}
             go func() {
                 err := a.pdfService.Merge(config)
-                if err != nil { dialog.ShowError(err, a.window) } else { _ = a.openFile(outputFile); dialog.ShowInformation("Success", "PDFs merged successfully!", a.window) }
+                if err != nil { a.showError(err, config.InputFiles...) } else { _ = a.openFile(outputFile); dialog.ShowInformation("Success", "PDFs merged successfully!", a.window) }
             }()
         }
         if hasSelected {
@@ -257,6 +364,7 @@ func (a *App) makeMergeTab() fyne.CanvasObject {
         countLabel,
 		clearBtn,
         listArea,
+		container.NewGridWithColumns(2, widget.NewLabel("Normalize page sizes:"), normalizeSelect),
 		mergeBtn,
 	)
 }
@@ -278,6 +386,8 @@ func (a *App) makeDeletePagesTab() fyne.CanvasObject {
             // Get page count
             if count, err := a.pdfService.GetPageCount(selectedFile); err == nil {
                 fileLabel.SetText(fmt.Sprintf("%s (%d pages)", filepath.Base(selectedFile), count))
+            } else {
+                a.offerRepair(selectedFile, err)
             }
         }
     })
@@ -298,7 +408,7 @@ func (a *App) makeDeletePagesTab() fyne.CanvasObject {
 			return
 		}
 
-		pages, err := parsePageRange(pagesEntry.Text)
+		pages, err := a.parsePages(pagesEntry.Text, selectedFile)
 		if err != nil {
 			dialog.ShowError(fmt.Errorf("invalid page range: %w", err), a.window)
 			return
@@ -324,7 +434,7 @@ func (a *App) makeDeletePagesTab() fyne.CanvasObject {
 			go func() {
 				err := a.pdfService.DeletePages(config)
 				if err != nil {
-					dialog.ShowError(err, a.window)
+					a.showError(err, config.InputFile)
 				} else {
                     _ = a.openFile(outputFile)
                     dialog.ShowInformation("Success", "Pages extracted successfully!", a.window)
@@ -351,6 +461,8 @@ func (a *App) makeImagesToPDFTab() fyne.CanvasObject {
 	
     var sortable *SortableList
     sortable = NewSortableList(&selectedFiles, &selectedMap, nil)
+    thumbs := newImageThumbnails()
+    sortable.thumbnail = thumbs.object
     sortable.onChange = func(){
         if idx := sortable.ActiveIndex(); idx >= 0 && idx < len(selectedFiles) {
             selectedIndex = idx
@@ -412,11 +524,67 @@ func (a *App) makeImagesToPDFTab() fyne.CanvasObject {
 		}
 	})
 
+    // Page layout options
+    pageSizes := map[string]models.PaperSize{"A4": models.PaperA4, "Letter": models.PaperLetter, "Match image": models.PaperImage}
+    pageSizeSelect := widget.NewSelect([]string{"A4", "Letter", "Match image"}, nil)
+    pageSizeSelect.SetSelected("A4")
+    orientations := map[string]models.Orientation{"Auto": models.OrientationAuto, "Portrait": models.OrientationPortrait, "Landscape": models.OrientationLandscape}
+    orientationSelect := widget.NewSelect([]string{"Auto", "Portrait", "Landscape"}, nil)
+    orientationSelect.SetSelected("Auto")
+    fitModes := map[string]models.FitMode{"Fit": models.FitContain, "Fill": models.FitFill, "Stretch": models.FitStretch, "Center": models.FitCenter}
+    fitSelect := widget.NewSelect([]string{"Fit", "Fill", "Stretch", "Center"}, nil)
+    fitSelect.SetSelected("Fit")
+    marginEntry := widget.NewEntry()
+    marginEntry.SetText("0")
+    dpiEntry := widget.NewEntry()
+    dpiEntry.SetText("72")
+    exifCheck := widget.NewCheck("Correct EXIF orientation", func(v bool) {
+        thumbs.applyEXIF = v
+        sortable.rebuild()
+    })
+    exifCheck.SetChecked(true)
+    // Scan enhancement steps for photographed documents
+    var enhance models.ScanEnhancement
+    enhanceChecks := container.NewHBox(
+        widget.NewLabel("Scan cleanup:"),
+        widget.NewCheck("Auto-crop", func(v bool) { enhance.AutoCrop = v }),
+        widget.NewCheck("Deskew", func(v bool) { enhance.Deskew = v }),
+        widget.NewCheck("Normalize contrast", func(v bool) { enhance.Contrast = v }),
+        widget.NewCheck("Grayscale", func(v bool) { enhance.Grayscale = v }),
+        widget.NewCheck("Black & white", func(v bool) { enhance.Threshold = v }),
+    )
+
+    buildConfig := func(inputs []string, outputFile string) (models.ImagesToPDFConfig, error) {
+        margin, err := strconv.ParseFloat(strings.TrimSpace(marginEntry.Text), 64)
+        if err != nil || margin < 0 {
+            return models.ImagesToPDFConfig{}, fmt.Errorf("please enter a valid margin in points")
+        }
+        dpi, err := strconv.Atoi(strings.TrimSpace(dpiEntry.Text))
+        if err != nil || dpi < 1 {
+            return models.ImagesToPDFConfig{}, fmt.Errorf("please enter a valid DPI")
+        }
+        return models.ImagesToPDFConfig{
+            InputFiles:  inputs,
+            OutputFile:  outputFile,
+            PageSize:    pageSizes[pageSizeSelect.Selected],
+            Orientation: orientations[orientationSelect.Selected],
+            FitMode:     fitModes[fitSelect.Selected],
+            Margin:      margin,
+            DPI:         dpi,
+            IgnoreEXIF:  !exifCheck.Checked,
+            Enhance:     enhance,
+        }, nil
+    }
+
     convertBtn := widget.NewButton("Convert to PDF", func() {
         if len(selectedFiles) == 0 {
             dialog.ShowError(fmt.Errorf("please select at least one image file"), a.window)
             return
         }
+        if _, err := buildConfig(nil, ""); err != nil {
+            dialog.ShowError(err, a.window)
+            return
+        }
         // Scope: all or only selected
         hasSelected := false
         var onlySelected []string
@@ -432,7 +600,8 @@ func (a *App) makeImagesToPDFTab() fyne.CanvasObject {
                 if combine {
                     outputFile, err := a.selectNativeSave("images.pdf", []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
                     if err != nil || outputFile == "" { return }
-                    go func(){ if err := a.pdfService.ImagesToPDF(inputs, outputFile); err != nil { dialog.ShowError(err, a.window) } else { _ = a.openFile(outputFile); dialog.ShowInformation("Success", "Images converted to PDF successfully!", a.window) } }()
+                    config, _ := buildConfig(inputs, outputFile)
+                    go func(){ if err := a.pdfService.ImagesToPDF(config); err != nil { dialog.ShowError(err, a.window) } else { _ = a.openFile(outputFile); dialog.ShowInformation("Success", "Images converted to PDF successfully!", a.window) } }()
                 } else {
                     // choose output directory
                     dir, err := a.selectNativeFolder()
@@ -442,7 +611,8 @@ func (a *App) makeImagesToPDFTab() fyne.CanvasObject {
                         for _, img := range inputs {
                             name := strings.TrimSuffix(filepath.Base(img), filepath.Ext(img)) + ".pdf"
                             out := filepath.Join(dir, name)
-                            if err := a.pdfService.ImagesToPDF([]string{img}, out); err != nil { dialog.ShowError(err, a.window); return }
+                            config, _ := buildConfig([]string{img}, out)
+                            if err := a.pdfService.ImagesToPDF(config); err != nil { dialog.ShowError(err, a.window); return }
                             lastOut = out
                         }
                         if lastOut != "" { _ = a.openFile(filepath.Dir(lastOut)) }
@@ -460,6 +630,12 @@ func (a *App) makeImagesToPDFTab() fyne.CanvasObject {
         container.NewHBox(selectFilesBtn, previewBtn, removeBtn, moveUpBtn, moveDownBtn),
         clearBtn,
         listArea,
+        container.NewGridWithColumns(5,
+            widget.NewLabel("Page size:"), widget.NewLabel("Orientation:"), widget.NewLabel("Fit:"), widget.NewLabel("Margin (pt):"), widget.NewLabel("DPI:"),
+            pageSizeSelect, orientationSelect, fitSelect, marginEntry, dpiEntry,
+        ),
+        exifCheck,
+        enhanceChecks,
 		convertBtn,
 	)
 }
@@ -469,18 +645,34 @@ func (a *App) makeInfoTab() fyne.CanvasObject {
 	fileLabel := widget.NewLabel("No file selected")
 	infoLabel := widget.NewLabel("")
 
+	var loadFile func(path string)
+	attachmentsSection, loadAttachments := a.makeAttachmentsSection(func(path string) { loadFile(path) })
+	signaturesSection, loadSignatures := a.makeSignaturesSection()
+	fontsSection, loadFonts := a.makeFontsSection()
+	validationSection, loadValidation := a.makeValidationSection(func(path string) { loadFile(path) })
+	loadFile = func(path string) {
+		selectedFile = path
+		fileLabel.SetText(filepath.Base(selectedFile))
+		if info, err := a.pdfService.GetInfo(selectedFile); err == nil {
+			infoText := fmt.Sprintf("File: %v\nPages: %v\nVersion: %v\nSize: %v bytes\nEncrypted: %v",
+				info["file"], info["pages"], info["version"], info["size"], info["encrypted"])
+			infoLabel.SetText(infoText)
+		} else {
+			infoLabel.SetText("Error: " + err.Error())
+			a.offerRepair(selectedFile, err)
+		}
+		// Validate and verify the file as picked: a copy sanitized on import
+		// is rewritten, which changes its structure and breaks signatures
+		loadValidation(a.originalPath(selectedFile))
+		loadFonts(selectedFile)
+		loadAttachments(selectedFile)
+		loadSignatures(a.originalPath(selectedFile))
+	}
+
     selectFileBtn := widget.NewButton("Browse PDF File", func() {
         path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
         if err == nil && path != "" {
-            selectedFile = path
-            fileLabel.SetText(filepath.Base(selectedFile))
-            if info, err := a.pdfService.GetInfo(selectedFile); err == nil {
-                infoText := fmt.Sprintf("File: %v\nPages: %v\nVersion: %v\nSize: %v bytes\nEncrypted: %v",
-                    info["file"], info["pages"], info["version"], info["size"], info["encrypted"])
-                infoLabel.SetText(infoText)
-            } else {
-                infoLabel.SetText("Error: " + err.Error())
-            }
+            loadFile(path)
         }
     })
 	
@@ -501,52 +693,47 @@ func (a *App) makeInfoTab() fyne.CanvasObject {
 		previewBtn,
 		widget.NewSeparator(),
 		infoLabel,
+		widget.NewSeparator(),
+		validationSection,
+		widget.NewSeparator(),
+		fontsSection,
+		widget.NewSeparator(),
+		attachmentsSection,
+		widget.NewSeparator(),
+		signaturesSection,
 	)
 }
 
-// parsePageRange parses a page range string like "1,3,5-7,10" into a slice of page numbers
-func parsePageRange(rangeStr string) ([]int, error) {
+// parsePageRange parses a page range string like "1,3,5-7,10" into a slice of page numbers.
+// labels holds the page labels of the document, labels[0] being page 1, or nil. Numbers are
+// always physical pages; other parts are matched against the labels, so "iv-x" or "A-1" work.
+func parsePageRange(rangeStr string, labels []string) ([]int, error) {
     if strings.TrimSpace(rangeStr) == "" {
         return nil, fmt.Errorf("empty page range")
     }
 
-    // Normalize separators: keep digits and '-' and ','; everything else becomes ','
-    normalized := strings.Map(func(r rune) rune {
-        if unicode.IsDigit(r) || r == '-' || r == ',' {
-            return r
-        }
-        return ','
-    }, rangeStr)
-
     var pages []int
-    for _, raw := range strings.Split(normalized, ",") {
-        part := strings.TrimSpace(raw)
-        if part == "" {
-            continue
-        }
-        if strings.Contains(part, "-") {
-            seg := strings.Split(part, "-")
-            if len(seg) != 2 {
-                return nil, fmt.Errorf("invalid range: %s", part)
-            }
-            start, err1 := strconv.Atoi(strings.TrimSpace(seg[0]))
-            end, err2 := strconv.Atoi(strings.TrimSpace(seg[1]))
-            if err1 != nil || err2 != nil || start < 1 || end < 1 {
-                return nil, fmt.Errorf("invalid range: %s", part)
-            }
-            if start > end {
-                return nil, fmt.Errorf("start must be <= end: %s", part)
+    parts := strings.FieldsFunc(rangeStr, func(r rune) bool {
+        return r == ',' || r == ';' || unicode.IsSpace(r)
+    })
+    for _, part := range parts {
+        parsed, err := parsePagePart(part, labels)
+        if err != nil {
+            // Any other character separates pages too, as in "1/3"
+            normalized := strings.Map(func(r rune) rune {
+                if unicode.IsDigit(r) || r == '-' {
+                    return r
+                }
+                return ','
+            }, part)
+            if normalized == part {
+                return nil, err
             }
-            for i := start; i <= end; i++ {
-                pages = append(pages, i)
+            if parsed, _ = parsePageRange(normalized, nil); parsed == nil {
+                return nil, err
             }
-            continue
         }
-        n, err := strconv.Atoi(part)
-        if err != nil || n < 1 {
-            return nil, fmt.Errorf("invalid page number: %s", part)
-        }
-        pages = append(pages, n)
+        pages = append(pages, parsed...)
     }
 
     if len(pages) == 0 {
@@ -555,6 +742,61 @@ func parsePageRange(rangeStr string) ([]int, error) {
     return pages, nil
 }
 
+// parsePagePart parses one page, label or range of them. A label may itself
+// contain '-', so each '-' is tried as the range separator in turn.
+func parsePagePart(part string, labels []string) ([]int, error) {
+    if page, ok := pageOf(part, labels); ok {
+        return []int{page}, nil
+    }
+    for i := 0; i < len(part); i++ {
+        if part[i] != '-' {
+            continue
+        }
+        start, ok1 := pageOf(part[:i], labels)
+        end, ok2 := pageOf(part[i+1:], labels)
+        if !ok1 || !ok2 {
+            continue
+        }
+        if start > end {
+            return nil, fmt.Errorf("start must be <= end: %s", part)
+        }
+        var pages []int
+        for p := start; p <= end; p++ {
+            pages = append(pages, p)
+        }
+        return pages, nil
+    }
+    switch {
+    case strings.IndexFunc(part, unicode.IsLetter) >= 0:
+        return nil, fmt.Errorf("invalid page or label: %s", part)
+    case strings.Contains(part, "-"):
+        return nil, fmt.Errorf("invalid range: %s", part)
+    }
+    return nil, fmt.Errorf("invalid page number: %s", part)
+}
+
+// pageOf returns the physical page of a page number or label
+func pageOf(s string, labels []string) (int, bool) {
+    if s == "" {
+        return 0, false
+    }
+    if strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+        n, err := strconv.Atoi(s)
+        return n, err == nil && n >= 1
+    }
+    for i, label := range labels {
+        if label == s {
+            return i + 1, true
+        }
+    }
+    for i, label := range labels {
+        if strings.EqualFold(label, s) {
+            return i + 1, true
+        }
+    }
+    return 0, false
+}
+
 // openFile opens a file in the system's default application
 func (a *App) openFile(path string) error {
 	var cmd *exec.Cmd
@@ -574,21 +816,35 @@ func (a *App) openFile(path string) error {
 }
 
 // selectNativeSingle opens the OS-native file dialog for a single file.
+// A picked PDF is sanitized first when sanitizing on import is on.
 func (a *App) selectNativeSingle(filters []zenity.FileFilter) (string, error) {
     opts := []zenity.Option{}
     if len(filters) > 0 {
         opts = append(opts, zenity.FileFilters(filters))
     }
-    return zenity.SelectFile(opts...)
+    path, err := zenity.SelectFile(opts...)
+    if err != nil || path == "" {
+        return path, err
+    }
+    paths, err := a.importFiles([]string{path})
+    if err != nil {
+        return "", err
+    }
+    return paths[0], nil
 }
 
 // selectNativeMultiple opens the OS-native file dialog for multiple files.
+// Picked PDFs are sanitized first when sanitizing on import is on.
 func (a *App) selectNativeMultiple(filters []zenity.FileFilter) ([]string, error) {
     opts := []zenity.Option{}
     if len(filters) > 0 {
         opts = append(opts, zenity.FileFilters(filters))
     }
-    return zenity.SelectFileMultiple(opts...)
+    paths, err := zenity.SelectFileMultiple(opts...)
+    if err != nil {
+        return nil, err
+    }
+    return a.importFiles(paths)
 }
 
 // selectNativeFolder opens the OS-native folder picker
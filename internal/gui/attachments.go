@@ -0,0 +1,122 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+)
+
+// makeAttachmentsSection lists the files embedded in a PDF with buttons to
+// save, add and remove them. The returned function loads a PDF into the
+// section; onChanged is called with the output file after adding or removing.
+func (a *App) makeAttachmentsSection(onChanged func(path string)) (fyne.CanvasObject, func(path string)) {
+	var selectedFile string
+	statusLabel := widget.NewLabel("")
+	list := container.NewVBox()
+
+	// writeCopy asks for an output file and runs op on it in the background
+	writeCopy := func(suffix string, op func(outputFile string) error, done string) {
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + suffix
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+		go func() {
+			if err := op(outputFile); err != nil {
+				a.showError(err, selectedFile)
+				return
+			}
+			onChanged(outputFile)
+			dialog.ShowInformation("Success", done, a.window)
+		}()
+	}
+
+	load := func(path string) {
+		selectedFile = path
+		list.RemoveAll()
+		attachments, err := a.pdfService.ListAttachments(path)
+		if err != nil {
+			statusLabel.SetText("Error: " + err.Error())
+			return
+		}
+		if len(attachments) == 0 {
+			statusLabel.SetText("No attachments")
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("%d attachment(s)", len(attachments)))
+
+		for _, att := range attachments {
+			att := att
+			text := fmt.Sprintf("%s (%d bytes)", att.FileName, att.Size)
+			if att.Description != "" {
+				text += " - " + att.Description
+			}
+			if !att.Modified.IsZero() {
+				text += ", modified " + att.Modified.Format("2006-01-02 15:04")
+			}
+
+			saveBtn := widget.NewButton("Save…", func() {
+				outputFile, err := a.selectNativeSave(filepath.Base(att.FileName), nil)
+				if err != nil || outputFile == "" {
+					return
+				}
+				if err := a.pdfService.ExtractAttachment(selectedFile, att.ID, outputFile); err != nil {
+					a.showError(err, selectedFile)
+				}
+			})
+			removeBtn := widget.NewButton("Remove", func() {
+				writeCopy("_detached.pdf", func(outputFile string) error {
+					return a.pdfService.RemoveAttachments(selectedFile, outputFile, []string{att.ID})
+				}, "Attachment removed successfully!")
+			})
+			list.Add(container.NewBorder(nil, nil, nil, container.NewHBox(saveBtn, removeBtn), widget.NewLabel(text)))
+		}
+	}
+
+	addBtn := widget.NewButton("Add Files…", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		files, err := a.selectNativeMultiple(nil)
+		if err != nil || len(files) == 0 {
+			return
+		}
+		writeCopy("_attached.pdf", func(outputFile string) error {
+			return a.pdfService.AddAttachments(selectedFile, outputFile, files)
+		}, "Files attached successfully!")
+	})
+
+	saveAllBtn := widget.NewButton("Save All…", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		dir, err := a.selectNativeFolder()
+		if err != nil || dir == "" {
+			return
+		}
+		go func() {
+			written, err := a.pdfService.ExtractAttachments(selectedFile, dir)
+			if err != nil {
+				a.showError(err, selectedFile)
+				return
+			}
+			dialog.ShowInformation("Success", fmt.Sprintf("Saved %d attachment(s) to %s", len(written), dir), a.window)
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Attachments:"),
+		statusLabel,
+		list,
+		container.NewHBox(addBtn, saveAllBtn),
+	), load
+}
@@ -0,0 +1,102 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeBookletTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+
+	paperSelect := widget.NewSelect([]string{"A4", "A3", "Letter"}, nil)
+	paperSelect.SetSelected("A4")
+	signatureEntry := widget.NewEntry()
+	signatureEntry.SetText("0")
+	signatureEntry.Disable()
+	signatureCheck := widget.NewCheck("Split into multiple signatures", func(on bool) {
+		if on {
+			signatureEntry.SetText("4")
+			signatureEntry.Enable()
+		} else {
+			signatureEntry.SetText("0")
+			signatureEntry.Disable()
+		}
+	})
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	bookletBtn := widget.NewButton("Create Booklet", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		sheets, err := strconv.Atoi(strings.TrimSpace(signatureEntry.Text))
+		if err != nil || sheets < 0 || (signatureCheck.Checked && sheets == 0) {
+			dialog.ShowError(fmt.Errorf("please enter a valid number of sheets per signature"), a.window)
+			return
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_booklet.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.BookletConfig{
+			InputFile:          selectedFile,
+			OutputFile:         outputFile,
+			PaperSize:          models.PaperSize(paperSelect.Selected),
+			SheetsPerSignature: sheets,
+		}
+
+		go func() {
+			if err := a.pdfService.Booklet(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "Booklet created successfully! Print double-sided, flipping on the short edge.", a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Reorder pages for folded, saddle-stitched booklets (padded to a multiple of 4 pages)"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Sheet size (landscape):"), paperSelect,
+		),
+		signatureCheck,
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Sheets per signature:"), signatureEntry,
+		),
+		bookletBtn,
+	)
+}
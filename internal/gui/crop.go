@@ -0,0 +1,271 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+// dragArea is a transparent widget that reports mouse drags as a start and
+// current position
+type dragArea struct {
+	widget.BaseWidget
+	onDrag func(from, to fyne.Position)
+	start  *fyne.Position
+}
+
+func newDragArea(onDrag func(from, to fyne.Position)) *dragArea {
+	d := &dragArea{onDrag: onDrag}
+	d.ExtendBaseWidget(d)
+	return d
+}
+
+func (d *dragArea) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(canvas.NewRectangle(color.Transparent))
+}
+
+func (d *dragArea) Dragged(ev *fyne.DragEvent) {
+	if d.start == nil {
+		start := ev.Position.Subtract(ev.Dragged)
+		d.start = &start
+	}
+	d.onDrag(*d.start, ev.Position)
+}
+
+func (d *dragArea) DragEnd() {
+	d.start = nil
+}
+
+func (a *App) makeCropTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+
+	marginEntries := make([]*widget.Entry, 4) // top, right, bottom, left
+	for i := range marginEntries {
+		marginEntries[i] = widget.NewEntry()
+		marginEntries[i].SetText("0")
+	}
+	autoCheck := widget.NewCheck("Detect content bounds on each page", nil)
+	paddingEntry := widget.NewEntry()
+	paddingEntry.SetText("12")
+	cropBoxCheck := widget.NewCheck("CropBox", nil)
+	cropBoxCheck.SetChecked(true)
+	mediaBoxCheck := widget.NewCheck("MediaBox", nil)
+	trimBoxCheck := widget.NewCheck("TrimBox", nil)
+	pagesEntry := widget.NewEntry()
+	pagesEntry.SetPlaceHolder("Pages to crop (empty = all, e.g., 2-10)")
+	previewPageEntry := widget.NewEntry()
+	previewPageEntry.SetText("1")
+
+	preview := newPageCanvas(fyne.NewSize(300, 300))
+
+	readMargins := func() (models.Margins, error) {
+		var v [4]float64
+		for i, e := range marginEntries {
+			f, err := strconv.ParseFloat(strings.TrimSpace(e.Text), 64)
+			if err != nil || f < 0 {
+				return models.Margins{}, fmt.Errorf("please enter valid margins")
+			}
+			v[i] = f
+		}
+		return models.Margins{Top: v[0], Right: v[1], Bottom: v[2], Left: v[3]}, nil
+	}
+
+	// drawCropRect outlines the area kept by the current margins
+	drawCropRect := func() {
+		if preview.page == nil {
+			return
+		}
+		m, err := readMargins()
+		if err != nil {
+			return
+		}
+		topLeft := preview.toCanvas(m.Left, preview.page.Height-m.Top)
+		bottomRight := preview.toCanvas(preview.page.Width-m.Right, m.Bottom)
+		if bottomRight.X <= topLeft.X || bottomRight.Y <= topLeft.Y {
+			preview.SetOverlays()
+			return
+		}
+		r := canvas.NewRectangle(color.NRGBA{R: 0x20, G: 0x60, B: 0xff, A: 0x30})
+		r.StrokeColor = color.NRGBA{R: 0x20, G: 0x60, B: 0xff, A: 0xff}
+		r.StrokeWidth = 2
+		r.Move(topLeft)
+		r.Resize(fyne.NewSize(bottomRight.X-topLeft.X, bottomRight.Y-topLeft.Y))
+		preview.SetOverlays(r)
+	}
+	for _, e := range marginEntries {
+		e.OnChanged = func(string) { drawCropRect() }
+	}
+
+	setMargins := func(m models.Margins) {
+		for i, v := range []float64{m.Top, m.Right, m.Bottom, m.Left} {
+			marginEntries[i].SetText(strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64))
+		}
+	}
+
+	drag := newDragArea(func(from, to fyne.Position) {
+		if preview.page == nil {
+			return
+		}
+		x0, y0 := preview.toPage(from)
+		x1, y1 := preview.toPage(to)
+		w, h := preview.page.Width, preview.page.Height
+		clampTo := func(v, limit float64) float64 { return math.Max(0, math.Min(limit, v)) }
+		x0, x1 = clampTo(math.Min(x0, x1), w), clampTo(math.Max(x0, x1), w)
+		y0, y1 = clampTo(math.Min(y0, y1), h), clampTo(math.Max(y0, y1), h)
+		setMargins(models.Margins{Top: h - y1, Right: w - x1, Bottom: y0, Left: x0})
+	})
+
+	previewPage := func() int {
+		page, err := strconv.Atoi(strings.TrimSpace(previewPageEntry.Text))
+		if err != nil || page < 1 {
+			return 1
+		}
+		return page
+	}
+
+	loadPreview := func() {
+		if selectedFile == "" {
+			return
+		}
+		page, err := a.pdfService.PageThumbnail(selectedFile, previewPage())
+		if err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		preview.SetPage(page)
+		drawCropRect()
+	}
+	previewPageEntry.OnSubmitted = func(string) { loadPreview() }
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			previewPageEntry.SetText("1")
+			loadPreview()
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	detectBtn := widget.NewButton("Detect Content", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		m, err := a.pdfService.ContentBounds(selectedFile, previewPage())
+		if err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		setMargins(m)
+	})
+
+	cropBtn := widget.NewButton("Crop PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		margins, err := readMargins()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		padding, err := strconv.ParseFloat(strings.TrimSpace(paddingEntry.Text), 64)
+		if err != nil || padding < 0 {
+			dialog.ShowError(fmt.Errorf("please enter a valid padding"), a.window)
+			return
+		}
+		var pages []int
+		if strings.TrimSpace(pagesEntry.Text) != "" {
+			if pages, err = a.parsePages(pagesEntry.Text, selectedFile); err != nil {
+				dialog.ShowError(fmt.Errorf("invalid page range: %w", err), a.window)
+				return
+			}
+		}
+		var boxes []models.PageBox
+		if cropBoxCheck.Checked {
+			boxes = append(boxes, models.BoxCrop)
+		}
+		if mediaBoxCheck.Checked {
+			boxes = append(boxes, models.BoxMedia)
+		}
+		if trimBoxCheck.Checked {
+			boxes = append(boxes, models.BoxTrim)
+		}
+		if len(boxes) == 0 {
+			dialog.ShowError(fmt.Errorf("please select at least one page box to set"), a.window)
+			return
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_cropped.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.CropConfig{
+			InputFile:  selectedFile,
+			OutputFile: outputFile,
+			Pages:      pages,
+			Margins:    margins,
+			Auto:       autoCheck.Checked,
+			Padding:    padding,
+			Boxes:      boxes,
+		}
+
+		go func() {
+			if err := a.pdfService.Crop(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "PDF cropped successfully!", a.window)
+			}
+		}()
+	})
+
+	form := container.NewVBox(
+		widget.NewLabel("Drag on the page to select the area to keep, or enter margins (pt):"),
+		container.NewGridWithColumns(4,
+			widget.NewLabel("Top:"), marginEntries[0],
+			widget.NewLabel("Right:"), marginEntries[1],
+			widget.NewLabel("Bottom:"), marginEntries[2],
+			widget.NewLabel("Left:"), marginEntries[3],
+		),
+		container.NewHBox(widget.NewLabel("Preview page:"), previewPageEntry, detectBtn),
+		autoCheck,
+		container.NewGridWithColumns(2, widget.NewLabel("Padding around content (pt):"), paddingEntry),
+		container.NewHBox(widget.NewLabel("Set:"), cropBoxCheck, mediaBoxCheck, trimBoxCheck),
+		pagesEntry,
+		cropBtn,
+	)
+
+	return container.NewVBox(
+		widget.NewLabel("Crop pages to remove borders and margins"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewBorder(nil, nil, nil, container.NewStack(preview.Object(), drag), form),
+	)
+}
@@ -0,0 +1,89 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeFlattenTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+
+	formsCheck := widget.NewCheck("Form fields", nil)
+	formsCheck.SetChecked(true)
+	commentsCheck := widget.NewCheck("Comments (notes, highlights, stamps, drawings)", nil)
+	commentsCheck.SetChecked(true)
+	linksCheck := widget.NewCheck("Links", nil)
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	flattenBtn := widget.NewButton("Flatten PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		if !formsCheck.Checked && !commentsCheck.Checked && !linksCheck.Checked {
+			dialog.ShowError(fmt.Errorf("please select what to flatten"), a.window)
+			return
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_flattened.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.FlattenConfig{
+			InputFile:  selectedFile,
+			OutputFile: outputFile,
+			Forms:      formsCheck.Checked,
+			Comments:   commentsCheck.Checked,
+			Links:      linksCheck.Checked,
+		}
+
+		go func() {
+			if err := a.pdfService.Flatten(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "PDF flattened successfully!", a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Burn form fields and annotations into the pages so they can no longer be edited"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		widget.NewLabel("Flatten:"),
+		formsCheck,
+		commentsCheck,
+		linksCheck,
+		flattenBtn,
+	)
+}
@@ -0,0 +1,95 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+)
+
+// makeFontsSection lists the fonts of a PDF, flagging those that are not
+// embedded, and exports the list as JSON. The returned function loads a PDF
+// into the section.
+func (a *App) makeFontsSection() (fyne.CanvasObject, func(path string)) {
+	var selectedFile string
+	fontsLabel := widget.NewLabel("")
+
+	load := func(path string) {
+		selectedFile = path
+		fonts, err := a.pdfService.ListFonts(path)
+		if err != nil {
+			fontsLabel.SetText("Error: " + err.Error())
+			return
+		}
+		if len(fonts) == 0 {
+			fontsLabel.SetText("No fonts")
+			return
+		}
+
+		missing := 0
+		var b strings.Builder
+		for _, f := range fonts {
+			embedding := "embedded"
+			switch {
+			case !f.Embedded:
+				embedding = "⚠ NOT EMBEDDED"
+				missing++
+			case f.Subset:
+				embedding = "embedded subset"
+			}
+			fmt.Fprintf(&b, "%s (%s, %s): %s, pages %s\n", f.Name, f.Type, f.Encoding, embedding, pageList(f.Pages))
+		}
+		if missing > 0 {
+			fmt.Fprintf(&b, "%d of %d fonts are not embedded; print shops may reject this file.", missing, len(fonts))
+		} else {
+			fmt.Fprintf(&b, "All %d fonts are embedded.", len(fonts))
+		}
+		fontsLabel.SetText(b.String())
+	}
+
+	exportBtn := widget.NewButton("Save JSON…", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_fonts.json"
+		outFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "JSON", Patterns: []string{"*.json"}}})
+		if err != nil || outFile == "" {
+			return
+		}
+		if err := a.pdfService.ExportFonts(selectedFile, outFile); err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		dialog.ShowInformation("Success", "Fonts exported to "+filepath.Base(outFile), a.window)
+	})
+
+	return container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Fonts:"), exportBtn),
+		fontsLabel,
+	), load
+}
+
+// pageList formats sorted page numbers with runs collapsed, as in 1-3, 5
+func pageList(pages []int) string {
+	var parts []string
+	for i := 0; i < len(pages); {
+		j := i
+		for j+1 < len(pages) && pages[j+1] == pages[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", pages[i], pages[j]))
+		} else {
+			parts = append(parts, fmt.Sprint(pages[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
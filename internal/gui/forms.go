@@ -0,0 +1,159 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeFormsTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+	fieldsLabel := widget.NewLabel("")
+	flattenCheck := widget.NewCheck("Flatten filled fields into page content", nil)
+
+	showFields := func() {
+		fields, err := a.pdfService.ListFormFields(selectedFile)
+		if err != nil {
+			fieldsLabel.SetText("Error: " + err.Error())
+			return
+		}
+		var b strings.Builder
+		for _, f := range fields {
+			fmt.Fprintf(&b, "%s (%s) = %q", f.Name, f.Type, f.Value)
+			if len(f.Options) > 0 {
+				fmt.Fprintf(&b, "  options: %s", strings.Join(f.Options, ", "))
+			}
+			if f.Locked {
+				b.WriteString("  [locked]")
+			}
+			b.WriteByte('\n')
+		}
+		fieldsLabel.SetText(fmt.Sprintf("%d field(s)\n%s", len(fields), b.String()))
+	}
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			showFields()
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	exportBtn := widget.NewButton("Export Values to JSON", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_values.json"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "JSON", Patterns: []string{"*.json"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+		if err := a.pdfService.ExportFormValues(selectedFile, outputFile); err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		dialog.ShowInformation("Success", "Form values exported successfully!", a.window)
+	})
+
+	fillBtn := widget.NewButton("Fill from JSON", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		jsonFile, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "JSON", Patterns: []string{"*.json"}}})
+		if err != nil || jsonFile == "" {
+			return
+		}
+		values, err := a.pdfService.ReadFormValues(jsonFile)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_filled.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.FormFillConfig{
+			InputFile:  selectedFile,
+			OutputFile: outputFile,
+			Values:     values,
+			Flatten:    flattenCheck.Checked,
+		}
+
+		go func() {
+			if err := a.pdfService.FillForm(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "Form filled successfully!", a.window)
+			}
+		}()
+	})
+
+	bulkBtn := widget.NewButton("Bulk Fill from CSV", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		csvFile, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "CSV", Patterns: []string{"*.csv"}}})
+		if err != nil || csvFile == "" {
+			return
+		}
+		outputDir, err := a.selectNativeFolder()
+		if err != nil || outputDir == "" {
+			return
+		}
+
+		config := models.FormBulkFillConfig{
+			InputFile: selectedFile,
+			CSVFile:   csvFile,
+			OutputDir: outputDir,
+			Flatten:   flattenCheck.Checked,
+		}
+
+		go func() {
+			written, err := a.pdfService.BulkFillForm(config)
+			if err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				dialog.ShowInformation("Success", fmt.Sprintf("Wrote %d filled PDFs to %s", len(written), outputDir), a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("List, export and fill form fields"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		widget.NewLabel("JSON files map field names to values; CSV files have field names in the first row and one filled PDF per further row."),
+		flattenCheck,
+		container.NewHBox(exportBtn, fillBtn, bulkBtn),
+		widget.NewSeparator(),
+		fieldsLabel,
+	)
+}
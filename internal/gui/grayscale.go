@@ -0,0 +1,81 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+)
+
+func (a *App) makeGrayscaleTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+	resultLabel := widget.NewLabel("")
+	resultLabel.Wrapping = fyne.TextWrapWord
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			resultLabel.SetText("")
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	convertBtn := widget.NewButton("Convert to Grayscale", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_gray.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		go func() {
+			report, err := a.pdfService.Grayscale(selectedFile, outputFile)
+			if err != nil {
+				a.showError(err, selectedFile)
+				return
+			}
+			_ = a.openFile(outputFile)
+			var b strings.Builder
+			fmt.Fprintf(&b, "Saved %s:\n", filepath.Base(outputFile))
+			if len(report.ColorPages) == 0 {
+				fmt.Fprintf(&b, "None of the %d pages had colour.", report.Pages)
+			} else {
+				fmt.Fprintf(&b, "%d of %d pages had colour: %s", len(report.ColorPages), report.Pages, pageList(report.ColorPages))
+			}
+			if report.Skipped > 0 {
+				fmt.Fprintf(&b, "\n%d image(s) or shading(s) in an unsupported format were left unchanged.", report.Skipped)
+			}
+			resultLabel.SetText(b.String())
+			dialog.ShowInformation("Success", "PDF converted to grayscale", a.window)
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Convert page colours and images to gray, keeping text and vector graphics sharp"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		convertBtn,
+		resultLabel,
+	)
+}
@@ -0,0 +1,224 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/internal/pdf"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeHeaderFooterTab() fyne.CanvasObject {
+	// The title, page count and labels are read once per file, so that the
+	// preview follows typing without parsing the PDF again
+	var selectedFile, title string
+	var pageCount, previewPage int
+	var labels []string
+	fileLabel := widget.NewLabel("No file selected")
+
+	entries := map[models.Position]*widget.Entry{}
+	for _, pos := range []models.Position{models.PosTopLeft, models.PosTopCenter, models.PosTopRight, models.PosBottomLeft, models.PosBottomCenter, models.PosBottomRight} {
+		entries[pos] = widget.NewEntry()
+	}
+	entries[models.PosTopLeft].SetPlaceHolder("Header left")
+	entries[models.PosTopCenter].SetPlaceHolder("Header center")
+	entries[models.PosTopRight].SetPlaceHolder("Header right")
+	entries[models.PosBottomLeft].SetPlaceHolder("Footer left")
+	entries[models.PosBottomCenter].SetPlaceHolder("Footer center")
+	entries[models.PosBottomRight].SetPlaceHolder("Footer right")
+	entries[models.PosTopLeft].SetText("{title}")
+	entries[models.PosBottomCenter].SetText("Page {page} of {pages}")
+	entries[models.PosBottomRight].SetText("{date}")
+
+	fontSelect := widget.NewSelect(stampFonts, nil)
+	fontSelect.SetSelected("Helvetica")
+	sizeEntry := widget.NewEntry()
+	sizeEntry.SetText("10")
+	pagesEntry := widget.NewEntry()
+	pagesEntry.SetPlaceHolder("Pages (empty = all, e.g., 2-10)")
+
+	preview := newPageCanvas(fyne.NewSize(300, 300))
+	previewLabel := widget.NewLabel("")
+
+	buildConfig := func() (models.HeaderFooterConfig, error) {
+		size, err := strconv.Atoi(strings.TrimSpace(sizeEntry.Text))
+		if err != nil || size < 1 {
+			return models.HeaderFooterConfig{}, fmt.Errorf("please enter a valid font size")
+		}
+		var pages []int
+		if strings.TrimSpace(pagesEntry.Text) != "" {
+			if pages, err = parsePageRange(pagesEntry.Text, labels); err != nil {
+				return models.HeaderFooterConfig{}, fmt.Errorf("invalid page range: %w", err)
+			}
+		}
+		text := map[models.Position]string{}
+		for pos, e := range entries {
+			text[pos] = e.Text
+		}
+		return models.HeaderFooterConfig{
+			InputFile: selectedFile,
+			Text:      text,
+			FontName:  fontSelect.Selected,
+			FontSize:  size,
+			Pages:     pages,
+		}, nil
+	}
+
+	// updatePreview draws the resolved texts of the first selected page over
+	// its thumbnail, loading the thumbnail only when that page changes
+	updatePreview := func() {
+		if selectedFile == "" {
+			return
+		}
+		config, err := buildConfig()
+		if err != nil {
+			return
+		}
+		first := 1
+		if len(config.Pages) > 0 {
+			first = slices.Min(config.Pages)
+		}
+		if first < 1 || first > pageCount {
+			return
+		}
+		if first != previewPage {
+			page, err := a.pdfService.PageThumbnail(selectedFile, first)
+			if err != nil {
+				return
+			}
+			preview.SetPage(page)
+			previewPage = first
+		}
+		// Fyne has no serif face, so Times is drawn in the default font
+		previewLabel.SetText(fmt.Sprintf("Page %d (approximate)", first))
+		style := fyne.TextStyle{
+			Bold:      strings.HasSuffix(config.FontName, "-Bold"),
+			Monospace: strings.HasPrefix(config.FontName, "Courier"),
+		}
+
+		texts := pdf.HeaderFooterText(config, title, first, pageCount)
+		var overlays []fyne.CanvasObject
+		pageW := float32(preview.page.Width) * preview.scale
+		pageH := float32(preview.page.Height) * preview.scale
+		margin := pdf.StampMargin * preview.scale
+		for pos, text := range texts {
+			t := canvas.NewText(text, color.Black)
+			t.TextSize = float32(config.FontSize) * preview.scale
+			t.TextStyle = style
+			size := fyne.MeasureText(text, t.TextSize, t.TextStyle)
+			// Place the text by its width in the PDF font
+			size.Width = float32(pdf.StampTextWidth(text, config.FontName, config.FontSize)) * preview.scale
+			x := preview.origin.X + margin
+			switch pos[1] {
+			case 'c':
+				x = preview.origin.X + (pageW-size.Width)/2
+			case 'r':
+				x = preview.origin.X + pageW - margin - size.Width
+			}
+			y := preview.origin.Y + margin
+			if pos[0] == 'b' {
+				y = preview.origin.Y + pageH - margin - size.Height
+			}
+			t.Move(fyne.NewPos(x, y))
+			t.Resize(size)
+			overlays = append(overlays, t)
+		}
+		preview.SetOverlays(overlays...)
+	}
+	for _, e := range entries {
+		e.OnChanged = func(string) { updatePreview() }
+	}
+	sizeEntry.OnChanged = func(string) { updatePreview() }
+	pagesEntry.OnChanged = func(string) { updatePreview() }
+	fontSelect.OnChanged = func(string) { updatePreview() }
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || path == "" {
+			return
+		}
+		docTitle, count, err := a.pdfService.TitleAndPageCount(path)
+		if err != nil {
+			a.offerRepair(path, err)
+			return
+		}
+		page, err := a.pdfService.PageThumbnail(path, 1)
+		if err != nil {
+			a.showError(err, path)
+			return
+		}
+		selectedFile, title, pageCount, previewPage = path, docTitle, count, 1
+		labels, _ = a.pdfService.PageLabels(path)
+		fileLabel.SetText(filepath.Base(selectedFile))
+		preview.SetPage(page)
+		updatePreview()
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	applyBtn := widget.NewButton("Add Header/Footer", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		config, err := buildConfig()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_header_footer.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+		config.OutputFile = outputFile
+		go func() {
+			if err := a.pdfService.HeaderFooter(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "Header and footer added successfully!", a.window)
+			}
+		}()
+	})
+
+	form := container.NewVBox(
+		widget.NewLabel("Variables: {filename} {date} {page} {pages} {title}"),
+		container.NewGridWithColumns(3,
+			entries[models.PosTopLeft], entries[models.PosTopCenter], entries[models.PosTopRight],
+			entries[models.PosBottomLeft], entries[models.PosBottomCenter], entries[models.PosBottomRight],
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Font:"), fontSelect,
+			widget.NewLabel("Font size:"), sizeEntry,
+		),
+		pagesEntry,
+		applyBtn,
+	)
+
+	return container.NewVBox(
+		widget.NewLabel("Add headers and footers to a PDF"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewBorder(nil, nil, nil, container.NewVBox(preview.Object(), previewLabel), form),
+	)
+}
@@ -0,0 +1,147 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeInsertTab() fyne.CanvasObject {
+	var selectedFile, sourceFile string
+	fileLabel := widget.NewLabel("No file selected")
+	sourceLabel := widget.NewLabel("No source PDF selected")
+
+	pageEntry := widget.NewEntry()
+	pageEntry.SetText("1")
+	positionRadio := widget.NewRadioGroup([]string{"Before", "After"}, nil)
+	positionRadio.Horizontal = true
+	positionRadio.SetSelected("After")
+
+	blankEntry := widget.NewEntry()
+	blankEntry.SetText("1")
+	sourcePagesEntry := widget.NewEntry()
+	sourcePagesEntry.SetPlaceHolder("Source pages (empty = all, e.g., 2-5)")
+
+	selectSourceBtn := widget.NewButton("Browse Source PDF", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			sourceFile = path
+			sourceLabel.SetText(filepath.Base(sourceFile))
+			if count, err := a.pdfService.GetPageCount(sourceFile); err == nil {
+				sourceLabel.SetText(fmt.Sprintf("%s (%d pages)", filepath.Base(sourceFile), count))
+			} else {
+				a.offerRepair(sourceFile, err)
+			}
+		}
+	})
+
+	blankBox := container.NewGridWithColumns(2, widget.NewLabel("Number of blank pages:"), blankEntry)
+	sourceBox := container.NewVBox(selectSourceBtn, sourceLabel, sourcePagesEntry)
+	sourceBox.Hide()
+	modeRadio := widget.NewRadioGroup([]string{"Blank pages", "Pages from another PDF"}, func(mode string) {
+		if mode == "Blank pages" {
+			blankBox.Show()
+			sourceBox.Hide()
+		} else {
+			blankBox.Hide()
+			sourceBox.Show()
+		}
+	})
+	modeRadio.SetSelected("Blank pages")
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			if count, err := a.pdfService.GetPageCount(selectedFile); err == nil {
+				fileLabel.SetText(fmt.Sprintf("%s (%d pages)", filepath.Base(selectedFile), count))
+			} else {
+				a.offerRepair(selectedFile, err)
+			}
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	insertBtn := widget.NewButton("Insert Pages", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		page, err := strconv.Atoi(strings.TrimSpace(pageEntry.Text))
+		if err != nil || page < 1 {
+			dialog.ShowError(fmt.Errorf("please enter a valid page number"), a.window)
+			return
+		}
+		config := models.InsertConfig{
+			InputFile: selectedFile,
+			Page:      page,
+			After:     positionRadio.Selected == "After",
+		}
+		if modeRadio.Selected == "Blank pages" {
+			n, err := strconv.Atoi(strings.TrimSpace(blankEntry.Text))
+			if err != nil || n < 1 {
+				dialog.ShowError(fmt.Errorf("please enter a valid number of blank pages"), a.window)
+				return
+			}
+			config.BlankPages = n
+		} else {
+			if sourceFile == "" {
+				dialog.ShowError(fmt.Errorf("please select a source PDF"), a.window)
+				return
+			}
+			config.SourceFile = sourceFile
+			if strings.TrimSpace(sourcePagesEntry.Text) != "" {
+				if config.SourcePages, err = a.parsePages(sourcePagesEntry.Text, sourceFile); err != nil {
+					dialog.ShowError(fmt.Errorf("invalid page range: %w", err), a.window)
+					return
+				}
+			}
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_inserted.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+		config.OutputFile = outputFile
+
+		go func() {
+			if err := a.pdfService.Insert(config); err != nil {
+				a.showError(err, config.InputFile, config.SourceFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "Pages inserted successfully!", a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Insert blank pages or pages from another PDF"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewHBox(widget.NewLabel("Insert"), positionRadio, widget.NewLabel("page"), pageEntry),
+		modeRadio,
+		blankBox,
+		sourceBox,
+		insertBtn,
+	)
+}
@@ -0,0 +1,169 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+// stampPositions maps the position choices offered in the GUI
+var stampPositions = map[string]models.Position{
+	"Top left":      models.PosTopLeft,
+	"Top center":    models.PosTopCenter,
+	"Top right":     models.PosTopRight,
+	"Bottom left":   models.PosBottomLeft,
+	"Bottom center": models.PosBottomCenter,
+	"Bottom right":  models.PosBottomRight,
+}
+
+var stampPositionNames = []string{"Top left", "Top center", "Top right", "Bottom left", "Bottom center", "Bottom right"}
+
+// stampFonts lists the PDF core fonts that need no embedding
+var stampFonts = []string{"Helvetica", "Helvetica-Bold", "Times-Roman", "Times-Bold", "Courier", "Courier-Bold"}
+
+func (a *App) makePageNumbersTab() fyne.CanvasObject {
+	var selectedFiles []string
+	selectedMap := map[int]bool{}
+	sortable := NewSortableList(&selectedFiles, &selectedMap, nil)
+	countLabel := widget.NewLabel("0 files selected")
+
+	selectFilesBtn := widget.NewButton("Browse & Add PDFs", func() {
+		paths, err := a.selectNativeMultiple([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && len(paths) > 0 {
+			selectedFiles = append(selectedFiles, paths...)
+			sortable.rebuild()
+			countLabel.SetText(fmt.Sprintf("%d files selected", len(selectedFiles)))
+		}
+	})
+	clearBtn := widget.NewButton("Clear List", func() {
+		selectedFiles = []string{}
+		selectedMap = map[int]bool{}
+		sortable.rebuild()
+		countLabel.SetText("0 files selected")
+	})
+
+	formatEntry := widget.NewEntry()
+	formatEntry.SetText("Page {page} of {pages}")
+	startEntry := widget.NewEntry()
+	startEntry.SetText("1")
+	digitsEntry := widget.NewEntry()
+	digitsEntry.SetText("0")
+	positionSelect := widget.NewSelect(stampPositionNames, nil)
+	positionSelect.SetSelected("Bottom center")
+	fontSelect := widget.NewSelect(stampFonts, nil)
+	fontSelect.SetSelected("Helvetica")
+	presetSelect := widget.NewSelect([]string{"Page X of Y", "Bates number"}, func(v string) {
+		if v == "Bates number" {
+			formatEntry.SetText("BATES{n}")
+			digitsEntry.SetText("6")
+			positionSelect.SetSelected("Bottom right")
+		} else {
+			formatEntry.SetText("Page {page} of {pages}")
+			digitsEntry.SetText("0")
+			positionSelect.SetSelected("Bottom center")
+		}
+	})
+	sizeEntry := widget.NewEntry()
+	sizeEntry.SetText("10")
+	pagesEntry := widget.NewEntry()
+	pagesEntry.SetPlaceHolder("Pages to number (empty = all, e.g., 2-10)")
+
+	outputDirLabel := widget.NewLabel("Output: Same as input files")
+	var outputDir string
+	selectOutputBtn := widget.NewButton("Select Output Directory", func() {
+		if dir, err := a.selectNativeFolder(); err == nil && dir != "" {
+			outputDir = dir
+			outputDirLabel.SetText("Output: " + outputDir)
+		}
+	})
+
+	runBtn := widget.NewButton("Add Page Numbers", func() {
+		if len(selectedFiles) == 0 {
+			dialog.ShowError(fmt.Errorf("please select at least one PDF file"), a.window)
+			return
+		}
+		start, err := strconv.Atoi(strings.TrimSpace(startEntry.Text))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("please enter a valid start number"), a.window)
+			return
+		}
+		digits, err := strconv.Atoi(strings.TrimSpace(digitsEntry.Text))
+		if err != nil || digits < 0 {
+			dialog.ShowError(fmt.Errorf("please enter a valid number of digits"), a.window)
+			return
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(sizeEntry.Text))
+		if err != nil || size < 1 {
+			dialog.ShowError(fmt.Errorf("please enter a valid font size"), a.window)
+			return
+		}
+		var pages []int
+		if strings.TrimSpace(pagesEntry.Text) != "" {
+			// Labels differ between files, so they are only used for one file
+			labelFile := ""
+			if len(selectedFiles) == 1 {
+				labelFile = selectedFiles[0]
+			}
+			if pages, err = a.parsePages(pagesEntry.Text, labelFile); err != nil {
+				dialog.ShowError(fmt.Errorf("invalid page range: %w", err), a.window)
+				return
+			}
+		}
+
+		config := models.PageNumberingConfig{
+			InputFiles: append([]string(nil), selectedFiles...),
+			OutputDir:  outputDir,
+			Format:     formatEntry.Text,
+			Start:      start,
+			Digits:     digits,
+			Position:   stampPositions[positionSelect.Selected],
+			FontName:   fontSelect.Selected,
+			FontSize:   size,
+			Pages:      pages,
+		}
+
+		go func() {
+			next, err := a.pdfService.PageNumbering(config)
+			if err != nil {
+				a.showError(err, config.InputFiles...)
+				return
+			}
+			dir := outputDir
+			if dir == "" {
+				dir = filepath.Dir(a.originalPath(config.InputFiles[0]))
+			}
+			_ = a.openFile(dir)
+			dialog.ShowInformation("Success", fmt.Sprintf("Numbered %d file(s). Next number: %d", len(config.InputFiles), next), a.window)
+		}()
+	})
+
+	listArea := container.NewScroll(sortable.Container())
+	listArea.SetMinSize(fyne.NewSize(0, 200))
+	return container.NewVBox(
+		widget.NewLabel("Stamp page numbers or Bates numbers ({n} counter, {page}, {pages})"),
+		container.NewHBox(selectFilesBtn, clearBtn),
+		countLabel,
+		listArea,
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Preset:"), presetSelect,
+			widget.NewLabel("Format:"), formatEntry,
+			widget.NewLabel("Start number:"), startEntry,
+			widget.NewLabel("Zero-pad digits:"), digitsEntry,
+			widget.NewLabel("Position:"), positionSelect,
+			widget.NewLabel("Font:"), fontSelect,
+			widget.NewLabel("Font size:"), sizeEntry,
+		),
+		pagesEntry,
+		selectOutputBtn,
+		outputDirLabel,
+		runBtn,
+	)
+}
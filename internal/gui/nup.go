@@ -0,0 +1,119 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+// nupSheetChoices lists the pages-per-sheet options; "1" disables N-up
+var nupSheetChoices = []string{"1", "2", "4", "6", "9", "16"}
+
+// nupPapers maps the sheet sizes offered in the GUI
+var nupPapers = map[string]models.PaperSize{
+	"A4 portrait":      "A4",
+	"A4 landscape":     "A4L",
+	"A3 portrait":      "A3",
+	"A3 landscape":     "A3L",
+	"Letter portrait":  "Letter",
+	"Letter landscape": "LetterL",
+}
+
+var nupPaperNames = []string{"A4 portrait", "A4 landscape", "A3 portrait", "A3 landscape", "Letter portrait", "Letter landscape"}
+
+func (a *App) makeNUpTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+
+	sheetSelect := widget.NewSelect(nupSheetChoices[1:], nil)
+	sheetSelect.SetSelected("4")
+	paperSelect := widget.NewSelect(nupPaperNames, nil)
+	paperSelect.SetSelected("A4 portrait")
+	orderSelect := widget.NewSelect([]string{"Rows first", "Columns first"}, nil)
+	orderSelect.SetSelected("Rows first")
+	borderCheck := widget.NewCheck("Draw page borders", nil)
+	borderCheck.SetChecked(true)
+	marginEntry := widget.NewEntry()
+	marginEntry.SetText("3")
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	imposeBtn := widget.NewButton("Create N-up PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		margin, err := strconv.ParseFloat(strings.TrimSpace(marginEntry.Text), 64)
+		if err != nil || margin < 0 {
+			dialog.ShowError(fmt.Errorf("please enter a valid margin"), a.window)
+			return
+		}
+		n, _ := strconv.Atoi(sheetSelect.Selected)
+
+		base := filepath.Base(selectedFile)
+		suggested := fmt.Sprintf("%s_%dup.pdf", strings.TrimSuffix(base, filepath.Ext(base)), n)
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.NUpConfig{
+			InputFile:  selectedFile,
+			OutputFile: outputFile,
+			NUpOptions: models.NUpOptions{
+				PagesPerSheet: n,
+				PaperSize:     nupPapers[paperSelect.Selected],
+				ColumnMajor:   orderSelect.Selected == "Columns first",
+				Border:        borderCheck.Checked,
+				Margin:        margin,
+			},
+		}
+
+		go func() {
+			if err := a.pdfService.NUp(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "N-up PDF created successfully!", a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Print several pages on each sheet"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Pages per sheet:"), sheetSelect,
+			widget.NewLabel("Paper size:"), paperSelect,
+			widget.NewLabel("Page order:"), orderSelect,
+			widget.NewLabel("Margin (pt):"), marginEntry,
+		),
+		borderCheck,
+		imposeBtn,
+	)
+}
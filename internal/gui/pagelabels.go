@@ -0,0 +1,193 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/internal/pdf"
+	"pdf-toolbox/pkg/models"
+)
+
+// pageLabelStyles maps the numbering style choices offered in the GUI
+var pageLabelStyles = map[string]models.PageLabelStyle{
+	"1, 2, 3":            models.LabelDecimal,
+	"i, ii, iii":         models.LabelRomanLower,
+	"I, II, III":         models.LabelRomanUpper,
+	"a, b, c":            models.LabelLettersLower,
+	"A, B, C":            models.LabelLettersUpper,
+	"None (prefix only)": models.LabelNone,
+}
+
+var pageLabelStyleNames = []string{"1, 2, 3", "i, ii, iii", "I, II, III", "a, b, c", "A, B, C", "None (prefix only)"}
+
+func (a *App) makePageLabelsTab() fyne.CanvasObject {
+	var selectedFile string
+	var pageCount int
+	var ranges []models.PageLabelRange
+	fileLabel := widget.NewLabel("No file selected")
+	rangesBox := container.NewVBox()
+	labelsLabel := widget.NewLabel("")
+	labelsLabel.Wrapping = fyne.TextWrapWord
+
+	firstPageEntry := widget.NewEntry()
+	firstPageEntry.SetPlaceHolder("First page (e.g., 5)")
+	styleSelect := widget.NewSelect(pageLabelStyleNames, nil)
+	styleSelect.SetSelected("1, 2, 3")
+	prefixEntry := widget.NewEntry()
+	prefixEntry.SetPlaceHolder("Prefix (optional, e.g., A-)")
+	startEntry := widget.NewEntry()
+	startEntry.SetText("1")
+
+	var showRanges func()
+	showRanges = func() {
+		rangesBox.RemoveAll()
+		sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].FirstPage < ranges[j].FirstPage })
+		labels := pdf.PageLabelsFor(ranges, pageCount)
+		for i, r := range ranges {
+			i := i
+			last := pageCount
+			if i+1 < len(ranges) {
+				last = ranges[i+1].FirstPage - 1
+			}
+			text := fmt.Sprintf("Page %d: %s", r.FirstPage, labels[r.FirstPage-1])
+			if last > r.FirstPage {
+				text = fmt.Sprintf("Pages %d-%d: %s … %s", r.FirstPage, last, labels[r.FirstPage-1], labels[last-1])
+			}
+			removeBtn := widget.NewButton("Remove", func() {
+				ranges = append(ranges[:i], ranges[i+1:]...)
+				showRanges()
+			})
+			rangesBox.Add(container.NewBorder(nil, nil, nil, removeBtn, widget.NewLabel(text)))
+		}
+		switch {
+		case len(ranges) == 0:
+			labelsLabel.SetText("No page labels; pages are numbered 1, 2, 3.")
+		case ranges[0].FirstPage > 1:
+			labelsLabel.SetText(fmt.Sprintf("Pages 1-%d will be numbered 1, 2, 3.", ranges[0].FirstPage-1))
+		default:
+			labelsLabel.SetText("")
+		}
+	}
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || path == "" {
+			return
+		}
+		count, err := a.pdfService.GetPageCount(path)
+		if err != nil {
+			a.offerRepair(path, err)
+			return
+		}
+		existing, err := a.pdfService.PageLabelRanges(path)
+		if err != nil {
+			a.showError(err, path)
+			return
+		}
+		selectedFile, pageCount, ranges = path, count, existing
+		fileLabel.SetText(fmt.Sprintf("%s (%d pages)", filepath.Base(selectedFile), pageCount))
+		showRanges()
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	addRangeBtn := widget.NewButton("Add Range", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		first, err := strconv.Atoi(strings.TrimSpace(firstPageEntry.Text))
+		if err != nil || first < 1 || first > pageCount {
+			dialog.ShowError(fmt.Errorf("please enter a first page between 1 and %d", pageCount), a.window)
+			return
+		}
+		start, err := strconv.Atoi(strings.TrimSpace(startEntry.Text))
+		if err != nil || start < 1 {
+			dialog.ShowError(fmt.Errorf("please enter a valid start number"), a.window)
+			return
+		}
+		r := models.PageLabelRange{FirstPage: first, Style: pageLabelStyles[styleSelect.Selected], Prefix: prefixEntry.Text, Start: start}
+		// A range on the same page replaces the old one
+		for i := range ranges {
+			if ranges[i].FirstPage == first {
+				ranges = append(ranges[:i], ranges[i+1:]...)
+				break
+			}
+		}
+		ranges = append(ranges, r)
+		firstPageEntry.SetText("")
+		prefixEntry.SetText("")
+		startEntry.SetText("1")
+		showRanges()
+	})
+
+	saveBtn := widget.NewButton("Save Page Labels", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_labeled.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		labelRanges := append([]models.PageLabelRange(nil), ranges...)
+		go func() {
+			if err := a.pdfService.SetPageLabels(selectedFile, outputFile, labelRanges); err != nil {
+				a.showError(err, selectedFile)
+				return
+			}
+			_ = a.openFile(outputFile)
+			dialog.ShowInformation("Success", "Page labels saved", a.window)
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Number pages as the viewer shows them, e.g., i, ii, iii for front matter"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		widget.NewLabel("Ranges (each runs until the next one):"),
+		rangesBox,
+		labelsLabel,
+		container.NewGridWithColumns(2,
+			widget.NewLabel("First page:"), firstPageEntry,
+			widget.NewLabel("Style:"), styleSelect,
+			widget.NewLabel("Prefix:"), prefixEntry,
+			widget.NewLabel("Start at:"), startEntry,
+		),
+		addRangeBtn,
+		saveBtn,
+	)
+}
+
+// parsePages parses a page range against the page labels of path, reading
+// the labels only when the range holds something other than numbers
+func (a *App) parsePages(rangeStr, path string) ([]int, error) {
+	var labels []string
+	hasLabels := strings.IndexFunc(rangeStr, func(r rune) bool {
+		return !unicode.IsDigit(r) && !unicode.IsSpace(r) && r != '-' && r != ',' && r != ';'
+	}) >= 0
+	if hasLabels && path != "" {
+		labels, _ = a.pdfService.PageLabels(path)
+	}
+	return parsePageRange(rangeStr, labels)
+}
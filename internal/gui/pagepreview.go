@@ -0,0 +1,82 @@
+package gui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"pdf-toolbox/internal/pdf"
+)
+
+// pageCanvas draws a page preview scaled to fit a fixed area, with overlays
+// that are positioned in page coordinates
+type pageCanvas struct {
+	area     fyne.Size
+	content  *fyne.Container
+	base     []fyne.CanvasObject
+	page     *pdf.PagePreview
+	scale    float32       // canvas units per point
+	origin   fyne.Position // top-left corner of the page on the canvas
+	overlays []fyne.CanvasObject
+}
+
+func newPageCanvas(area fyne.Size) *pageCanvas {
+	return &pageCanvas{area: area, content: container.NewWithoutLayout()}
+}
+
+// Object returns the canvas object to place in a layout
+func (c *pageCanvas) Object() fyne.CanvasObject {
+	return container.NewGridWrap(c.area, c.content)
+}
+
+// SetPage shows p, or clears the canvas when p is nil
+func (c *pageCanvas) SetPage(p *pdf.PagePreview) {
+	c.page = p
+	c.base = nil
+	c.overlays = nil
+	if p != nil && p.Width > 0 && p.Height > 0 {
+		c.scale = min(c.area.Width/float32(p.Width), c.area.Height/float32(p.Height))
+		size := fyne.NewSize(float32(p.Width)*c.scale, float32(p.Height)*c.scale)
+		c.origin = fyne.NewPos((c.area.Width-size.Width)/2, (c.area.Height-size.Height)/2)
+
+		sheet := canvas.NewRectangle(color.White)
+		sheet.StrokeColor = color.Gray{Y: 0x80}
+		sheet.StrokeWidth = 1
+		sheet.Move(c.origin)
+		sheet.Resize(size)
+		c.base = append(c.base, sheet)
+
+		if p.Image != nil {
+			img := canvas.NewImageFromImage(p.Image)
+			img.FillMode = canvas.ImageFillStretch
+			img.Move(c.origin)
+			img.Resize(size)
+			c.base = append(c.base, img)
+		}
+	}
+	c.refresh()
+}
+
+// SetOverlays replaces the objects drawn on top of the page
+func (c *pageCanvas) SetOverlays(objects ...fyne.CanvasObject) {
+	c.overlays = objects
+	c.refresh()
+}
+
+// toCanvas converts a point in PDF page coordinates (origin bottom-left) to canvas coordinates
+func (c *pageCanvas) toCanvas(x, y float64) fyne.Position {
+	return fyne.NewPos(c.origin.X+float32(x)*c.scale, c.origin.Y+float32(c.page.Height-y)*c.scale)
+}
+
+// toPage converts a canvas position to PDF page coordinates
+func (c *pageCanvas) toPage(pos fyne.Position) (float64, float64) {
+	x := float64((pos.X - c.origin.X) / c.scale)
+	y := c.page.Height - float64((pos.Y-c.origin.Y)/c.scale)
+	return x, y
+}
+
+func (c *pageCanvas) refresh() {
+	c.content.Objects = append(append([]fyne.CanvasObject(nil), c.base...), c.overlays...)
+	c.content.Refresh()
+}
@@ -0,0 +1,118 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makePDFATab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+	resultLabel := widget.NewLabel("")
+	resultLabel.Wrapping = fyne.TextWrapWord
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			resultLabel.SetText("")
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	checkBtn := widget.NewButton("Check PDF/A-2b", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		report, err := a.pdfService.CheckPDFA(selectedFile)
+		if err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		if len(report.Violations) == 0 {
+			resultLabel.SetText("No PDF/A-2b violations found")
+			return
+		}
+		resultLabel.SetText(fmt.Sprintf("%d violation(s) found:\n%s", len(report.Violations), pdfaViolationsText(report.Violations)))
+	})
+
+	convertBtn := widget.NewButton("Convert to PDF/A-2b", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_pdfa.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		go func() {
+			result, err := a.pdfService.ConvertToPDFA(selectedFile, outputFile)
+			if err != nil {
+				a.showError(err, selectedFile)
+				return
+			}
+			_ = a.openFile(outputFile)
+			var b strings.Builder
+			fmt.Fprintf(&b, "Saved %s:\n", filepath.Base(outputFile))
+			for _, c := range result.Changes {
+				fmt.Fprintf(&b, "  %s\n", c)
+			}
+			if len(result.Remaining) == 0 {
+				b.WriteString("No PDF/A-2b violations remain.")
+			} else {
+				fmt.Fprintf(&b, "%d violation(s) could not be fixed:\n%s", len(result.Remaining), pdfaViolationsText(result.Remaining))
+			}
+			resultLabel.SetText(b.String())
+			dialog.ShowInformation("Converted", "PDF converted; see the report in the PDF/A tab.", a.window)
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Check a PDF against PDF/A-2b or convert it for archiving"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewHBox(checkBtn, convertBtn),
+		resultLabel,
+	)
+}
+
+// pdfaViolationsText lists violations one per line with their page or object
+func pdfaViolationsText(violations []models.PDFAViolation) string {
+	var b strings.Builder
+	for _, v := range violations {
+		var where []string
+		if v.Page > 0 {
+			where = append(where, fmt.Sprintf("page %d", v.Page))
+		}
+		if v.Object > 0 {
+			where = append(where, fmt.Sprintf("object %d", v.Object))
+		}
+		if len(where) == 0 {
+			where = append(where, "document")
+		}
+		fmt.Fprintf(&b, "  [%s] %s: %s\n", v.Rule, strings.Join(where, ", "), v.Message)
+	}
+	return b.String()
+}
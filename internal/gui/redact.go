@@ -0,0 +1,226 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeRedactTab() fyne.CanvasObject {
+	var selectedFile string
+	var areas []models.RedactArea
+	var pending *models.RedactArea
+	fileLabel := widget.NewLabel("No file selected")
+	areasBox := container.NewVBox()
+
+	patternsEntry := widget.NewMultiLineEntry()
+	patternsEntry.SetPlaceHolder("Search patterns, one regular expression per line (e.g., [\\w.]+@[\\w.]+)")
+	patternsEntry.SetMinRowsVisible(3)
+	pagesEntry := widget.NewEntry()
+	pagesEntry.SetPlaceHolder("Pages to search (empty = all, e.g., 2-10)")
+	reportCheck := widget.NewCheck("Save a JSON report next to the output", nil)
+	reportCheck.SetChecked(true)
+	previewPageEntry := widget.NewEntry()
+	previewPageEntry.SetText("1")
+
+	preview := newPageCanvas(fyne.NewSize(300, 300))
+
+	previewPage := func() int {
+		page, err := strconv.Atoi(strings.TrimSpace(previewPageEntry.Text))
+		if err != nil || page < 1 {
+			return 1
+		}
+		return page
+	}
+
+	areaRect := func(area models.RedactArea, fill, stroke color.Color) fyne.CanvasObject {
+		topLeft := preview.toCanvas(area.Rect[0], area.Rect[3])
+		bottomRight := preview.toCanvas(area.Rect[2], area.Rect[1])
+		r := canvas.NewRectangle(fill)
+		r.StrokeColor = stroke
+		r.StrokeWidth = 2
+		r.Move(topLeft)
+		r.Resize(fyne.NewSize(bottomRight.X-topLeft.X, bottomRight.Y-topLeft.Y))
+		return r
+	}
+
+	// drawAreas shows the areas of the previewed page and the one being drawn
+	drawAreas := func() {
+		if preview.page == nil {
+			return
+		}
+		var overlays []fyne.CanvasObject
+		for _, area := range areas {
+			if area.Page == previewPage() {
+				overlays = append(overlays, areaRect(area, color.NRGBA{A: 0xc0}, color.Black))
+			}
+		}
+		if pending != nil {
+			overlays = append(overlays, areaRect(*pending,
+				color.NRGBA{R: 0x20, G: 0x60, B: 0xff, A: 0x30}, color.NRGBA{R: 0x20, G: 0x60, B: 0xff, A: 0xff}))
+		}
+		preview.SetOverlays(overlays...)
+	}
+
+	var showAreas func()
+	showAreas = func() {
+		areasBox.RemoveAll()
+		for i, area := range areas {
+			i := i
+			text := fmt.Sprintf("Page %d: %.0f, %.0f - %.0f, %.0f", area.Page, area.Rect[0], area.Rect[1], area.Rect[2], area.Rect[3])
+			removeBtn := widget.NewButton("Remove", func() {
+				areas = append(areas[:i], areas[i+1:]...)
+				showAreas()
+				drawAreas()
+			})
+			areasBox.Add(container.NewBorder(nil, nil, nil, removeBtn, widget.NewLabel(text)))
+		}
+	}
+
+	drag := newDragArea(func(from, to fyne.Position) {
+		if preview.page == nil {
+			return
+		}
+		x0, y0 := preview.toPage(from)
+		x1, y1 := preview.toPage(to)
+		w, h := preview.page.Width, preview.page.Height
+		clampTo := func(v, limit float64) float64 { return math.Max(0, math.Min(limit, v)) }
+		pending = &models.RedactArea{Page: previewPage(), Rect: [4]float64{
+			clampTo(math.Min(x0, x1), w), clampTo(math.Min(y0, y1), h),
+			clampTo(math.Max(x0, x1), w), clampTo(math.Max(y0, y1), h),
+		}}
+		drawAreas()
+	})
+
+	loadPreview := func() {
+		if selectedFile == "" {
+			return
+		}
+		page, err := a.pdfService.PageThumbnail(selectedFile, previewPage())
+		if err != nil {
+			a.showError(err, selectedFile)
+			return
+		}
+		pending = nil
+		preview.SetPage(page)
+		drawAreas()
+	}
+	previewPageEntry.OnSubmitted = func(string) { loadPreview() }
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			areas = nil
+			showAreas()
+			previewPageEntry.SetText("1")
+			loadPreview()
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	addAreaBtn := widget.NewButton("Add Area", func() {
+		if pending == nil || pending.Rect[2]-pending.Rect[0] < 1 || pending.Rect[3]-pending.Rect[1] < 1 {
+			dialog.ShowInformation("Add Area", "Drag on the page to select an area first", a.window)
+			return
+		}
+		areas = append(areas, *pending)
+		pending = nil
+		showAreas()
+		drawAreas()
+	})
+
+	redactBtn := widget.NewButton("Redact PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		var patterns []string
+		for _, line := range strings.Split(patternsEntry.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				patterns = append(patterns, line)
+			}
+		}
+		if len(areas) == 0 && len(patterns) == 0 {
+			dialog.ShowError(fmt.Errorf("please add an area or a search pattern"), a.window)
+			return
+		}
+		var pages []int
+		if strings.TrimSpace(pagesEntry.Text) != "" {
+			var err error
+			if pages, err = a.parsePages(pagesEntry.Text, selectedFile); err != nil {
+				dialog.ShowError(fmt.Errorf("invalid page range: %w", err), a.window)
+				return
+			}
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_redacted.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.RedactConfig{
+			InputFile:  selectedFile,
+			OutputFile: outputFile,
+			Areas:      append([]models.RedactArea(nil), areas...),
+			Patterns:   patterns,
+			Pages:      pages,
+		}
+		if reportCheck.Checked {
+			config.ReportFile = strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + "_redaction.json"
+		}
+
+		go func() {
+			report, err := a.pdfService.Redact(config)
+			if err != nil {
+				a.showError(err, config.InputFile)
+				return
+			}
+			_ = a.openFile(outputFile)
+			dialog.ShowInformation("Success", fmt.Sprintf(
+				"Redacted %d area(s): removed %d characters, %d images and %d annotations, blacked out %d images.",
+				len(report.Redactions), report.GlyphsRemoved, report.ImagesRemoved, report.AnnotationsRemoved, report.ImagesCleared), a.window)
+		}()
+	})
+
+	form := container.NewVBox(
+		widget.NewLabel("Drag on the page to select an area, then add it:"),
+		container.NewHBox(widget.NewLabel("Preview page:"), previewPageEntry, addAreaBtn),
+		areasBox,
+		widget.NewSeparator(),
+		patternsEntry,
+		pagesEntry,
+		reportCheck,
+		redactBtn,
+	)
+
+	return container.NewVBox(
+		widget.NewLabel("Permanently remove text, images and annotations under areas or search matches"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewBorder(nil, nil, nil, container.NewStack(preview.Object(), drag), form),
+	)
+}
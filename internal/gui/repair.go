@@ -0,0 +1,131 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+// showError reports an error from an operation on inputs. When one of the
+// input PDFs cannot be read, repairing it is offered instead.
+func (a *App) showError(err error, inputs ...string) {
+	for _, input := range inputs {
+		if !strings.EqualFold(filepath.Ext(input), ".pdf") {
+			continue
+		}
+		if _, readErr := a.pdfService.GetPageCount(input); readErr != nil {
+			a.offerRepair(input, readErr)
+			return
+		}
+	}
+	dialog.ShowError(err, a.window)
+}
+
+// offerRepair asks whether to repair a PDF that could not be read
+func (a *App) offerRepair(path string, err error) {
+	message := fmt.Sprintf("%s could not be read:\n%v\n\nTry to repair it and save a fixed copy?", filepath.Base(path), err)
+	dialog.ShowConfirm("Repair PDF", message, func(ok bool) {
+		if ok {
+			a.repairFile(path, nil)
+		}
+	}, a.window)
+}
+
+// repairFile asks for an output file, writes a repaired copy of path to it
+// and calls done with the output file
+func (a *App) repairFile(path string, done func(outputFile string)) {
+	base := filepath.Base(path)
+	suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_repaired.pdf"
+	outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+	if err != nil || outputFile == "" {
+		return
+	}
+	go func() {
+		report, err := a.pdfService.Repair(path, outputFile)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if done != nil {
+			done(outputFile)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Saved %s with %d recovered objects.", filepath.Base(outputFile), report.ObjectsRecovered)
+		if report.StreamLengthsFixed > 0 {
+			fmt.Fprintf(&b, "\nFixed the length of %d streams.", report.StreamLengthsFixed)
+		}
+		if len(report.ReferencesDropped) > 0 {
+			fmt.Fprintf(&b, "\nDropped references to %d missing objects.", len(report.ReferencesDropped))
+		}
+		if len(report.Remaining) > 0 {
+			fmt.Fprintf(&b, "\n%d problems remain; see Validate in the Info tab.", len(report.Remaining))
+		}
+		b.WriteString("\nSelect the repaired copy to continue.")
+		dialog.ShowInformation("Repaired", b.String(), a.window)
+	}()
+}
+
+// makeValidationSection checks the structure of a PDF and repairs it. The
+// returned function loads a PDF into the section; onRepaired is called with
+// the repaired copy.
+func (a *App) makeValidationSection(onRepaired func(path string)) (fyne.CanvasObject, func(path string)) {
+	var selectedFile string
+	levelSelect := widget.NewSelect([]string{"Relaxed", "Strict"}, nil)
+	levelSelect.SetSelected("Relaxed")
+	resultLabel := widget.NewLabel("")
+
+	validateBtn := widget.NewButton("Validate", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		level := models.ValidationRelaxed
+		if levelSelect.Selected == "Strict" {
+			level = models.ValidationStrict
+		}
+		report, err := a.pdfService.Validate(selectedFile, level)
+		if err != nil {
+			resultLabel.SetText("Error: " + err.Error())
+			return
+		}
+		if len(report.Problems) == 0 {
+			resultLabel.SetText(fmt.Sprintf("No problems found (%d objects, %s)", report.Objects, report.Level))
+			return
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d problem(s) found (%d objects, %s):\n", len(report.Problems), report.Objects, report.Level)
+		for _, p := range report.Problems {
+			fmt.Fprintf(&b, "  [%s] %s\n", p.Kind, p.Message)
+		}
+		if !report.Readable {
+			b.WriteString("The file cannot be opened; Repair… may recover it.\n")
+		}
+		resultLabel.SetText(b.String())
+	})
+
+	repairBtn := widget.NewButton("Repair…", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		a.repairFile(selectedFile, onRepaired)
+	})
+
+	load := func(path string) {
+		selectedFile = path
+		resultLabel.SetText("")
+	}
+
+	return container.NewVBox(
+		widget.NewLabel("Structure:"),
+		container.NewHBox(widget.NewLabel("Level:"), levelSelect, validateBtn, repairBtn),
+		resultLabel,
+	), load
+}
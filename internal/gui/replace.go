@@ -0,0 +1,118 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeReplaceTab() fyne.CanvasObject {
+	var selectedFile, sourceFile string
+	fileLabel := widget.NewLabel("No file selected")
+	sourceLabel := widget.NewLabel("No source PDF selected")
+
+	pagesEntry := widget.NewEntry()
+	pagesEntry.SetPlaceHolder("Pages to replace (e.g., 3 or 2,5-6)")
+	sourcePagesEntry := widget.NewEntry()
+	sourcePagesEntry.SetPlaceHolder("Replacement pages from source (empty = all)")
+	ignoreSizesCheck := widget.NewCheck("Allow different page sizes", nil)
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			if count, err := a.pdfService.GetPageCount(selectedFile); err == nil {
+				fileLabel.SetText(fmt.Sprintf("%s (%d pages)", filepath.Base(selectedFile), count))
+			} else {
+				a.offerRepair(selectedFile, err)
+			}
+		}
+	})
+
+	selectSourceBtn := widget.NewButton("Browse Source PDF", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			sourceFile = path
+			sourceLabel.SetText(filepath.Base(sourceFile))
+			if count, err := a.pdfService.GetPageCount(sourceFile); err == nil {
+				sourceLabel.SetText(fmt.Sprintf("%s (%d pages)", filepath.Base(sourceFile), count))
+			} else {
+				a.offerRepair(sourceFile, err)
+			}
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	replaceBtn := widget.NewButton("Replace Pages", func() {
+		if selectedFile == "" || sourceFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file and a source PDF"), a.window)
+			return
+		}
+		pages, err := a.parsePages(pagesEntry.Text, selectedFile)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("invalid page range: %w", err), a.window)
+			return
+		}
+		var sourcePages []int
+		if strings.TrimSpace(sourcePagesEntry.Text) != "" {
+			if sourcePages, err = a.parsePages(sourcePagesEntry.Text, sourceFile); err != nil {
+				dialog.ShowError(fmt.Errorf("invalid source page range: %w", err), a.window)
+				return
+			}
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_replaced.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.ReplacePagesConfig{
+			InputFile:   selectedFile,
+			OutputFile:  outputFile,
+			Pages:       pages,
+			SourceFile:  sourceFile,
+			SourcePages: sourcePages,
+			IgnoreSizes: ignoreSizesCheck.Checked,
+		}
+
+		go func() {
+			if err := a.pdfService.ReplacePages(config); err != nil {
+				a.showError(err, config.InputFile, config.SourceFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "Pages replaced successfully!", a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Replace pages with pages from another PDF (e.g., a re-scanned signed page)"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		pagesEntry,
+		selectSourceBtn,
+		sourceLabel,
+		sourcePagesEntry,
+		ignoreSizesCheck,
+		replaceBtn,
+	)
+}
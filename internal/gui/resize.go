@@ -0,0 +1,105 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+// resizePapers lists the target paper sizes offered for resizing
+var resizePapers = []string{"A4", "A3", "A5", "Letter", "Legal"}
+
+func (a *App) makeResizeTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+
+	paperSelect := widget.NewSelect(resizePapers, nil)
+	paperSelect.SetSelected("A4")
+	orientations := map[string]models.Orientation{"Auto": models.OrientationAuto, "Portrait": models.OrientationPortrait, "Landscape": models.OrientationLandscape}
+	orientationSelect := widget.NewSelect([]string{"Auto", "Portrait", "Landscape"}, nil)
+	orientationSelect.SetSelected("Auto")
+	centerCheck := widget.NewCheck("Center pages on the sheet", nil)
+	centerCheck.SetChecked(true)
+	pagesEntry := widget.NewEntry()
+	pagesEntry.SetPlaceHolder("Pages to resize (empty = all, e.g., 2-10)")
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	resizeBtn := widget.NewButton("Resize PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		var pages []int
+		if strings.TrimSpace(pagesEntry.Text) != "" {
+			var err error
+			if pages, err = a.parsePages(pagesEntry.Text, selectedFile); err != nil {
+				dialog.ShowError(fmt.Errorf("invalid page range: %w", err), a.window)
+				return
+			}
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := fmt.Sprintf("%s_%s.pdf", strings.TrimSuffix(base, filepath.Ext(base)), paperSelect.Selected)
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.ResizeConfig{
+			InputFile:  selectedFile,
+			OutputFile: outputFile,
+			Pages:      pages,
+			ResizeOptions: models.ResizeOptions{
+				PaperSize:   models.PaperSize(paperSelect.Selected),
+				Orientation: orientations[orientationSelect.Selected],
+				Center:      centerCheck.Checked,
+			},
+		}
+
+		go func() {
+			if err := a.pdfService.Resize(config); err != nil {
+				a.showError(err, config.InputFile)
+			} else {
+				_ = a.openFile(outputFile)
+				dialog.ShowInformation("Success", "PDF resized successfully!", a.window)
+			}
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Scale pages to a paper size, keeping their aspect ratio"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewGridWithColumns(2,
+			widget.NewLabel("Paper size:"), paperSelect,
+			widget.NewLabel("Orientation:"), orientationSelect,
+		),
+		centerCheck,
+		pagesEntry,
+		resizeBtn,
+	)
+}
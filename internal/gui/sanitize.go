@@ -0,0 +1,179 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// sanitizeImportsKey is the preference holding the sanitize on import option
+const sanitizeImportsKey = "sanitizeImports"
+
+func (a *App) makeSanitizeTab() fyne.CanvasObject {
+	var selectedFile string
+	fileLabel := widget.NewLabel("No file selected")
+	resultLabel := widget.NewLabel("")
+	resultLabel.Wrapping = fyne.TextWrapWord
+	reportCheck := widget.NewCheck("Save a JSON report next to the output", nil)
+	reportCheck.SetChecked(true)
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		// Pick the file directly: sanitizing it on import as well would do
+		// the work twice
+		path, err := zenity.SelectFile(zenity.FileFilters{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			selectedFile = path
+			fileLabel.SetText(filepath.Base(selectedFile))
+			resultLabel.SetText("")
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	sanitizeBtn := widget.NewButton("Sanitize PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_sanitized.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+
+		config := models.SanitizeConfig{InputFile: selectedFile, OutputFile: outputFile}
+		if reportCheck.Checked {
+			config.ReportFile = strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + "_sanitize.json"
+		}
+
+		go func() {
+			report, err := a.pdfService.Sanitize(config)
+			if err != nil {
+				a.showError(err, config.InputFile)
+				return
+			}
+			if len(report.Removed) == 0 {
+				resultLabel.SetText(fmt.Sprintf("Saved %s; no active content found.", filepath.Base(outputFile)))
+			} else {
+				resultLabel.SetText(fmt.Sprintf("Saved %s; removed %d item(s):\n%s", filepath.Base(outputFile), len(report.Removed), sanitizeRemovalsText(report.Removed)))
+			}
+			dialog.ShowInformation("Success", "PDF sanitized; see the report in the Sanitize tab.", a.window)
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Remove JavaScript, launch actions, embedded executables and automatic links, keeping the visible content"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		reportCheck,
+		sanitizeBtn,
+		resultLabel,
+	)
+}
+
+// makeSanitizeImportsCheck returns the option to sanitize every PDF picked
+// in any tab, remembered between runs
+func (a *App) makeSanitizeImportsCheck() fyne.CanvasObject {
+	prefs := a.fyneApp.Preferences()
+	check := widget.NewCheck("Sanitize PDFs on import", func(on bool) {
+		prefs.SetBool(sanitizeImportsKey, on)
+	})
+	check.SetChecked(prefs.Bool(sanitizeImportsKey))
+	return check
+}
+
+// importFiles replaces picked PDFs by sanitized copies when sanitizing on
+// import is on. The copies keep the file names, in temporary folders, and
+// are removed when the application exits. It reports what was removed and
+// returns an error if a file could not be sanitized.
+func (a *App) importFiles(paths []string) ([]string, error) {
+	if !a.fyneApp.Preferences().Bool(sanitizeImportsKey) {
+		return paths, nil
+	}
+
+	imported := make([]string, len(paths))
+	var b strings.Builder
+	for i, path := range paths {
+		imported[i] = path
+		if !utils.IsPDF(path) {
+			continue
+		}
+		dir, err := os.MkdirTemp("", "pdf-toolbox-sanitized-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary folder: %w", err)
+		}
+		copyFile := filepath.Join(dir, filepath.Base(path))
+		report, err := a.pdfService.Sanitize(models.SanitizeConfig{InputFile: path, OutputFile: copyFile})
+		if err != nil {
+			os.RemoveAll(dir)
+			a.showError(fmt.Errorf("failed to sanitize %s: %w", filepath.Base(path), err), path)
+			return nil, err
+		}
+		a.sanitizedMu.Lock()
+		a.sanitized[copyFile] = path
+		a.sanitizedMu.Unlock()
+		imported[i] = copyFile
+		if len(report.Removed) > 0 {
+			fmt.Fprintf(&b, "%s: removed %d item(s)\n%s", filepath.Base(path), len(report.Removed), sanitizeRemovalsText(report.Removed))
+		}
+	}
+	if b.Len() > 0 {
+		dialog.ShowInformation("Sanitized", b.String(), a.window)
+	}
+	return imported, nil
+}
+
+// originalPath returns the file a sanitized copy was made from, or path
+// itself when it is not a copy
+func (a *App) originalPath(path string) string {
+	a.sanitizedMu.Lock()
+	defer a.sanitizedMu.Unlock()
+	if original, ok := a.sanitized[path]; ok {
+		return original
+	}
+	return path
+}
+
+// removeSanitizedCopies deletes the copies made on import
+func (a *App) removeSanitizedCopies() {
+	a.sanitizedMu.Lock()
+	defer a.sanitizedMu.Unlock()
+	for copyFile := range a.sanitized {
+		os.RemoveAll(filepath.Dir(copyFile))
+	}
+}
+
+// sanitizeRemovalsText lists removed items one per line with where they were
+func sanitizeRemovalsText(removed []models.SanitizeRemoval) string {
+	var b strings.Builder
+	for _, r := range removed {
+		where := r.Location
+		if r.Page > 0 {
+			where = fmt.Sprintf("%s, page %d", where, r.Page)
+		}
+		if r.Detail != "" {
+			fmt.Fprintf(&b, "  [%s] %s: %s\n", r.Kind, where, r.Detail)
+		} else {
+			fmt.Fprintf(&b, "  [%s] %s\n", r.Kind, where)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"pdf-toolbox/pkg/models"
+)
+
+func (a *App) makeSignTab() fyne.CanvasObject {
+	var selectedFile, certFile, imageFile, tsaFile string
+	fileLabel := widget.NewLabel("No file selected")
+	certLabel := widget.NewLabel("No certificate selected")
+	imageLabel := widget.NewLabel("No image (text only)")
+	tsaLabel := widget.NewLabel("No TSA certificate selected")
+
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("Certificate password")
+	reasonEntry := widget.NewEntry()
+	reasonEntry.SetPlaceHolder("Reason (optional)")
+	locationEntry := widget.NewEntry()
+	locationEntry.SetPlaceHolder("Location (optional)")
+
+	visibleCheck := widget.NewCheck("Visible signature", nil)
+	pageEntry := widget.NewEntry()
+	pageEntry.SetText("1")
+	rectEntries := make([]*widget.Entry, 4) // left, bottom, right, top
+	for i, v := range []string{"36", "36", "236", "96"} {
+		rectEntries[i] = widget.NewEntry()
+		rectEntries[i].SetText(v)
+	}
+
+	timestampCheck := widget.NewCheck("Timestamp with a local time-stamping certificate", nil)
+	tsaPasswordEntry := widget.NewPasswordEntry()
+	tsaPasswordEntry.SetPlaceHolder("TSA certificate password")
+
+	p12Filter := []zenity.FileFilter{{Name: "PKCS#12", Patterns: []string{"*.p12", "*.pfx"}}}
+
+	selectFileBtn := widget.NewButton("Browse PDF File", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err == nil && path != "" {
+			// Sign the original, not a copy sanitized on import: signing
+			// must not change the document behind the user's back
+			selectedFile = a.originalPath(path)
+			fileLabel.SetText(filepath.Base(selectedFile))
+		}
+	})
+
+	previewBtn := widget.NewButton("Preview PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowInformation("Preview", "Please select a PDF file first", a.window)
+			return
+		}
+		if err := a.openFile(selectedFile); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	})
+
+	certBtn := widget.NewButton("Certificate…", func() {
+		path, err := a.selectNativeSingle(p12Filter)
+		if err == nil && path != "" {
+			certFile = path
+			certLabel.SetText(filepath.Base(path))
+		}
+	})
+
+	imageBtn := widget.NewButton("Image…", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "Images", Patterns: []string{"*.png", "*.jpg", "*.jpeg"}}})
+		if err == nil && path != "" {
+			imageFile = path
+			imageLabel.SetText(filepath.Base(path))
+		}
+	})
+
+	tsaBtn := widget.NewButton("TSA Certificate…", func() {
+		path, err := a.selectNativeSingle(p12Filter)
+		if err == nil && path != "" {
+			tsaFile = path
+			tsaLabel.SetText(filepath.Base(path))
+		}
+	})
+
+	signBtn := widget.NewButton("Sign PDF", func() {
+		if selectedFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PDF file"), a.window)
+			return
+		}
+		if certFile == "" {
+			dialog.ShowError(fmt.Errorf("please select a PKCS#12 certificate"), a.window)
+			return
+		}
+
+		config := models.SignConfig{
+			InputFile: selectedFile,
+			CertFile:  certFile,
+			Password:  passwordEntry.Text,
+			Reason:    strings.TrimSpace(reasonEntry.Text),
+			Location:  strings.TrimSpace(locationEntry.Text),
+		}
+		if visibleCheck.Checked {
+			page, err := strconv.Atoi(strings.TrimSpace(pageEntry.Text))
+			if err != nil || page < 1 {
+				dialog.ShowError(fmt.Errorf("please enter a valid page number"), a.window)
+				return
+			}
+			appearance := &models.SignatureAppearance{Page: page, ImageFile: imageFile}
+			for i, e := range rectEntries {
+				v, err := strconv.ParseFloat(strings.TrimSpace(e.Text), 64)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("please enter a valid signature rectangle"), a.window)
+					return
+				}
+				appearance.Rect[i] = v
+			}
+			if appearance.Rect[2] <= appearance.Rect[0] || appearance.Rect[3] <= appearance.Rect[1] {
+				dialog.ShowError(fmt.Errorf("the signature rectangle must have a positive size"), a.window)
+				return
+			}
+			config.Appearance = appearance
+		}
+		if timestampCheck.Checked {
+			if tsaFile == "" {
+				dialog.ShowError(fmt.Errorf("please select a time-stamping certificate"), a.window)
+				return
+			}
+			config.Timestamp = &models.TimestampConfig{CertFile: tsaFile, Password: tsaPasswordEntry.Text}
+		}
+
+		base := filepath.Base(selectedFile)
+		suggested := strings.TrimSuffix(base, filepath.Ext(base)) + "_signed.pdf"
+		outputFile, err := a.selectNativeSave(suggested, []zenity.FileFilter{{Name: "PDF", Patterns: []string{"*.pdf"}}})
+		if err != nil || outputFile == "" {
+			return
+		}
+		config.OutputFile = outputFile
+
+		go func() {
+			if err := a.pdfService.Sign(config); err != nil {
+				a.showError(err, config.InputFile)
+				return
+			}
+			_ = a.openFile(outputFile)
+			dialog.ShowInformation("Success", "PDF signed successfully!", a.window)
+		}()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Digitally sign a PDF with a PKCS#12 (.p12/.pfx) certificate"),
+		container.NewHBox(selectFileBtn, previewBtn),
+		fileLabel,
+		container.NewBorder(nil, nil, nil, certBtn, certLabel),
+		passwordEntry,
+		reasonEntry,
+		locationEntry,
+		widget.NewSeparator(),
+		visibleCheck,
+		container.NewGridWithColumns(2, widget.NewLabel("Page:"), pageEntry),
+		widget.NewLabel("Rectangle (pt from the bottom left corner):"),
+		container.NewGridWithColumns(4,
+			widget.NewLabel("Left:"), rectEntries[0],
+			widget.NewLabel("Bottom:"), rectEntries[1],
+			widget.NewLabel("Right:"), rectEntries[2],
+			widget.NewLabel("Top:"), rectEntries[3],
+		),
+		container.NewBorder(nil, nil, nil, imageBtn, imageLabel),
+		widget.NewSeparator(),
+		timestampCheck,
+		container.NewBorder(nil, nil, nil, tsaBtn, tsaLabel),
+		tsaPasswordEntry,
+		signBtn,
+	)
+}
@@ -0,0 +1,87 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+)
+
+// makeSignaturesSection shows the digital signatures of a PDF and whether
+// they verify against a trust store chosen by the user. The returned
+// function loads a PDF into the section.
+func (a *App) makeSignaturesSection() (fyne.CanvasObject, func(path string)) {
+	var selectedFile, trustStore string
+	trustLabel := widget.NewLabel("Trust store: none (no certificate is trusted)")
+	signaturesLabel := widget.NewLabel("")
+
+	load := func(path string) {
+		selectedFile = path
+		if path == "" {
+			return
+		}
+		signatures, err := a.pdfService.VerifySignatures(path, trustStore)
+		if err != nil {
+			signaturesLabel.SetText("Error: " + err.Error())
+			return
+		}
+		if len(signatures) == 0 {
+			signaturesLabel.SetText("Not signed")
+			return
+		}
+
+		var b strings.Builder
+		for i, sig := range signatures {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			fmt.Fprintf(&b, "%s: %s\n", sig.Field, sig.Kind)
+			fmt.Fprintf(&b, "  Signer: %s (issued by %s)\n", sig.Signer, sig.Issuer)
+			if !sig.SigningTime.IsZero() {
+				fmt.Fprintf(&b, "  Signed: %s\n", sig.SigningTime.Format("2006-01-02 15:04:05 -0700"))
+			}
+			if sig.Reason != "" || sig.Location != "" {
+				fmt.Fprintf(&b, "  Reason: %s  Location: %s\n", sig.Reason, sig.Location)
+			}
+			var ranges []string
+			for j := 0; j+1 < len(sig.ByteRange); j += 2 {
+				ranges = append(ranges, fmt.Sprintf("%d-%d", sig.ByteRange[j], sig.ByteRange[j]+sig.ByteRange[j+1]))
+			}
+			fmt.Fprintf(&b, "  Signed bytes: %s", strings.Join(ranges, ", "))
+			if !sig.WholeFile {
+				b.WriteString(" (content was appended after signing)")
+			}
+			b.WriteByte('\n')
+			fmt.Fprintf(&b, "  Modified after signing: %s\n", sig.Modified)
+			fmt.Fprintf(&b, "  Status: %s - %s", sig.Status, sig.Details)
+			if sig.Trusted {
+				b.WriteString(" (trusted)")
+			}
+			b.WriteByte('\n')
+			for _, p := range sig.Problems {
+				fmt.Fprintf(&b, "  ! %s\n", strings.TrimSpace(p))
+			}
+		}
+		signaturesLabel.SetText(b.String())
+	}
+
+	trustBtn := widget.NewButton("Trust Store…", func() {
+		path, err := a.selectNativeSingle([]zenity.FileFilter{{Name: "Certificates", Patterns: []string{"*.pem", "*.crt", "*.cer", "*.p7c"}}})
+		if err != nil || path == "" {
+			return
+		}
+		trustStore = path
+		trustLabel.SetText("Trust store: " + filepath.Base(path))
+		load(selectedFile)
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Signatures:"),
+		container.NewBorder(nil, nil, nil, trustBtn, trustLabel),
+		signaturesLabel,
+	), load
+}
@@ -19,6 +19,8 @@ type SortableList struct {
     selected *map[int]bool
     box      *fyne.Container
     onChange func()
+    // thumbnail optionally renders a small preview shown in each row
+    thumbnail func(path string) fyne.CanvasObject
 
     // drag state
     dragging bool
@@ -46,6 +48,9 @@ func (s *SortableList) rebuild() {
     s.box.Objects = nil
     for i, p := range *s.items {
         row := newSortableRow(s, i, filepath.Base(p))
+        if s.thumbnail != nil {
+            row.thumb = s.thumbnail(p)
+        }
         s.box.Add(row)
     }
     s.box.Refresh()
@@ -110,6 +115,7 @@ type sortableRow struct {
     handle   *dragHandle
     check    *widget.Check
     label    *widget.Label
+    thumb    fyne.CanvasObject
     delBtn   *widget.Button
     bg       *canvas.Rectangle
     dragAccY float32
@@ -142,6 +148,9 @@ func (r *sortableRow) CreateRenderer() fyne.WidgetRenderer {
         r.bg = canvas.NewRectangle(color.NRGBA{R: 0, G: 0, B: 0, A: 0})
     }
     content := container.NewHBox(r.handle, r.check, r.label, layout.NewSpacer(), r.delBtn)
+    if r.thumb != nil {
+        content = container.NewHBox(r.handle, r.check, r.thumb, r.label, layout.NewSpacer(), r.delBtn)
+    }
     stacked := container.NewStack(r.bg, content)
     return widget.NewSimpleRenderer(stacked)
 }
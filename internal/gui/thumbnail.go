@@ -0,0 +1,90 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"golang.org/x/image/draw"
+	"pdf-toolbox/internal/pdf"
+)
+
+// thumbnailSize is the edge length of list thumbnails
+const thumbnailSize = 48
+
+// imageThumbnails renders row thumbnails for image files. Images are decoded in
+// the background and cached per path and orientation setting.
+type imageThumbnails struct {
+	mu        sync.Mutex
+	cache     map[string]image.Image
+	applyEXIF bool
+}
+
+func newImageThumbnails() *imageThumbnails {
+	return &imageThumbnails{cache: map[string]image.Image{}, applyEXIF: true}
+}
+
+// object returns a canvas image for path that fills in once decoding finishes
+func (t *imageThumbnails) object(path string) fyne.CanvasObject {
+	img := canvas.NewImageFromImage(nil)
+	img.FillMode = canvas.ImageFillContain
+	img.SetMinSize(fyne.NewSize(thumbnailSize, thumbnailSize))
+
+	applyEXIF := t.applyEXIF
+	key := fmt.Sprintf("%s|%v", path, applyEXIF)
+	t.mu.Lock()
+	cached, ok := t.cache[key]
+	t.mu.Unlock()
+	if ok {
+		img.Image = cached
+		return img
+	}
+
+	go func() {
+		thumb, err := loadImageThumbnail(path, thumbnailSize*2, applyEXIF)
+		if err != nil {
+			return
+		}
+		t.mu.Lock()
+		t.cache[key] = thumb
+		t.mu.Unlock()
+		fyne.Do(func() {
+			img.Image = thumb
+			img.Refresh()
+		})
+	}()
+	return img
+}
+
+// loadImageThumbnail decodes path and scales it so the longer side is at most size
+func loadImageThumbnail(path string, size int, applyEXIF bool) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	if applyEXIF {
+		return pdf.OrientImage(dst, pdf.JPEGOrientation(data)), nil
+	}
+	return dst, nil
+}
@@ -0,0 +1,210 @@
+package pdf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// ListAnnotations returns the annotations of all pages. Popups are left out
+// as they only show the contents of their parent annotation.
+func (s *Service) ListAnnotations(filePath string) ([]models.Annotation, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	var list []models.Annotation
+	for page := 1; page <= ctx.PageCount; page++ {
+		d, _, _, err := ctx.PageDict(page, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+		annots, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read annotations of page %d: %w", page, err)
+		}
+		for _, o := range annots {
+			annot, err := ctx.DereferenceDict(o)
+			if err != nil || annot == nil || annotationSubtype(annot) == "Popup" {
+				continue
+			}
+			list = append(list, annotationInfo(ctx.XRefTable, page, annot))
+		}
+	}
+	return list, nil
+}
+
+// ExportAnnotations writes the annotations of a PDF to outFile as CSV when
+// its extension is .csv and as JSON otherwise
+func (s *Service) ExportAnnotations(filePath, outFile string) error {
+	list, err := s.ListAnnotations(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(outFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(outFile), ".csv") {
+		return writeAnnotationsCSV(list, outFile)
+	}
+
+	if list == nil {
+		list = []models.Annotation{}
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outFile, append(data, '\n'), 0644)
+}
+
+// RemoveAnnotations deletes the annotations matching the configured types and
+// authors, together with their popups. Removed widgets take their form fields
+// with them, so no field is left without a place on a page.
+func (s *Service) RemoveAnnotations(config models.RemoveAnnotationsConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+	if len(config.Types) == 0 && len(config.Authors) == 0 {
+		return fmt.Errorf("select the annotation types or authors to remove")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, err := api.ReadContextFile(config.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	matches := func(annot types.Dict) bool {
+		if len(config.Types) > 0 && !slices.Contains(config.Types, annotationSubtype(annot)) {
+			return false
+		}
+		if len(config.Authors) > 0 && !slices.Contains(config.Authors, annotationText(ctx.XRefTable, annot, "T")) {
+			return false
+		}
+		return true
+	}
+
+	count := 0
+	widgets := map[types.IndirectRef]bool{}
+	for page := 1; page <= ctx.PageCount; page++ {
+		d, _, _, err := ctx.PageDict(page, false)
+		if err != nil {
+			return fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+		annots, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil || len(annots) == 0 {
+			continue
+		}
+
+		var kept types.Array
+		removed := map[types.IndirectRef]bool{}
+		for _, o := range annots {
+			annot, err := ctx.DereferenceDict(o)
+			if err != nil || annot == nil || annotationSubtype(annot) == "Popup" || !matches(annot) {
+				kept = append(kept, o)
+				continue
+			}
+			if ref, ok := o.(types.IndirectRef); ok {
+				removed[ref] = true
+				if annotationSubtype(annot) == "Widget" {
+					widgets[ref] = true
+				}
+			}
+			count++
+		}
+		if len(kept) < len(annots) {
+			setPageAnnotations(ctx.XRefTable, d, kept, removed)
+		}
+	}
+	if count == 0 {
+		return fmt.Errorf("no annotations match")
+	}
+	if err := removeFormFields(ctx.XRefTable, widgets); err != nil {
+		return err
+	}
+
+	return api.WriteContextFile(ctx, config.OutputFile)
+}
+
+// annotationInfo describes annot on the given page
+func annotationInfo(xRefTable *model.XRefTable, page int, annot types.Dict) models.Annotation {
+	a := models.Annotation{
+		Page:     page,
+		Type:     annotationSubtype(annot),
+		Author:   annotationText(xRefTable, annot, "T"),
+		Contents: annotationText(xRefTable, annot, "Contents"),
+	}
+	if rect, err := xRefTable.DereferenceArray(annot["Rect"]); err == nil && len(rect) == 4 {
+		r := numbers(rect)
+		a.Rect = [4]float64{min(r[0], r[2]), min(r[1], r[3]), max(r[0], r[2]), max(r[1], r[3])}
+	}
+	if m := annotationText(xRefTable, annot, "M"); m != "" {
+		if t, ok := types.DateTime(m, true); ok {
+			a.Modified = t
+		}
+	}
+	return a
+}
+
+// annotationText returns the decoded text string stored under key in annot
+func annotationText(xRefTable *model.XRefTable, annot types.Dict, key string) string {
+	o, err := xRefTable.Dereference(annot[key])
+	if err != nil || o == nil {
+		return ""
+	}
+	s, err := types.StringOrHexLiteral(o)
+	if err != nil || s == nil {
+		return ""
+	}
+	return *s
+}
+
+func writeAnnotationsCSV(list []models.Annotation, outFile string) error {
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"page", "type", "author", "contents", "llx", "lly", "urx", "ury", "modified"})
+	for _, a := range list {
+		record := []string{strconv.Itoa(a.Page), a.Type, a.Author, a.Contents}
+		for _, v := range a.Rect {
+			record = append(record, formatNumber(v))
+		}
+		modified := ""
+		if !a.Modified.IsZero() {
+			modified = a.Modified.Format(time.RFC3339)
+		}
+		_ = w.Write(append(record, modified))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,159 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// ListAttachments returns the files embedded in a PDF
+func (s *Service) ListAttachments(filePath string) ([]models.Attachment, error) {
+	attachments, err := readAttachments(filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]models.Attachment, 0, len(attachments))
+	for _, a := range attachments {
+		n, _ := io.Copy(io.Discard, a)
+		item := models.Attachment{ID: a.ID, FileName: a.FileName, Description: a.Desc, Size: n}
+		if a.ModTime != nil {
+			item.Modified = *a.ModTime
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// ExtractAttachment saves the embedded file with the given ID to outFile
+func (s *Service) ExtractAttachment(filePath, id, outFile string) error {
+	attachments, err := readAttachments(filePath, []string{id})
+	if err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return fmt.Errorf("attachment %q not found", id)
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(outFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return writeAttachment(attachments[0], outFile)
+}
+
+// ExtractAttachments saves all embedded files into outDir and returns their
+// paths. A name already used by an earlier attachment or by a file in outDir
+// gets a number, as in report_2.pdf, so nothing is overwritten.
+func (s *Service) ExtractAttachments(filePath, outDir string) ([]string, error) {
+	attachments, err := readAttachments(filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.EnsureDir(outDir); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var written []string
+	for _, a := range attachments {
+		outFile := uniqueAttachmentPath(outDir, a.FileName)
+		if err := writeAttachment(a, outFile); err != nil {
+			return written, err
+		}
+		written = append(written, outFile)
+	}
+	return written, nil
+}
+
+// uniqueAttachmentPath returns a path in dir for an attachment name that
+// does not exist yet
+func uniqueAttachmentPath(dir, name string) string {
+	// Attachment names come from the document, so never let them leave dir
+	name = filepath.Base(name)
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		name = "attachment"
+	}
+	path := filepath.Join(dir, name)
+	ext := filepath.Ext(name)
+	for n := 2; utils.FileExists(path); n++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), n, ext))
+	}
+	return path
+}
+
+// AddAttachments embeds files into a PDF
+func (s *Service) AddAttachments(inputFile, outputFile string, files []string) error {
+	if !utils.IsPDF(inputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no files to attach")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(outputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return api.AddAttachmentsFile(inputFile, outputFile, files, false, nil)
+}
+
+// RemoveAttachments deletes the embedded files with the given IDs, or all of
+// them when ids is empty
+func (s *Service) RemoveAttachments(inputFile, outputFile string, ids []string) error {
+	if !utils.IsPDF(inputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(outputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return api.RemoveAttachmentsFile(inputFile, outputFile, ids, nil)
+}
+
+func readAttachments(filePath string, ids []string) ([]model.Attachment, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTATTACHMENTS
+	ctx, err := api.ReadAndValidate(f, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if ctx.Names["EmbeddedFiles"] == nil {
+		return nil, nil
+	}
+
+	attachments, err := ctx.ExtractAttachments(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+func writeAttachment(a model.Attachment, outFile string) error {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, a); err != nil {
+		return fmt.Errorf("failed to read attachment %s: %w", a.FileName, err)
+	}
+	if err := os.WriteFile(outFile, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to save attachment %s: %w", a.FileName, err)
+	}
+	return nil
+}
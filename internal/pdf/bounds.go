@@ -0,0 +1,443 @@
+package pdf
+
+import (
+	"image"
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// matrix is a PDF transformation matrix [a b c d e f]
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m followed by n
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// invert returns the inverse of m, or identity if m is not invertible
+func (m matrix) invert() matrix {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return identity
+	}
+	a, b, c, d := m[3]/det, -m[1]/det, -m[2]/det, m[0]/det
+	return matrix{a, b, c, d, -(m[4]*a + m[5]*c), -(m[4]*b + m[5]*d)}
+}
+
+func toMatrix(f []float64) matrix {
+	if len(f) < 6 {
+		return identity
+	}
+	return matrix{f[0], f[1], f[2], f[3], f[4], f[5]}
+}
+
+// bbox accumulates an axis-aligned bounding box
+type bbox struct {
+	x0, y0, x1, y1 float64
+	ok             bool
+}
+
+func (b *bbox) add(x, y float64) {
+	if !b.ok {
+		*b = bbox{x, y, x, y, true}
+		return
+	}
+	b.x0, b.y0 = math.Min(b.x0, x), math.Min(b.y0, y)
+	b.x1, b.y1 = math.Max(b.x1, x), math.Max(b.y1, y)
+}
+
+func (b *bbox) union(o bbox) {
+	if o.ok {
+		b.add(o.x0, o.y0)
+		b.add(o.x1, o.y1)
+	}
+}
+
+// intersect returns the overlap of b and o; an unset box does not restrict
+func (b bbox) intersect(o bbox) bbox {
+	if !b.ok {
+		return o
+	}
+	if !o.ok {
+		return b
+	}
+	r := bbox{math.Max(b.x0, o.x0), math.Max(b.y0, o.y0), math.Min(b.x1, o.x1), math.Min(b.y1, o.y1), true}
+	r.ok = r.x0 <= r.x1 && r.y0 <= r.y1
+	return r
+}
+
+// addRect adds the corners of the rectangle (x0,y0)-(x1,y1) transformed by m
+func (b *bbox) addRect(m matrix, x0, y0, x1, y1 float64) {
+	for _, p := range [][2]float64{{x0, y0}, {x1, y0}, {x0, y1}, {x1, y1}} {
+		b.add(m.apply(p[0], p[1]))
+	}
+}
+
+// maxFormDepth limits recursion into nested form XObjects
+const maxFormDepth = 8
+
+// boundsWalker computes the bounds of everything painted on a page
+type boundsWalker struct {
+	xRefTable *model.XRefTable
+	images    map[int]image.Image // decoded page images by object number
+	bounds    bbox
+}
+
+// pageContentBounds returns the area of a page covered by visible content, in
+// default user space. Text extents are estimated from the font size, and
+// scanned images contribute only their non-blank region.
+func pageContentBounds(ctx *model.Context, pageNr int) (bbox, error) {
+	d, _, inh, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return bbox{}, err
+	}
+	data, err := ctx.PageContent(d, pageNr)
+	if err != nil {
+		if err == model.ErrNoContent {
+			return bbox{}, nil
+		}
+		return bbox{}, err
+	}
+	ops, err := parseContent(data)
+	if err != nil {
+		return bbox{}, err
+	}
+
+	w := &boundsWalker{xRefTable: ctx.XRefTable, images: map[int]image.Image{}}
+	if imgs, err := pdfcpu.ExtractPageImages(ctx, pageNr, false); err == nil {
+		for objNr, img := range imgs {
+			if decoded, _, err := image.Decode(img); err == nil {
+				w.images[objNr] = decoded
+			}
+		}
+	}
+
+	var res types.Dict
+	if inh != nil {
+		res = inh.Resources
+	}
+	w.walk(ops, res, identity, 0)
+	return w.bounds, nil
+}
+
+type textState struct {
+	tm, tlm              matrix
+	size, scale, rise    float64
+	charSpace, wordSpace float64
+	leading              float64
+}
+
+func (w *boundsWalker) walk(ops []contentOp, res types.Dict, ctm matrix, depth int) {
+	type state struct {
+		ctm   matrix
+		clip  bbox
+		white bool
+		text  textState
+	}
+	gs := state{ctm: ctm, text: textState{scale: 1}}
+	var stack []state
+	var path bbox
+	var clipping bool
+
+	paint := func(b bbox) {
+		if b.ok {
+			w.bounds.union(b.intersect(gs.clip))
+		}
+	}
+	// endPath applies a pending clip and starts a new path
+	endPath := func() {
+		if clipping {
+			gs.clip = path.intersect(gs.clip)
+			if !gs.clip.ok {
+				// Everything is clipped away; keep an empty, set box
+				gs.clip = bbox{ok: true, x0: 1, x1: 0}
+			}
+			clipping = false
+		}
+		path = bbox{}
+	}
+
+	for _, op := range ops {
+		f := numbers(op.Operands)
+		switch op.Operator {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if n := len(stack); n > 0 {
+				gs, stack = stack[n-1], stack[:n-1]
+			}
+		case "cm":
+			gs.ctm = toMatrix(f).mul(gs.ctm)
+
+		// Fill color, to ignore white page backgrounds
+		case "g":
+			gs.white = len(f) == 1 && f[0] >= 0.99
+		case "rg":
+			gs.white = len(f) == 3 && f[0] >= 0.99 && f[1] >= 0.99 && f[2] >= 0.99
+		case "k":
+			gs.white = len(f) == 4 && f[0]+f[1]+f[2]+f[3] <= 0.01
+		case "sc", "scn":
+			gs.white = false
+
+		// Paths
+		case "m", "l":
+			if len(f) >= 2 {
+				path.add(gs.ctm.apply(f[0], f[1]))
+			}
+		case "c", "v", "y":
+			for i := 0; i+1 < len(f); i += 2 {
+				path.add(gs.ctm.apply(f[i], f[i+1]))
+			}
+		case "re":
+			if len(f) >= 4 {
+				path.addRect(gs.ctm, f[0], f[1], f[0]+f[2], f[1]+f[3])
+			}
+		case "W", "W*":
+			clipping = true
+		case "S", "s", "B", "B*", "b", "b*":
+			paint(path)
+			endPath()
+		case "f", "F", "f*":
+			if !gs.white {
+				paint(path)
+			}
+			endPath()
+		case "n":
+			endPath()
+
+		// Text
+		case "BT":
+			gs.text.tm, gs.text.tlm = identity, identity
+		case "Tf":
+			if len(f) >= 2 {
+				gs.text.size = f[1]
+			}
+		case "Tc":
+			if len(f) >= 1 {
+				gs.text.charSpace = f[0]
+			}
+		case "Tw":
+			if len(f) >= 1 {
+				gs.text.wordSpace = f[0]
+			}
+		case "Tz":
+			if len(f) >= 1 {
+				gs.text.scale = f[0] / 100
+			}
+		case "TL":
+			if len(f) >= 1 {
+				gs.text.leading = f[0]
+			}
+		case "Ts":
+			if len(f) >= 1 {
+				gs.text.rise = f[0]
+			}
+		case "Td", "TD":
+			if len(f) >= 2 {
+				if op.Operator == "TD" {
+					gs.text.leading = -f[1]
+				}
+				gs.text.tlm = matrix{1, 0, 0, 1, f[0], f[1]}.mul(gs.text.tlm)
+				gs.text.tm = gs.text.tlm
+			}
+		case "Tm":
+			gs.text.tlm = toMatrix(f)
+			gs.text.tm = gs.text.tlm
+		case "T*":
+			gs.text.tlm = matrix{1, 0, 0, 1, 0, -gs.text.leading}.mul(gs.text.tlm)
+			gs.text.tm = gs.text.tlm
+		case "Tj", "'", "\"", "TJ":
+			if op.Operator != "Tj" && op.Operator != "TJ" {
+				gs.text.tlm = matrix{1, 0, 0, 1, 0, -gs.text.leading}.mul(gs.text.tlm)
+				gs.text.tm = gs.text.tlm
+			}
+			if len(op.Operands) == 0 {
+				continue
+			}
+			paint(showText(&gs.text, gs.ctm, op.Operands[len(op.Operands)-1]))
+
+		// Images and forms
+		case "BI":
+			var b bbox
+			b.addRect(gs.ctm, 0, 0, 1, 1)
+			paint(b)
+		case "Do":
+			if len(op.Operands) == 1 {
+				if name, ok := op.Operands[0].(types.Name); ok {
+					paint(w.xObject(string(name), res, gs.ctm, depth))
+				}
+			}
+		}
+	}
+}
+
+// showText returns the estimated extent of a text showing operand and advances
+// the text matrix. Glyphs are assumed to be half an em wide.
+func showText(ts *textState, ctm matrix, o types.Object) bbox {
+	advance := func(s []byte) float64 {
+		var width float64
+		for _, c := range s {
+			width += 0.5*ts.size + ts.charSpace
+			if c == ' ' {
+				width += ts.wordSpace
+			}
+		}
+		return width * ts.scale
+	}
+
+	var width float64
+	if arr, ok := o.(types.Array); ok {
+		for _, e := range arr {
+			if s := stringBytes(e); s != nil {
+				width += advance(s)
+			} else {
+				width -= number(e) / 1000 * ts.size * ts.scale
+			}
+		}
+	} else {
+		width = advance(stringBytes(o))
+	}
+	var b bbox
+	if width == 0 {
+		return b
+	}
+
+	b.addRect(ts.tm.mul(ctm), 0, ts.rise-0.25*ts.size, width, ts.rise+0.85*ts.size)
+	ts.tm = matrix{1, 0, 0, 1, width, 0}.mul(ts.tm)
+	return b
+}
+
+// xObject returns the bounds of the named image or form XObject drawn with ctm
+func (w *boundsWalker) xObject(name string, res types.Dict, ctm matrix, depth int) (b bbox) {
+	if res == nil {
+		return b
+	}
+	xobjs, err := w.xRefTable.DereferenceDict(res["XObject"])
+	if err != nil || xobjs == nil {
+		return b
+	}
+	ref, _ := xobjs[name].(types.IndirectRef)
+	o, err := w.xRefTable.Dereference(xobjs[name])
+	if err != nil {
+		return b
+	}
+	sd, ok := o.(types.StreamDict)
+	if !ok {
+		return b
+	}
+
+	subtype := sd.Subtype()
+	if subtype == nil {
+		return b
+	}
+	switch *subtype {
+	case "Image":
+		x0, y0, x1, y1 := 0.0, 0.0, 1.0, 1.0
+		if img, ok := w.images[ref.ObjectNumber.Value()]; ok {
+			var found bool
+			if x0, y0, x1, y1, found = imageContentRect(img); !found {
+				return b
+			}
+		}
+		b.addRect(ctm, x0, y0, x1, y1)
+	case "Form":
+		if depth >= maxFormDepth {
+			return b
+		}
+		if err := sd.Decode(); err != nil {
+			return b
+		}
+		ops, err := parseContent(sd.Content)
+		if err != nil {
+			return b
+		}
+		m := identity
+		if arr, err := w.xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(arr) == 6 {
+			m = toMatrix(numbers(arr))
+		}
+		formRes := res
+		if d, err := w.xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+			formRes = d
+		}
+		inner := &boundsWalker{xRefTable: w.xRefTable, images: w.images}
+		inner.walk(ops, formRes, m.mul(ctm), depth+1)
+		return inner.bounds
+	}
+	return b
+}
+
+// imageContentRect returns the part of img that is not blank margin or solid
+// scanner border, in image space (the unit square, origin bottom-left)
+func imageContentRect(img image.Image) (x0, y0, x1, y1 float64, ok bool) {
+	rgba := toRGBA(img)
+	b := rgba.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0, 0, 0, 0, false
+	}
+	level := otsu(lumaHistogram(rgba))
+
+	dark := func(x, y int) bool { return luma(rgba.Pix, rgba.PixOffset(x, y)) <= level }
+	// A line is blank when almost all of it is the same class, either paper or border
+	uniform := func(n, total int) bool { return n*100 <= total || n*100 >= total*99 }
+
+	top, bottom, left, right := 0, h-1, 0, w-1
+	rowBlank := func(y int) bool {
+		n := 0
+		for x := left; x <= right; x++ {
+			if dark(x, y) {
+				n++
+			}
+		}
+		return uniform(n, right-left+1)
+	}
+	colBlank := func(x int) bool {
+		n := 0
+		for y := top; y <= bottom; y++ {
+			if dark(x, y) {
+				n++
+			}
+		}
+		return uniform(n, bottom-top+1)
+	}
+
+	// Borders make the lines crossing them look non-blank, so keep peeling
+	// blank edges until none are left
+	for changed := true; changed; {
+		changed = false
+		for top <= bottom && left <= right && rowBlank(top) {
+			top, changed = top+1, true
+		}
+		for bottom >= top && left <= right && rowBlank(bottom) {
+			bottom, changed = bottom-1, true
+		}
+		for left <= right && top <= bottom && colBlank(left) {
+			left, changed = left+1, true
+		}
+		for right >= left && top <= bottom && colBlank(right) {
+			right, changed = right-1, true
+		}
+	}
+	if top > bottom || left > right {
+		return 0, 0, 0, 0, false
+	}
+
+	fw, fh := float64(w), float64(h)
+	return float64(left) / fw, 1 - float64(bottom+1)/fh, float64(right+1) / fw, 1 - float64(top)/fh, true
+}
@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	oidData                 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidTimeStampToken       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+	oidTSTInfo              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidSHA256               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncryption        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidAnyPolicy            = asn1.ObjectIdentifier{2, 5, 29, 32, 0}
+)
+
+// cmsSigner creates CMS (RFC 5652) signatures with a key and its
+// certificate chain
+type cmsSigner struct {
+	key   crypto.Signer
+	cert  *x509.Certificate
+	chain []*x509.Certificate
+}
+
+// loadSigner reads the private key and certificates of a PKCS#12 file
+func loadSigner(file, password string) (*cmsSigner, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	key, cert, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, RSA or ECDSA keys are needed", key)
+	}
+	return &cmsSigner{key: key.(crypto.Signer), cert: cert, chain: chain}, nil
+}
+
+// certificates returns the signer certificate followed by its chain
+func (s *cmsSigner) certificates() []*x509.Certificate {
+	return append([]*x509.Certificate{s.cert}, s.chain...)
+}
+
+// sign returns a DER encoded ContentInfo holding SignedData for content of
+// the given type and SHA-256 digest. eContent is embedded when not nil;
+// otherwise the signature is detached. extraAttrs are signed along with the
+// content. When tsa is set, the signature value is timestamped by it.
+func (s *cmsSigner) sign(contentType asn1.ObjectIdentifier, digest, eContent []byte, extraAttrs [][]byte, tsa *cmsSigner) ([]byte, error) {
+	essCertID := derSequence(
+		derOctetString(sha256Sum(s.cert.Raw)),
+		issuerSerial(s.cert),
+	)
+	attrs := [][]byte{
+		derAttribute(oidContentType, derOID(contentType)),
+		derAttribute(oidMessageDigest, derOctetString(digest)),
+		derAttribute(oidSigningCertificateV2, derSequence(derSequence(essCertID))),
+	}
+	attrs = append(attrs, extraAttrs...)
+	// DER orders the elements of a SET OF by their encoding
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+
+	// The signature covers the attributes encoded as a SET OF
+	signature, err := s.key.Sign(rand.Reader, sha256Sum(derTLV(0x31, attrs...)), crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	sigAlg := derSequence(derOID(oidRSAEncryption), derNull())
+	if _, ok := s.key.(*ecdsa.PrivateKey); ok {
+		sigAlg = derSequence(derOID(oidECDSAWithSHA256))
+	}
+
+	signerInfo := [][]byte{
+		derInt(big.NewInt(1)),
+		derSequence(s.cert.RawIssuer, derInt(s.cert.SerialNumber)),
+		derSequence(derOID(oidSHA256)),
+		derTLV(0xa0, attrs...),
+		sigAlg,
+		derOctetString(signature),
+	}
+	if tsa != nil {
+		token, err := tsa.timestampToken(signature)
+		if err != nil {
+			return nil, err
+		}
+		signerInfo = append(signerInfo, derTLV(0xa1, derAttribute(oidTimeStampToken, token)))
+	}
+
+	encap := [][]byte{derOID(contentType)}
+	if eContent != nil {
+		encap = append(encap, derTLV(0xa0, derOctetString(eContent)))
+	}
+	version := int64(1)
+	if !contentType.Equal(oidData) {
+		version = 3
+	}
+	var certs [][]byte
+	for _, c := range s.certificates() {
+		certs = append(certs, c.Raw)
+	}
+
+	signedData := derSequence(
+		derInt(big.NewInt(version)),
+		derTLV(0x31, derSequence(derOID(oidSHA256))),
+		derSequence(encap...),
+		derTLV(0xa0, certs...),
+		derTLV(0x31, derSequence(signerInfo...)),
+	)
+	return derSequence(derOID(oidSignedData), derTLV(0xa0, signedData)), nil
+}
+
+// timestampToken issues an RFC 3161 TimeStampToken for data, acting as a
+// time-stamping authority
+func (s *cmsSigner) timestampToken(data []byte) ([]byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 63))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	genTime, err := asn1.MarshalWithParams(now, "generalized")
+	if err != nil {
+		return nil, err
+	}
+	signingTime, err := asn1.Marshal(now)
+	if err != nil {
+		return nil, err
+	}
+	tstInfo := derSequence(
+		derInt(big.NewInt(1)),
+		derOID(oidAnyPolicy),
+		derSequence(derSequence(derOID(oidSHA256)), derOctetString(sha256Sum(data))),
+		derInt(serial),
+		genTime,
+	)
+	// Verifiers commonly read the time from the signing time attribute
+	return s.sign(oidTSTInfo, sha256Sum(tstInfo), tstInfo, [][]byte{derAttribute(oidSigningTime, signingTime)}, nil)
+}
+
+// issuerSerial encodes the IssuerSerial of an ESS certificate ID
+func issuerSerial(cert *x509.Certificate) []byte {
+	// GeneralNames holding the issuer as a directoryName
+	names := derSequence(derTLV(0xa4, cert.RawIssuer))
+	return derSequence(names, derInt(cert.SerialNumber))
+}
+
+func sha256Sum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+// derTLV encodes a DER element from its tag and the encodings it contains
+func derTLV(tag byte, contents ...[]byte) []byte {
+	n := 0
+	for _, c := range contents {
+		n += len(c)
+	}
+	out := []byte{tag}
+	if n < 0x80 {
+		out = append(out, byte(n))
+	} else {
+		var length []byte
+		for v := n; v > 0; v >>= 8 {
+			length = append([]byte{byte(v)}, length...)
+		}
+		out = append(out, 0x80|byte(len(length)))
+		out = append(out, length...)
+	}
+	for _, c := range contents {
+		out = append(out, c...)
+	}
+	return out
+}
+
+func derSequence(elems ...[]byte) []byte { return derTLV(0x30, elems...) }
+
+func derOctetString(b []byte) []byte { return derTLV(0x04, b) }
+
+func derNull() []byte { return []byte{0x05, 0x00} }
+
+func derOID(oid asn1.ObjectIdentifier) []byte {
+	b, _ := asn1.Marshal(oid)
+	return b
+}
+
+func derInt(i *big.Int) []byte {
+	b, _ := asn1.Marshal(i)
+	return b
+}
+
+// derAttribute encodes a CMS attribute with a single value
+func derAttribute(oid asn1.ObjectIdentifier, value []byte) []byte {
+	return derSequence(derOID(oid), derTLV(0x31, value))
+}
@@ -0,0 +1,850 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+var errUnsupportedImage = errors.New("unsupported image filter")
+
+// neutralTolerance is how far colour components may differ, on a 0-1 scale,
+// for a colour to still count as gray
+const neutralTolerance = 0.02
+
+// colorSpace is a colour space resolved from its name or array. ICC based
+// and calibrated spaces are treated as the device space with the same
+// number of components.
+type colorSpace struct {
+	family string      // DeviceGray, DeviceRGB, DeviceCMYK, Lab, Indexed, Separation, DeviceN or Pattern
+	n      int         // components of a colour value
+	base   *colorSpace // Indexed base, Separation or DeviceN alternate, Pattern underlying space
+	lookup []byte      // Indexed colour table
+	names  []string    // Separation or DeviceN colorants
+	tint   *pdfFunction
+}
+
+var (
+	deviceGray = &colorSpace{family: "DeviceGray", n: 1}
+	deviceRGB  = &colorSpace{family: "DeviceRGB", n: 3}
+	deviceCMYK = &colorSpace{family: "DeviceCMYK", n: 4}
+)
+
+// resolveColorSpace resolves a colour space. Names other than the device
+// families, including the abbreviations of inline images, are looked up in
+// the resources. It returns nil for colour spaces it does not know.
+func resolveColorSpace(xRefTable *model.XRefTable, o types.Object, res types.Dict, depth int) *colorSpace {
+	if o == nil || depth > 8 {
+		return nil
+	}
+	if name, ok := o.(types.Name); ok {
+		switch name {
+		case "DeviceGray", "G", "CalGray":
+			return deviceGray
+		case "DeviceRGB", "RGB", "CalRGB":
+			return deviceRGB
+		case "DeviceCMYK", "CMYK":
+			return deviceCMYK
+		case "Pattern":
+			return &colorSpace{family: "Pattern"}
+		}
+		if res == nil {
+			return nil
+		}
+		spaces, err := xRefTable.DereferenceDict(res["ColorSpace"])
+		if err != nil || spaces == nil || spaces[string(name)] == nil {
+			return nil
+		}
+		return resolveColorSpace(xRefTable, spaces[string(name)], nil, depth+1)
+	}
+
+	obj, err := xRefTable.Dereference(o)
+	if err != nil {
+		return nil
+	}
+	if name, ok := obj.(types.Name); ok {
+		return resolveColorSpace(xRefTable, name, nil, depth+1)
+	}
+	arr, ok := obj.(types.Array)
+	if !ok || len(arr) == 0 {
+		return nil
+	}
+	family, _ := arr[0].(types.Name)
+	switch family {
+	case "CalGray", "CalRGB", "CalCMYK":
+		return resolveColorSpace(xRefTable, family, nil, depth+1)
+	case "Lab":
+		return &colorSpace{family: "Lab", n: 3}
+	case "ICCBased":
+		if len(arr) < 2 {
+			return nil
+		}
+		sd, _, err := xRefTable.DereferenceStreamDict(arr[1])
+		if err != nil || sd == nil {
+			return nil
+		}
+		if n := sd.IntEntry("N"); n != nil {
+			switch *n {
+			case 1:
+				return deviceGray
+			case 3:
+				return deviceRGB
+			case 4:
+				return deviceCMYK
+			}
+		}
+		return resolveColorSpace(xRefTable, sd.Dict["Alternate"], nil, depth+1)
+	case "Indexed", "I":
+		if len(arr) < 4 {
+			return nil
+		}
+		base := resolveColorSpace(xRefTable, arr[1], res, depth+1)
+		if base == nil {
+			return nil
+		}
+		cs := &colorSpace{family: "Indexed", n: 1, base: base}
+		lookup, err := xRefTable.Dereference(arr[3])
+		if err != nil {
+			return nil
+		}
+		switch l := lookup.(type) {
+		case types.StringLiteral, types.HexLiteral:
+			cs.lookup = stringBytes(l)
+		case types.StreamDict:
+			if err := l.Decode(); err != nil {
+				return nil
+			}
+			cs.lookup = l.Content
+		}
+		return cs
+	case "Separation", "DeviceN":
+		if len(arr) < 4 {
+			return nil
+		}
+		cs := &colorSpace{family: string(family), n: 1}
+		if family == "Separation" {
+			if name, ok := arr[1].(types.Name); ok {
+				cs.names = []string{string(name)}
+			}
+		} else if names, err := xRefTable.DereferenceArray(arr[1]); err == nil {
+			for _, o := range names {
+				if name, ok := o.(types.Name); ok {
+					cs.names = append(cs.names, string(name))
+				}
+			}
+			cs.n = len(names)
+		}
+		cs.base = resolveColorSpace(xRefTable, arr[2], res, depth+1)
+		cs.tint = loadFunction(xRefTable, arr[3], 0)
+		return cs
+	case "Pattern":
+		cs := &colorSpace{family: "Pattern"}
+		if len(arr) > 1 {
+			cs.base = resolveColorSpace(xRefTable, arr[1], res, depth+1)
+		}
+		return cs
+	}
+	return nil
+}
+
+// isGray reports whether every colour of the space is gray
+func (cs *colorSpace) isGray() bool {
+	switch cs.family {
+	case "DeviceGray":
+		return true
+	case "Separation", "DeviceN":
+		return cs.blackInks()
+	}
+	return false
+}
+
+// blackInks reports whether all colorants of a Separation or DeviceN space
+// print black
+func (cs *colorSpace) blackInks() bool {
+	if len(cs.names) == 0 {
+		return false
+	}
+	for _, name := range cs.names {
+		if name != "Black" && name != "All" && name != "None" {
+			return false
+		}
+	}
+	return true
+}
+
+// gray converts a colour value to a gray level, 0 black and 1 white, and
+// reports whether the colour is neutral
+func (cs *colorSpace) gray(c []float64) (float64, bool) {
+	if len(c) < cs.n || cs.n == 0 {
+		return 0, true
+	}
+	switch cs.family {
+	case "DeviceGray":
+		return c[0], true
+	case "DeviceRGB":
+		g := 0.3*c[0] + 0.59*c[1] + 0.11*c[2]
+		spread := math.Max(c[0], math.Max(c[1], c[2])) - math.Min(c[0], math.Min(c[1], c[2]))
+		return g, spread <= neutralTolerance
+	case "DeviceCMYK":
+		g := 1 - math.Min(1, 0.3*c[0]+0.59*c[1]+0.11*c[2]+c[3])
+		return g, math.Max(c[0], math.Max(c[1], c[2])) <= neutralTolerance
+	case "Lab":
+		return c[0] / 100, math.Abs(c[1]) <= 2 && math.Abs(c[2]) <= 2
+	case "Indexed":
+		n := cs.base.n
+		i := int(c[0])
+		if i < 0 || (i+1)*n > len(cs.lookup) {
+			return 0, true
+		}
+		entry := make([]float64, n)
+		for j := range entry {
+			entry[j] = float64(cs.lookup[i*n+j]) / 255
+			if cs.base.family == "Lab" {
+				// Lab tables hold L from 0 to 100 and a, b from -100 to 100
+				entry[j] = entry[j] * 100
+				if j > 0 {
+					entry[j] = entry[j]*2 - 100
+				}
+			}
+		}
+		return cs.base.gray(entry)
+	case "Separation", "DeviceN":
+		ink := 0.0
+		for _, t := range c[:cs.n] {
+			ink += t
+		}
+		if cs.blackInks() {
+			return 1 - math.Min(1, ink), true
+		}
+		if cs.tint != nil && cs.base != nil {
+			return cs.base.gray(cs.tint.eval(c[:cs.n]))
+		}
+		return 1 - math.Min(1, ink), false
+	}
+	return 0, true
+}
+
+// pdfFunction evaluates sampled (type 0), exponential (type 2) and
+// stitching (type 3) functions, and arrays of single-output functions
+type pdfFunction struct {
+	kind   int
+	domain []float64
+	rng    []float64
+	// type 0
+	size    []int
+	bps     int
+	samples []byte
+	decode  []float64
+	// type 0 and 3
+	encode []float64
+	// type 2
+	c0, c1 []float64
+	exp    float64
+	// type 3 subfunctions, or the functions of an array
+	funcs  []*pdfFunction
+	bounds []float64
+}
+
+// loadFunction reads a function or an array of functions. It returns nil
+// for PostScript calculator functions and functions it cannot read.
+func loadFunction(xRefTable *model.XRefTable, o types.Object, depth int) *pdfFunction {
+	if depth > 8 {
+		return nil
+	}
+	obj, err := xRefTable.Dereference(o)
+	if err != nil || obj == nil {
+		return nil
+	}
+	if arr, ok := obj.(types.Array); ok {
+		f := &pdfFunction{kind: -1}
+		for _, o := range arr {
+			sub := loadFunction(xRefTable, o, depth+1)
+			if sub == nil {
+				return nil
+			}
+			f.funcs = append(f.funcs, sub)
+		}
+		if len(f.funcs) == 0 {
+			return nil
+		}
+		f.domain = f.funcs[0].domain
+		return f
+	}
+
+	var d types.Dict
+	var sd types.StreamDict
+	switch v := obj.(type) {
+	case types.Dict:
+		d = v
+	case types.StreamDict:
+		sd, d = v, v.Dict
+	default:
+		return nil
+	}
+	floats := func(key string) []float64 {
+		arr, err := xRefTable.DereferenceArray(d[key])
+		if err != nil {
+			return nil
+		}
+		return numbers(arr)
+	}
+	f := &pdfFunction{domain: floats("Domain"), rng: floats("Range")}
+	if len(f.domain) < 2 {
+		return nil
+	}
+	if t := d.IntEntry("FunctionType"); t != nil {
+		f.kind = *t
+	}
+	switch f.kind {
+	case 0:
+		for _, s := range floats("Size") {
+			f.size = append(f.size, int(s))
+		}
+		if bps := d.IntEntry("BitsPerSample"); bps != nil {
+			f.bps = *bps
+		}
+		if len(f.size) != len(f.domain)/2 || len(f.rng) < 2 || f.bps < 1 || f.bps > 32 {
+			return nil
+		}
+		f.encode, f.decode = floats("Encode"), floats("Decode")
+		if f.encode == nil {
+			for _, s := range f.size {
+				f.encode = append(f.encode, 0, float64(s-1))
+			}
+		}
+		if f.decode == nil {
+			f.decode = f.rng
+		}
+		if err := sd.Decode(); err != nil {
+			return nil
+		}
+		f.samples = sd.Content
+	case 2:
+		f.c0, f.c1 = floats("C0"), floats("C1")
+		if f.c0 == nil {
+			f.c0 = []float64{0}
+		}
+		if f.c1 == nil {
+			f.c1 = []float64{1}
+		}
+		if n, err := xRefTable.Dereference(d["N"]); err == nil && n != nil {
+			f.exp = number(n)
+		}
+	case 3:
+		arr, err := xRefTable.DereferenceArray(d["Functions"])
+		if err != nil || len(arr) == 0 {
+			return nil
+		}
+		for _, o := range arr {
+			sub := loadFunction(xRefTable, o, depth+1)
+			if sub == nil {
+				return nil
+			}
+			f.funcs = append(f.funcs, sub)
+		}
+		f.bounds, f.encode = floats("Bounds"), floats("Encode")
+		if len(f.bounds) != len(f.funcs)-1 || len(f.encode) != 2*len(f.funcs) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return f
+}
+
+// eval returns the outputs of the function for inputs in
+func (f *pdfFunction) eval(in []float64) []float64 {
+	if f.kind == -1 {
+		var out []float64
+		for _, sub := range f.funcs {
+			out = append(out, sub.eval(in)...)
+		}
+		return out
+	}
+
+	x := make([]float64, len(f.domain)/2)
+	for i := range x {
+		if i < len(in) {
+			x[i] = in[i]
+		}
+		x[i] = math.Max(f.domain[2*i], math.Min(f.domain[2*i+1], x[i]))
+	}
+
+	var out []float64
+	switch f.kind {
+	case 0:
+		out = f.sampled(x)
+	case 2:
+		t := math.Pow(x[0], f.exp)
+		out = make([]float64, len(f.c0))
+		for j := range out {
+			c1 := 0.0
+			if j < len(f.c1) {
+				c1 = f.c1[j]
+			}
+			out[j] = f.c0[j] + t*(c1-f.c0[j])
+		}
+	case 3:
+		k := 0
+		for k < len(f.bounds) && x[0] >= f.bounds[k] {
+			k++
+		}
+		lo, hi := f.domain[0], f.domain[1]
+		if k > 0 {
+			lo = f.bounds[k-1]
+		}
+		if k < len(f.bounds) {
+			hi = f.bounds[k]
+		}
+		out = f.funcs[k].eval([]float64{interpolate(x[0], lo, hi, f.encode[2*k], f.encode[2*k+1])})
+	}
+
+	for j := range out {
+		if 2*j+1 < len(f.rng) {
+			out[j] = math.Max(f.rng[2*j], math.Min(f.rng[2*j+1], out[j]))
+		}
+	}
+	return out
+}
+
+// sampled looks up a type 0 function, interpolating linearly between the
+// samples of single-input functions and taking the nearest sample otherwise
+func (f *pdfFunction) sampled(x []float64) []float64 {
+	outputs := len(f.rng) / 2
+	sample := func(index []int) []float64 {
+		pos := 0
+		stride := 1
+		for i, e := range index {
+			pos += e * stride
+			stride *= f.size[i]
+		}
+		out := make([]float64, outputs)
+		max := math.Pow(2, float64(f.bps)) - 1
+		for j := range out {
+			bit := (pos*outputs + j) * f.bps
+			v := 0
+			for b := 0; b < f.bps; b++ {
+				at := (bit + b) / 8
+				if at >= len(f.samples) {
+					break
+				}
+				v = v<<1 | int(f.samples[at]>>(7-uint((bit+b)%8)))&1
+			}
+			out[j] = interpolate(float64(v), 0, max, f.decode[2*j], f.decode[2*j+1])
+		}
+		return out
+	}
+
+	e := make([]float64, len(x))
+	for i := range x {
+		e[i] = interpolate(x[i], f.domain[2*i], f.domain[2*i+1], f.encode[2*i], f.encode[2*i+1])
+		e[i] = math.Max(0, math.Min(float64(f.size[i]-1), e[i]))
+	}
+	if len(x) == 1 {
+		i0 := int(math.Floor(e[0]))
+		i1 := min(i0+1, f.size[0]-1)
+		a, b := sample([]int{i0}), sample([]int{i1})
+		t := e[0] - float64(i0)
+		for j := range a {
+			a[j] += t * (b[j] - a[j])
+		}
+		return a
+	}
+	index := make([]int, len(e))
+	for i := range e {
+		index[i] = int(math.Round(e[i]))
+	}
+	return sample(index)
+}
+
+// interpolate maps x from [x0, x1] to [y0, y1]
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 == x0 {
+		return y0
+	}
+	return y0 + (x-x0)*(y1-y0)/(x1-x0)
+}
+
+// colorAnalyzer finds the pages of a document that print in colour.
+// Colours count as gray when their components are nearly equal.
+type colorAnalyzer struct {
+	ctx      *model.Context
+	images   map[int]bool // image objects by whether they hold colour
+	shadings map[int]bool
+}
+
+func newColorAnalyzer(ctx *model.Context) *colorAnalyzer {
+	return &colorAnalyzer{ctx: ctx, images: map[int]bool{}, shadings: map[int]bool{}}
+}
+
+// pageHasColor reports whether a page paints anything in colour, including
+// its forms, patterns and annotation appearances
+func (a *colorAnalyzer) pageHasColor(page int) (bool, error) {
+	found := false
+	err := walkPageResources(a.ctx, page, func(content []byte, res types.Dict, _ *types.IndirectRef) {
+		if !found && content != nil {
+			found = a.contentHasColor(content, res)
+		}
+	})
+	return found, err
+}
+
+// colorPages returns the pages of a document that print in colour
+func colorPages(ctx *model.Context) ([]int, error) {
+	a := newColorAnalyzer(ctx)
+	var pages []int
+	for page := 1; page <= ctx.PageCount; page++ {
+		found, err := a.pageHasColor(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+		if found {
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// colorState is the colour space of the fill and stroke colours
+type colorState struct {
+	fill, stroke *colorSpace
+}
+
+// contentHasColor reports whether a content stream paints in colour
+func (a *colorAnalyzer) contentHasColor(content []byte, res types.Dict) bool {
+	ops, err := parseContent(content)
+	if err != nil {
+		return false
+	}
+	state := colorState{deviceGray, deviceGray}
+	var stack []colorState
+	for _, op := range ops {
+		switch op.Operator {
+		case "q":
+			stack = append(stack, state)
+		case "Q":
+			if len(stack) > 0 {
+				state, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case "rg", "RG":
+			if _, neutral := deviceRGB.gray(numbers(op.Operands)); !neutral {
+				return true
+			}
+		case "k", "K":
+			if _, neutral := deviceCMYK.gray(numbers(op.Operands)); !neutral {
+				return true
+			}
+		case "cs", "CS":
+			if len(op.Operands) != 1 {
+				continue
+			}
+			cs := resolveColorSpace(a.ctx.XRefTable, op.Operands[0], res, 0)
+			if op.Operator == "cs" {
+				state.fill = cs
+			} else {
+				state.stroke = cs
+			}
+		case "sc", "scn", "SC", "SCN":
+			cs := state.fill
+			if op.Operator == "SC" || op.Operator == "SCN" {
+				cs = state.stroke
+			}
+			if a.colorHasColor(cs, op.Operands, res) {
+				return true
+			}
+		case "sh":
+			if len(op.Operands) == 1 {
+				if name, ok := op.Operands[0].(types.Name); ok {
+					shadings, _ := a.ctx.DereferenceDict(res["Shading"])
+					if shadings != nil && a.shadingHasColor(shadings[string(name)], res) {
+						return true
+					}
+				}
+			}
+		case "Do":
+			if len(op.Operands) == 1 {
+				if name, ok := op.Operands[0].(types.Name); ok && a.xObjectHasColor(string(name), res) {
+					return true
+				}
+			}
+		case "BI":
+			if a.inlineImageHasColor(op, res) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// colorHasColor reports whether the operands of sc or scn select a colour
+// in cs. Patterns are looked up in the resources.
+func (a *colorAnalyzer) colorHasColor(cs *colorSpace, operands []types.Object, res types.Dict) bool {
+	if len(operands) > 0 {
+		if name, ok := operands[len(operands)-1].(types.Name); ok {
+			patterns, _ := a.ctx.DereferenceDict(res["Pattern"])
+			if patterns == nil {
+				return false
+			}
+			pattern, _ := a.ctx.Dereference(patterns[string(name)])
+			// Tiling patterns are checked as content streams of their own
+			if d, ok := pattern.(types.Dict); ok && a.shadingHasColor(d["Shading"], res) {
+				return true
+			}
+			if cs != nil && cs.base != nil && len(operands) > 1 {
+				_, neutral := cs.base.gray(numbers(operands[:len(operands)-1]))
+				return !neutral
+			}
+			return false
+		}
+	}
+	c := numbers(operands)
+	if cs == nil || cs.n != len(c) {
+		// Guess the space from the number of components
+		switch len(c) {
+		case 3:
+			cs = deviceRGB
+		case 4:
+			cs = deviceCMYK
+		default:
+			return false
+		}
+	}
+	_, neutral := cs.gray(c)
+	return !neutral
+}
+
+// xObjectHasColor reports whether the named image XObject holds colour.
+// Forms are checked as content streams of their own.
+func (a *colorAnalyzer) xObjectHasColor(name string, res types.Dict) bool {
+	xobjs, _ := a.ctx.DereferenceDict(res["XObject"])
+	if xobjs == nil {
+		return false
+	}
+	ref, ok := xobjs[name].(types.IndirectRef)
+	if !ok {
+		return false
+	}
+	nr := ref.ObjectNumber.Value()
+	if found, ok := a.images[nr]; ok {
+		return found
+	}
+	sd, _, err := a.ctx.DereferenceStreamDict(ref)
+	found := false
+	if err == nil && sd != nil {
+		if st := sd.Subtype(); st != nil && *st == "Image" {
+			found = imageHasColor(a.ctx, sd, nr)
+		}
+	}
+	a.images[nr] = found
+	return found
+}
+
+// imageHasColor reports whether an image holds pixels that are not gray.
+// Images that cannot be decoded count as colour unless their colour space
+// is gray.
+func imageHasColor(ctx *model.Context, sd *types.StreamDict, objNr int) bool {
+	if mask := sd.BooleanEntry("ImageMask"); mask != nil && *mask {
+		return false
+	}
+	cs := resolveColorSpace(ctx.XRefTable, sd.Dict["ColorSpace"], nil, 0)
+	if cs != nil && cs.isGray() {
+		return false
+	}
+	img, err := decodeImage(ctx, sd, objNr)
+	if err != nil {
+		return true
+	}
+	return imageColorful(img)
+}
+
+// decodeImage decodes an image XObject
+func decodeImage(ctx *model.Context, sd *types.StreamDict, objNr int) (image.Image, error) {
+	decoded, err := pdfcpu.ExtractImage(ctx, sd, false, "", objNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		return nil, errUnsupportedImage
+	}
+	img, _, err := image.Decode(decoded)
+	return img, err
+}
+
+// imageColorful reports whether any pixel of img is not gray
+func imageColorful(img image.Image) bool {
+	b := img.Bounds()
+	switch m := img.(type) {
+	case *image.Gray, *image.Gray16:
+		return false
+	case *image.YCbCr:
+		tol := int(math.Round(neutralTolerance * 255))
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				i := m.COffset(x, y)
+				if abs(int(m.Cb[i])-128) > tol || abs(int(m.Cr[i])-128) > tol {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	rgba := toRGBA(img)
+	tol := uint8(math.Round(neutralTolerance * 255))
+	for i := 0; i+3 < len(rgba.Pix); i += 4 {
+		r, g, bl := rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2]
+		hi, lo := max(r, g, bl), min(r, g, bl)
+		if hi-lo > tol {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineImageHasColor reports whether an inline image holds colour. Only
+// unfiltered images are decoded; others count as colour unless their
+// colour space is gray.
+func (a *colorAnalyzer) inlineImageHasColor(op contentOp, res types.Dict) bool {
+	d, _ := op.Operands[0].(types.Dict)
+	if d == nil || d["IM"] == types.Boolean(true) || d["ImageMask"] == types.Boolean(true) {
+		return false
+	}
+	cs := resolveColorSpace(a.ctx.XRefTable, inlineEntry(d, "CS", "ColorSpace"), res, 0)
+	if cs == nil || cs.isGray() {
+		return false
+	}
+	samples, ok := inlineSamples(d, op.Inline, cs)
+	if !ok {
+		return true
+	}
+	for _, c := range samples {
+		if _, neutral := cs.gray(c); !neutral {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineEntry returns the entry of an inline image dictionary under its
+// abbreviated or full key
+func inlineEntry(d types.Dict, short, long string) types.Object {
+	if o, ok := d[short]; ok {
+		return o
+	}
+	return d[long]
+}
+
+// inlineSamples returns the colour values of an unfiltered inline image
+// with 8 bits per component
+func inlineSamples(d types.Dict, data []byte, cs *colorSpace) ([][]float64, bool) {
+	if inlineEntry(d, "F", "Filter") != nil || inlineEntry(d, "D", "Decode") != nil {
+		return nil, false
+	}
+	bpc, _ := inlineEntry(d, "BPC", "BitsPerComponent").(types.Integer)
+	w, _ := inlineEntry(d, "W", "Width").(types.Integer)
+	h, _ := inlineEntry(d, "H", "Height").(types.Integer)
+	n := cs.n
+	if bpc != 8 || w <= 0 || h <= 0 || len(data) < int(w)*int(h)*n {
+		return nil, false
+	}
+	samples := make([][]float64, 0, int(w)*int(h))
+	for i := 0; i+n <= int(w)*int(h)*n; i += n {
+		c := make([]float64, n)
+		for j := range c {
+			c[j] = float64(data[i+j]) / 255
+			if cs.family == "Indexed" {
+				c[j] = float64(data[i+j])
+			}
+		}
+		samples = append(samples, c)
+	}
+	return samples, true
+}
+
+// shadingHasColor reports whether a shading paints colour, sampling its
+// function. Shadings without a readable function count as colour unless
+// their colour space is gray.
+func (a *colorAnalyzer) shadingHasColor(o types.Object, res types.Dict) bool {
+	ref, isRef := o.(types.IndirectRef)
+	if isRef {
+		if found, ok := a.shadings[ref.ObjectNumber.Value()]; ok {
+			return found
+		}
+	}
+	found := shadingColorful(a.ctx.XRefTable, o, res)
+	if isRef {
+		a.shadings[ref.ObjectNumber.Value()] = found
+	}
+	return found
+}
+
+func shadingColorful(xRefTable *model.XRefTable, o types.Object, res types.Dict) bool {
+	sh := shadingDict(xRefTable, o)
+	if sh == nil {
+		return false
+	}
+	cs := resolveColorSpace(xRefTable, sh["ColorSpace"], res, 0)
+	if cs == nil {
+		return false
+	}
+	if cs.isGray() {
+		return false
+	}
+	f := loadFunction(xRefTable, sh["Function"], 0)
+	if f == nil {
+		return true
+	}
+	for _, in := range functionSamples(f, 17) {
+		if _, neutral := cs.gray(f.eval(in)); !neutral {
+			return true
+		}
+	}
+	return false
+}
+
+// shadingDict returns the dictionary of a shading, which may be a stream
+func shadingDict(xRefTable *model.XRefTable, o types.Object) types.Dict {
+	obj, err := xRefTable.Dereference(o)
+	if err != nil {
+		return nil
+	}
+	switch sh := obj.(type) {
+	case types.Dict:
+		return sh
+	case types.StreamDict:
+		return sh.Dict
+	}
+	return nil
+}
+
+// functionSamples returns inputs spread evenly over the domain of f, n per
+// input dimension for up to two inputs
+func functionSamples(f *pdfFunction, n int) [][]float64 {
+	step := func(i, k int) float64 {
+		return f.domain[2*i] + float64(k)*(f.domain[2*i+1]-f.domain[2*i])/float64(n-1)
+	}
+	var inputs [][]float64
+	if len(f.domain) >= 4 {
+		for k := 0; k < n; k++ {
+			for l := 0; l < n; l++ {
+				inputs = append(inputs, []float64{step(0, k), step(1, l)})
+			}
+		}
+		return inputs
+	}
+	for k := 0; k < n; k++ {
+		inputs = append(inputs, []float64{step(0, k)})
+	}
+	return inputs
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,143 @@
+package pdf
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// ColorPages returns the number of pages of a PDF and the pages that print
+// in colour; all other pages are mono
+func (s *Service) ColorPages(filePath string) (int, []int, error) {
+	if !utils.IsPDF(filePath) {
+		return 0, nil, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(filePath)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	pages, err := colorPages(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	return ctx.PageCount, pages, nil
+}
+
+// SplitByColor writes the colour pages and the mono pages of a PDF to
+// <name>_color.pdf and <name>_mono.pdf in outputDir, with a CSV page map in
+// <name>_pagemap.csv telling where each original page went. With duplex
+// set, pages are classified by sheet: both sides of a sheet with a colour
+// side go to the colour set, so each set prints on whole sheets.
+func (s *Service) SplitByColor(inputFile, outputDir string, duplex bool) (*models.ColorSplit, error) {
+	pageCount, colored, err := s.ColorPages(inputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.EnsureDir(outputDir); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	isColor := make([]bool, pageCount+1)
+	for _, page := range colored {
+		isColor[page] = true
+		if duplex {
+			// Mark the other side of the sheet
+			other := page + 1
+			if page%2 == 0 {
+				other = page - 1
+			}
+			if other <= pageCount {
+				isColor[other] = true
+			}
+		}
+	}
+
+	var colorSet, monoSet []int
+	for page := 1; page <= pageCount; page++ {
+		if isColor[page] {
+			colorSet = append(colorSet, page)
+		} else {
+			monoSet = append(monoSet, page)
+		}
+	}
+
+	baseName := filepath.Base(inputFile)
+	baseName = baseName[:len(baseName)-len(filepath.Ext(baseName))]
+	split := &models.ColorSplit{
+		Pages:      pageCount,
+		ColorPages: colored,
+		MapFile:    filepath.Join(outputDir, baseName+"_pagemap.csv"),
+		Map:        make([]models.ColorPageMapping, pageCount),
+	}
+
+	if len(colorSet) > 0 {
+		split.ColorFile = filepath.Join(outputDir, baseName+"_color.pdf")
+		if err := api.TrimFile(inputFile, split.ColorFile, pageSelectors(colorSet), nil); err != nil {
+			return nil, fmt.Errorf("failed to write colour pages: %w", err)
+		}
+	}
+	if len(monoSet) > 0 {
+		split.MonoFile = filepath.Join(outputDir, baseName+"_mono.pdf")
+		if err := api.TrimFile(inputFile, split.MonoFile, pageSelectors(monoSet), nil); err != nil {
+			return nil, fmt.Errorf("failed to write mono pages: %w", err)
+		}
+	}
+
+	for i, page := range colorSet {
+		split.Map[page-1] = models.ColorPageMapping{Page: page, Color: true, File: filepath.Base(split.ColorFile), FilePage: i + 1}
+	}
+	for i, page := range monoSet {
+		split.Map[page-1] = models.ColorPageMapping{Page: page, File: filepath.Base(split.MonoFile), FilePage: i + 1}
+	}
+
+	if err := writePageMapCSV(split.Map, split.MapFile, duplex); err != nil {
+		return nil, fmt.Errorf("failed to write page map: %w", err)
+	}
+	return split, nil
+}
+
+// writePageMapCSV writes one row per original page, in document order, so
+// the printed stacks can be collated by reading it top to bottom
+func writePageMapCSV(pages []models.ColorPageMapping, outFile string, duplex bool) error {
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := []string{"page", "set", "file", "file_page"}
+	if duplex {
+		header = append(header, "sheet", "side")
+	}
+	_ = w.Write(header)
+	for _, p := range pages {
+		set := "mono"
+		if p.Color {
+			set = "color"
+		}
+		record := []string{strconv.Itoa(p.Page), set, p.File, strconv.Itoa(p.FilePage)}
+		if duplex {
+			side := "front"
+			if p.FilePage%2 == 0 {
+				side = "back"
+			}
+			record = append(record, strconv.Itoa((p.FilePage+1)/2), side)
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,356 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// contentOp is one operator of a page content stream together with its operands
+type contentOp struct {
+	Operator string
+	Operands []types.Object
+	// Inline holds the data of an inline image; Operands[0] is its dictionary
+	Inline []byte
+}
+
+// parseContent splits a content stream into operators. Operands are parsed
+// into pdfcpu objects so they can be inspected, changed and written back.
+func parseContent(data []byte) ([]contentOp, error) {
+	l := &contentLexer{data: data}
+	var ops []contentOp
+	var operands []types.Object
+	for {
+		l.skipSpace()
+		if l.eof() {
+			break
+		}
+		obj, op, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if op == "" {
+			operands = append(operands, obj)
+			continue
+		}
+		if op == "BI" {
+			dict, inline, err := l.inlineImage()
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, contentOp{Operator: op, Operands: []types.Object{dict}, Inline: inline})
+		} else {
+			ops = append(ops, contentOp{Operator: op, Operands: operands})
+		}
+		operands = nil
+	}
+	return ops, nil
+}
+
+// writeContent serializes ops back into a content stream
+func writeContent(ops []contentOp) []byte {
+	var buf bytes.Buffer
+	for _, op := range ops {
+		if op.Operator == "BI" {
+			buf.WriteString("BI")
+			if d, ok := op.Operands[0].(types.Dict); ok {
+				for _, k := range sortedKeys(d) {
+					buf.WriteString(" /" + types.EncodeName(k) + " ")
+					writeObject(&buf, d[k])
+				}
+			}
+			buf.WriteString(" ID ")
+			buf.Write(op.Inline)
+			buf.WriteString("\nEI\n")
+			continue
+		}
+		for _, o := range op.Operands {
+			writeObject(&buf, o)
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(op.Operator)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func writeObject(buf *bytes.Buffer, o types.Object) {
+	switch o := o.(type) {
+	case nil:
+		buf.WriteString("null")
+	case types.Float:
+		buf.WriteString(formatNumber(float64(o)))
+	case types.Array:
+		buf.WriteByte('[')
+		for i, e := range o {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			writeObject(buf, e)
+		}
+		buf.WriteByte(']')
+	case types.Dict:
+		buf.WriteString("<<")
+		for _, k := range sortedKeys(o) {
+			buf.WriteString("/" + types.EncodeName(k) + " ")
+			writeObject(buf, o[k])
+		}
+		buf.WriteString(">>")
+	default:
+		buf.WriteString(o.PDFString())
+	}
+}
+
+func sortedKeys(d types.Dict) []string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatNumber writes f with at most 4 decimals and no trailing zeros
+func formatNumber(f float64) string {
+	s := strconv.FormatFloat(math.Round(f*10000)/10000, 'f', -1, 64)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// number returns the numeric value of a content stream operand
+func number(o types.Object) float64 {
+	switch o := o.(type) {
+	case types.Integer:
+		return float64(o)
+	case types.Float:
+		return float64(o)
+	}
+	return 0
+}
+
+// numbers returns the numeric values of operands
+func numbers(operands []types.Object) []float64 {
+	f := make([]float64, len(operands))
+	for i, o := range operands {
+		f[i] = number(o)
+	}
+	return f
+}
+
+// stringBytes returns the decoded bytes of a string operand
+func stringBytes(o types.Object) []byte {
+	switch o := o.(type) {
+	case types.StringLiteral:
+		b, err := types.Unescape(string(o))
+		if err != nil {
+			return []byte(o)
+		}
+		return b
+	case types.HexLiteral:
+		b, err := o.Bytes()
+		if err != nil {
+			return nil
+		}
+		return b
+	}
+	return nil
+}
+
+type contentLexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *contentLexer) eof() bool {
+	return l.pos >= len(l.data)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (l *contentLexer) skipSpace() {
+	for !l.eof() {
+		c := l.data[l.pos]
+		if c == '%' {
+			for !l.eof() && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// word reads a run of regular characters
+func (l *contentLexer) word() string {
+	start := l.pos
+	for !l.eof() && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// next returns either an operand or, for a bare keyword, the operator name
+func (l *contentLexer) next() (types.Object, string, error) {
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		name, err := types.DecodeName(l.word())
+		if err != nil {
+			return nil, "", err
+		}
+		return types.Name(name), "", nil
+	case c == '(':
+		s, err := l.literalString()
+		return s, "", err
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		d, err := l.dict(">>")
+		return d, "", err
+	case c == '<':
+		end := bytes.IndexByte(l.data[l.pos:], '>')
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated hex string at offset %d", l.pos)
+		}
+		hex := bytes.Map(func(r rune) rune {
+			if isSpace(byte(r)) {
+				return -1
+			}
+			return r
+		}, l.data[l.pos+1:l.pos+end])
+		l.pos += end + 1
+		return types.HexLiteral(hex), "", nil
+	case c == '[':
+		l.pos++
+		var arr types.Array
+		for {
+			l.skipSpace()
+			if l.eof() {
+				return nil, "", fmt.Errorf("unterminated array")
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, "", nil
+			}
+			obj, op, err := l.next()
+			if err != nil {
+				return nil, "", err
+			}
+			if op != "" {
+				return nil, "", fmt.Errorf("unexpected operator %q in array", op)
+			}
+			arr = append(arr, obj)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return nil, "", fmt.Errorf("unexpected %q at offset %d", c, l.pos-1)
+	}
+
+	w := l.word()
+	switch w {
+	case "true":
+		return types.Boolean(true), "", nil
+	case "false":
+		return types.Boolean(false), "", nil
+	case "null":
+		return nil, "", nil
+	}
+	if c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+		if i, err := strconv.Atoi(w); err == nil {
+			return types.Integer(i), "", nil
+		}
+		if f, err := strconv.ParseFloat(w, 64); err == nil {
+			return types.Float(f), "", nil
+		}
+	}
+	return nil, w, nil
+}
+
+// literalString reads a balanced (...) string, keeping escapes as written
+func (l *contentLexer) literalString() (types.StringLiteral, error) {
+	l.pos++
+	start, depth := l.pos, 1
+	for !l.eof() {
+		switch l.data[l.pos] {
+		case '\\':
+			l.pos++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				s := types.StringLiteral(l.data[start:l.pos])
+				l.pos++
+				return s, nil
+			}
+		}
+		l.pos++
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// dict reads key/value pairs until end, which is ">>" or, for inline images, "ID"
+func (l *contentLexer) dict(end string) (types.Dict, error) {
+	d := types.Dict{}
+	for {
+		l.skipSpace()
+		if l.eof() {
+			return nil, fmt.Errorf("unterminated dictionary")
+		}
+		if bytes.HasPrefix(l.data[l.pos:], []byte(end)) {
+			l.pos += len(end)
+			return d, nil
+		}
+		key, _, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(types.Name)
+		if !ok {
+			return nil, fmt.Errorf("dictionary key must be a name")
+		}
+		l.skipSpace()
+		val, op, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if op != "" {
+			return nil, fmt.Errorf("unexpected operator %q in dictionary", op)
+		}
+		d[string(name)] = val
+	}
+}
+
+// inlineImage reads the dictionary and data of an inline image after BI
+func (l *contentLexer) inlineImage() (types.Dict, []byte, error) {
+	d, err := l.dict("ID")
+	if err != nil {
+		return nil, nil, err
+	}
+	// A single white-space character separates ID from the data
+	l.pos++
+	start := l.pos
+	for i := start; i+1 < len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' && i > start && isSpace(l.data[i-1]) &&
+			(i+2 == len(l.data) || isSpace(l.data[i+2]) || isDelimiter(l.data[i+2])) {
+			l.pos = i + 2
+			return d, l.data[start : i-1], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unterminated inline image")
+}
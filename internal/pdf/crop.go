@@ -0,0 +1,145 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Crop sets the page boxes of the selected pages, either by trimming fixed
+// margins or by shrinking each page to its detected content
+func (s *Service) Crop(config models.CropConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, err := readOptimizedContext(config.InputFile)
+	if err != nil {
+		return err
+	}
+
+	boxes := config.Boxes
+	if len(boxes) == 0 {
+		boxes = []models.PageBox{models.BoxCrop}
+	}
+
+	for _, page := range selectedPages(config.Pages, ctx.PageCount) {
+		d, _, inh, err := ctx.PageDict(page, false)
+		if err != nil {
+			return fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+		visible := inh.MediaBox
+		if inh.CropBox != nil {
+			visible = inh.CropBox
+		}
+
+		var r *types.Rectangle
+		if config.Auto {
+			b, err := pageContentBounds(ctx, page)
+			if err != nil {
+				return fmt.Errorf("failed to analyze page %d: %w", page, err)
+			}
+			if !b.ok {
+				// Blank page, nothing to crop to
+				continue
+			}
+			p := config.Padding
+			r = types.NewRectangle(
+				math.Max(b.x0-p, visible.LL.X), math.Max(b.y0-p, visible.LL.Y),
+				math.Min(b.x1+p, visible.UR.X), math.Min(b.y1+p, visible.UR.Y))
+		} else {
+			m := rotateMargins(config.Margins, inh.Rotate)
+			r = types.NewRectangle(visible.LL.X+m.Left, visible.LL.Y+m.Bottom, visible.UR.X-m.Right, visible.UR.Y-m.Top)
+		}
+		if r.Width() < 1 || r.Height() < 1 {
+			return fmt.Errorf("crop leaves nothing of page %d", page)
+		}
+
+		for _, box := range boxes {
+			d.Update(string(box), r.Array())
+		}
+	}
+
+	return api.WriteContextFile(ctx, config.OutputFile)
+}
+
+// ContentBounds returns the detected content area of a page as margins from
+// the edges of its visible area, as displayed
+func (s *Service) ContentBounds(filePath string, page int) (models.Margins, error) {
+	if !utils.IsPDF(filePath) {
+		return models.Margins{}, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := readOptimizedContext(filePath)
+	if err != nil {
+		return models.Margins{}, err
+	}
+	if page < 1 || page > ctx.PageCount {
+		return models.Margins{}, fmt.Errorf("page %d out of range (1-%d)", page, ctx.PageCount)
+	}
+
+	_, _, inh, err := ctx.PageDict(page, false)
+	if err != nil {
+		return models.Margins{}, fmt.Errorf("failed to read page %d: %w", page, err)
+	}
+	visible := inh.MediaBox
+	if inh.CropBox != nil {
+		visible = inh.CropBox
+	}
+
+	b, err := pageContentBounds(ctx, page)
+	if err != nil {
+		return models.Margins{}, fmt.Errorf("failed to analyze page %d: %w", page, err)
+	}
+	if !b.ok {
+		return models.Margins{}, fmt.Errorf("page %d has no visible content", page)
+	}
+	m := models.Margins{
+		Top:    math.Max(0, visible.UR.Y-b.y1),
+		Right:  math.Max(0, visible.UR.X-b.x1),
+		Bottom: math.Max(0, b.y0-visible.LL.Y),
+		Left:   math.Max(0, b.x0-visible.LL.X),
+	}
+	return rotateMargins(m, 360-inh.Rotate), nil
+}
+
+// readOptimizedContext reads and validates a PDF with its resources indexed,
+// as needed for image extraction
+func readOptimizedContext(filePath string) (*model.Context, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ctx, err := api.ReadValidateAndOptimize(f, model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return ctx, nil
+}
+
+// rotateMargins maps margins as seen on screen to the unrotated page of a
+// page displayed with the given /Rotate value
+func rotateMargins(m models.Margins, rotate int) models.Margins {
+	switch ((rotate % 360) + 360) % 360 {
+	case 90:
+		return models.Margins{Top: m.Right, Right: m.Bottom, Bottom: m.Left, Left: m.Top}
+	case 180:
+		return models.Margins{Top: m.Bottom, Right: m.Left, Bottom: m.Top, Left: m.Right}
+	case 270:
+		return models.Margins{Top: m.Left, Right: m.Top, Bottom: m.Right, Left: m.Bottom}
+	}
+	return m
+}
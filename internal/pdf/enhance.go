@@ -0,0 +1,353 @@
+package pdf
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+
+	"pdf-toolbox/pkg/models"
+)
+
+// enhancementEnabled reports whether any scan enhancement step is selected
+func enhancementEnabled(e models.ScanEnhancement) bool {
+	return e.AutoCrop || e.Deskew || e.Contrast || e.Grayscale || e.Threshold
+}
+
+// EnhanceScan runs the selected pre-processing steps on a photographed
+// document page: auto-crop, contrast normalization, deskew, then grayscale or
+// black-and-white thresholding. img is expected to be upright already.
+func EnhanceScan(img image.Image, opts models.ScanEnhancement) image.Image {
+	rgba := toRGBA(img)
+
+	if opts.AutoCrop {
+		rgba = autoCrop(rgba)
+	}
+	// Flatten lighting before deskewing so the white fill of rotated corners
+	// does not skew the background estimate
+	if opts.Contrast {
+		normalizeContrast(rgba)
+	}
+	if opts.Deskew {
+		if angle := detectSkew(rgba); math.Abs(angle) >= 0.1 {
+			rgba = rotateRGBA(rgba, angle)
+		}
+	}
+
+	if opts.Threshold {
+		return threshold(toGray(rgba))
+	}
+	if opts.Grayscale {
+		return toGray(rgba)
+	}
+	return rgba
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// luma returns the Rec. 601 luminance of the pixel at offset i
+func luma(pix []uint8, i int) uint8 {
+	return uint8((299*int(pix[i]) + 587*int(pix[i+1]) + 114*int(pix[i+2])) / 1000)
+}
+
+func toGray(img *image.RGBA) *image.Gray {
+	b := img.Bounds()
+	gray := image.NewGray(b)
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			gray.Pix[gray.PixOffset(x, y)] = luma(img.Pix, img.PixOffset(x, y))
+		}
+	}
+	return gray
+}
+
+// otsu returns the threshold that best separates the two classes of hist
+func otsu(hist [256]int) uint8 {
+	total, sum := 0, 0
+	for i, n := range hist {
+		total += n
+		sum += i * n
+	}
+	var best float64
+	var level uint8
+	wB, sumB := 0, 0
+	for t := 0; t < 256; t++ {
+		wB += hist[t]
+		if wB == 0 {
+			continue
+		}
+		wF := total - wB
+		if wF == 0 {
+			break
+		}
+		sumB += t * hist[t]
+		mB := float64(sumB) / float64(wB)
+		mF := float64(sum-sumB) / float64(wF)
+		between := float64(wB) * float64(wF) * (mB - mF) * (mB - mF)
+		if between > best {
+			best = between
+			level = uint8(t)
+		}
+	}
+	return level
+}
+
+func lumaHistogram(img *image.RGBA) [256]int {
+	var hist [256]int
+	b := img.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			hist[luma(img.Pix, img.PixOffset(x, y))]++
+		}
+	}
+	return hist
+}
+
+func threshold(gray *image.Gray) *image.Gray {
+	var hist [256]int
+	for _, v := range gray.Pix {
+		hist[v]++
+	}
+	level := otsu(hist)
+	for i, v := range gray.Pix {
+		if v > level {
+			gray.Pix[i] = 255
+		} else {
+			gray.Pix[i] = 0
+		}
+	}
+	return gray
+}
+
+// autoCrop crops to the bright paper area when it stands out from a darker
+// background. The image is returned unchanged if no clear page edge is found.
+func autoCrop(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	level := otsu(lumaHistogram(img))
+
+	rows := make([]int, h)
+	cols := make([]int, w)
+	bright := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if luma(img.Pix, img.PixOffset(x, y)) > level {
+				rows[y]++
+				cols[x]++
+				bright++
+			}
+		}
+	}
+	// Mostly bright means there is no visible background around the page
+	if bright > w*h*95/100 {
+		return img
+	}
+
+	y0, y1 := profileSpan(rows)
+	x0, x1 := profileSpan(cols)
+	if x1-x0 < w/5 || y1-y0 < h/5 {
+		return img
+	}
+	return toRGBA(img.SubImage(image.Rect(x0, y0, x1+1, y1+1)))
+}
+
+// profileSpan returns the first and last index whose count reaches half of the peak
+func profileSpan(profile []int) (int, int) {
+	peak := 0
+	for _, n := range profile {
+		peak = max(peak, n)
+	}
+	first, last := 0, len(profile)-1
+	for first < last && profile[first]*2 < peak {
+		first++
+	}
+	for last > first && profile[last]*2 < peak {
+		last--
+	}
+	return first, last
+}
+
+// detectSkew estimates the text line angle in degrees using projection
+// profiles of dark pixels on a downscaled copy
+func detectSkew(img *image.RGBA) float64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	step := max(1, max(w, h)/800)
+	level := otsu(lumaHistogram(img))
+
+	type point struct{ x, y float64 }
+	var dark []point
+	for y := 0; y < h; y += step {
+		for x := 0; x < w; x += step {
+			if luma(img.Pix, img.PixOffset(x, y)) <= level {
+				dark = append(dark, point{float64(x / step), float64(y / step)})
+			}
+		}
+	}
+	if len(dark) == 0 {
+		return 0
+	}
+
+	score := func(deg float64) float64 {
+		sin, cos := math.Sincos(deg * math.Pi / 180)
+		hist := map[int]int{}
+		for _, p := range dark {
+			hist[int(math.Floor(p.y*cos-p.x*sin))]++
+		}
+		var s float64
+		for _, n := range hist {
+			s += float64(n) * float64(n)
+		}
+		return s
+	}
+
+	search := func(from, to, by float64) float64 {
+		best, bestScore := 0.0, -1.0
+		for a := from; a <= to+1e-9; a += by {
+			if sc := score(a); sc > bestScore {
+				best, bestScore = a, sc
+			}
+		}
+		return best
+	}
+
+	coarse := search(-10, 10, 0.5)
+	return search(coarse-0.5, coarse+0.5, 0.1)
+}
+
+// rotateRGBA rotates img around its center so lines at angle deg become
+// horizontal. Uncovered corners are filled with white.
+func rotateRGBA(img *image.RGBA, deg float64) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	cx, cy := float64(w)/2, float64(h)/2
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			sx := dx*cos - dy*sin + cx
+			sy := dx*sin + dy*cos + cy
+			dst.SetRGBA(x, y, sampleBilinear(img, sx, sy))
+		}
+	}
+	return dst
+}
+
+func sampleBilinear(img *image.RGBA, x, y float64) color.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if x < 0 || y < 0 || x > float64(w-1) || y > float64(h-1) {
+		return color.RGBA{255, 255, 255, 255}
+	}
+	x0, y0 := int(x), int(y)
+	x1, y1 := min(x0+1, w-1), min(y0+1, h-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	var out [4]uint8
+	for c := 0; c < 4; c++ {
+		p00 := float64(img.Pix[img.PixOffset(x0, y0)+c])
+		p10 := float64(img.Pix[img.PixOffset(x1, y0)+c])
+		p01 := float64(img.Pix[img.PixOffset(x0, y1)+c])
+		p11 := float64(img.Pix[img.PixOffset(x1, y1)+c])
+		top := p00 + (p10-p00)*fx
+		bottom := p01 + (p11-p01)*fx
+		out[c] = uint8(top + (bottom-top)*fy + 0.5)
+	}
+	return color.RGBA{out[0], out[1], out[2], out[3]}
+}
+
+// normalizeContrast removes uneven lighting by dividing by an estimate of the
+// paper brightness, then stretches levels between the 1st and 99th percentile
+func normalizeContrast(img *image.RGBA) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	block := max(16, min(w, h)/32)
+	bw, bh := (w+block-1)/block, (h+block-1)/block
+
+	// Paper is the brightest thing in each block; text only darkens it
+	bg := make([]float64, bw*bh)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := (y/block)*bw + x/block
+			bg[i] = math.Max(bg[i], float64(luma(img.Pix, img.PixOffset(x, y))))
+		}
+	}
+	// Blocks fully covered by dark content would darken the estimate; smooth them out
+	smooth := make([]float64, len(bg))
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			var vals []float64
+			for ny := max(0, by-1); ny <= min(bh-1, by+1); ny++ {
+				for nx := max(0, bx-1); nx <= min(bw-1, bx+1); nx++ {
+					vals = append(vals, bg[ny*bw+nx])
+				}
+			}
+			sort.Float64s(vals)
+			smooth[by*bw+bx] = math.Max(vals[len(vals)/2], 1)
+		}
+	}
+
+	background := func(x, y int) float64 {
+		fx := math.Max(0, float64(x)/float64(block)-0.5)
+		fy := math.Max(0, float64(y)/float64(block)-0.5)
+		x0, y0 := min(int(fx), bw-1), min(int(fy), bh-1)
+		x1, y1 := min(x0+1, bw-1), min(y0+1, bh-1)
+		tx, ty := fx-float64(x0), fy-float64(y0)
+		top := smooth[y0*bw+x0]*(1-tx) + smooth[y0*bw+x1]*tx
+		bottom := smooth[y1*bw+x0]*(1-tx) + smooth[y1*bw+x1]*tx
+		return top*(1-ty) + bottom*ty
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gain := 255 / background(x, y)
+			i := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = clamp8(float64(img.Pix[i+c]) * gain)
+			}
+		}
+	}
+
+	hist := lumaHistogram(img)
+	total := w * h
+	lo, hi := 0, 255
+	for n := 0; lo < 255 && n+hist[lo] <= total/100; lo++ {
+		n += hist[lo]
+	}
+	for n := 0; hi > 0 && n+hist[hi] <= total/100; hi-- {
+		n += hist[hi]
+	}
+	if hi-lo < 16 {
+		return
+	}
+	scale := 255 / float64(hi-lo)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = clamp8((float64(img.Pix[i+c]) - float64(lo)) * scale)
+			}
+		}
+	}
+}
+
+func clamp8(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+	return uint8(v + 0.5)
+}
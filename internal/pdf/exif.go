@@ -0,0 +1,148 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// JPEGOrientation returns the EXIF Orientation tag (1-8) of JPEG data.
+// It returns 1 (upright) when data is not a JPEG or carries no orientation.
+func JPEGOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the marker segments up to the start of scan looking for APP1/Exif
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if size < 2 || pos+2+size > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation reads tag 0x0112 from IFD0 of a TIFF structure
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) != 0x0112 {
+			continue
+		}
+		// SHORT values are stored left-justified in the value field
+		o := int(order.Uint16(tiff[entry+8 : entry+10]))
+		if o < 1 || o > 8 {
+			return 1
+		}
+		return o
+	}
+	return 1
+}
+
+// orientationSwapsAxes reports whether orientation o turns the image by 90 degrees
+func orientationSwapsAxes(o int) bool {
+	return o >= 5 && o <= 8
+}
+
+// orientationMatrix returns the PDF cm operands that draw an image with EXIF
+// orientation o upright into the w x h rectangle at (x, y).
+func orientationMatrix(o int, x, y, w, h float64) [6]float64 {
+	switch o {
+	case 2:
+		return [6]float64{-w, 0, 0, h, x + w, y}
+	case 3:
+		return [6]float64{-w, 0, 0, -h, x + w, y + h}
+	case 4:
+		return [6]float64{w, 0, 0, -h, x, y + h}
+	case 5:
+		return [6]float64{0, -h, -w, 0, x + w, y + h}
+	case 6:
+		return [6]float64{0, -h, w, 0, x, y + h}
+	case 7:
+		return [6]float64{0, h, w, 0, x, y}
+	case 8:
+		return [6]float64{0, h, -w, 0, x + w, y}
+	default:
+		return [6]float64{w, 0, 0, h, x, y}
+	}
+}
+
+// OrientImage returns img turned upright according to EXIF orientation o
+func OrientImage(img image.Image, o int) image.Image {
+	if o <= 1 || o > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+	if orientationSwapsAxes(o) {
+		dw, dh = sh, sw
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// c, r: source column and row shown at displayed (x, y)
+			var c, r int
+			switch o {
+			case 2:
+				c, r = sw-1-x, y
+			case 3:
+				c, r = sw-1-x, sh-1-y
+			case 4:
+				c, r = x, sh-1-y
+			case 5:
+				c, r = y, x
+			case 6:
+				c, r = y, sh-1-x
+			case 7:
+				c, r = sw-1-y, sh-1-x
+			case 8:
+				c, r = sw-1-y, x
+			}
+			si := src.PixOffset(c, r)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,366 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Annotation flags that keep an annotation off screen
+const (
+	annotHidden = 1 << 1
+	annotNoView = 1 << 5
+)
+
+// commentSubtypes are the markup annotations treated as comments
+var commentSubtypes = map[string]bool{
+	"Text": true, "FreeText": true, "Line": true, "Square": true, "Circle": true,
+	"Polygon": true, "PolyLine": true, "Highlight": true, "Underline": true,
+	"Squiggly": true, "StrikeOut": true, "Stamp": true, "Caret": true, "Ink": true,
+	"FileAttachment": true, "Sound": true,
+}
+
+// Flatten draws the selected kinds of annotations into the page content and
+// removes them, leaving a document that looks the same but is not editable
+func (s *Service) Flatten(config models.FlattenConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+	if !config.Forms && !config.Comments && !config.Links {
+		return fmt.Errorf("nothing selected to flatten")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, err := api.ReadContextFile(config.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	match := func(subtype string) bool {
+		switch {
+		case subtype == "Link":
+			return config.Links
+		case commentSubtypes[subtype]:
+			return config.Comments
+		}
+		return false
+	}
+	if _, err := flattenAnnotations(ctx.XRefTable, match); err != nil {
+		return err
+	}
+	if config.Forms {
+		if err := flattenForm(ctx.XRefTable); err != nil {
+			return err
+		}
+	}
+
+	return api.WriteContextFile(ctx, config.OutputFile)
+}
+
+// flattenAnnotations draws the normal appearance of every annotation whose
+// subtype matches into the page content and removes it from the page. Popups
+// belonging to flattened annotations are removed as well. It returns the
+// number of annotations flattened.
+func flattenAnnotations(xRefTable *model.XRefTable, match func(subtype string) bool) (int, error) {
+	count := 0
+	for page := 1; page <= xRefTable.PageCount; page++ {
+		n, err := flattenPageAnnotations(xRefTable, page, match)
+		if err != nil {
+			return count, fmt.Errorf("failed to flatten page %d: %w", page, err)
+		}
+		count += n
+	}
+	return count, nil
+}
+
+func flattenPageAnnotations(xRefTable *model.XRefTable, page int, match func(subtype string) bool) (int, error) {
+	d, _, inh, err := xRefTable.PageDict(page, false)
+	if err != nil {
+		return 0, err
+	}
+	annots, err := xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		return 0, err
+	}
+
+	var xObjects types.Dict
+	var ops strings.Builder
+	var kept types.Array
+	flattened := map[types.IndirectRef]bool{}
+	count := 0
+
+	for _, o := range annots {
+		annot, err := xRefTable.DereferenceDict(o)
+		if err != nil || annot == nil {
+			continue
+		}
+		subtype := annotationSubtype(annot)
+		if subtype == "Popup" || !match(subtype) {
+			kept = append(kept, o)
+			continue
+		}
+		if ref, ok := o.(types.IndirectRef); ok {
+			flattened[ref] = true
+		}
+		count++
+
+		if f := annot.IntEntry("F"); f != nil && *f&(annotHidden|annotNoView) != 0 {
+			continue
+		}
+		ap, m, err := annotationAppearance(xRefTable, annot)
+		if err != nil || ap == nil {
+			// Nothing visible to keep
+			continue
+		}
+
+		if xObjects == nil {
+			if xObjects, err = pageXObjects(xRefTable, d, inh); err != nil {
+				return 0, err
+			}
+		}
+		name := uniqueResourceName(xObjects, "Fl")
+		xObjects[name] = *ap
+		fmt.Fprintf(&ops, "q %s %s %s %s %s %s cm /%s Do Q\n",
+			formatNumber(m[0]), formatNumber(m[1]), formatNumber(m[2]),
+			formatNumber(m[3]), formatNumber(m[4]), formatNumber(m[5]), name)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	setPageAnnotations(xRefTable, d, kept, flattened)
+
+	if ops.Len() > 0 {
+		if err := wrapPageContent(xRefTable, d, "q", "Q\n"+ops.String()); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// setPageAnnotations sets the annotations of page dict d to kept, leaving
+// out popups whose parent annotation was removed
+func setPageAnnotations(xRefTable *model.XRefTable, d types.Dict, kept types.Array, removed map[types.IndirectRef]bool) {
+	var remaining types.Array
+	for _, o := range kept {
+		annot, err := xRefTable.DereferenceDict(o)
+		if err == nil && annot != nil && annotationSubtype(annot) == "Popup" {
+			if parent := annot.IndirectRefEntry("Parent"); parent != nil && removed[*parent] {
+				continue
+			}
+		}
+		remaining = append(remaining, o)
+	}
+	if len(remaining) == 0 {
+		delete(d, "Annots")
+	} else {
+		d["Annots"] = remaining
+	}
+}
+
+// annotationSubtype returns the Subtype name of annot, or "" if it has none
+func annotationSubtype(annot types.Dict) string {
+	if st := annot.NameEntry("Subtype"); st != nil {
+		return *st
+	}
+	return ""
+}
+
+// annotationAppearance returns the normal appearance stream of annot as a
+// form XObject and the matrix that places it on the annotation rectangle
+func annotationAppearance(xRefTable *model.XRefTable, annot types.Dict) (*types.IndirectRef, matrix, error) {
+	ap, err := xRefTable.DereferenceDict(annot["AP"])
+	if err != nil {
+		return nil, matrix{}, err
+	}
+	if ap == nil {
+		// Viewers draw fields without appearance from their value
+		if ap, err = fieldAppearance(xRefTable, annot); err != nil || ap == nil {
+			return nil, matrix{}, err
+		}
+	}
+
+	ref, ok := ap["N"].(types.IndirectRef)
+	if !ok {
+		// Appearance states, e.g. the on and off looks of a check box
+		states, err := xRefTable.DereferenceDict(ap["N"])
+		if err != nil || states == nil {
+			return nil, matrix{}, err
+		}
+		as := annot.NameEntry("AS")
+		if as == nil {
+			return nil, matrix{}, nil
+		}
+		if ref, ok = states[*as].(types.IndirectRef); !ok {
+			return nil, matrix{}, nil
+		}
+	}
+
+	sd, _, err := xRefTable.DereferenceStreamDict(ref)
+	if err != nil || sd == nil {
+		return nil, matrix{}, err
+	}
+	rect, err := xRefTable.DereferenceArray(annot["Rect"])
+	if err != nil || len(rect) != 4 {
+		return nil, matrix{}, err
+	}
+	bboxArr, err := xRefTable.DereferenceArray(sd.Dict["BBox"])
+	if err != nil || len(bboxArr) != 4 {
+		return nil, matrix{}, err
+	}
+	formMatrix := identity
+	if arr, err := xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(arr) == 6 {
+		formMatrix = toMatrix(numbers(arr))
+	}
+
+	// The transformed bounding box is fitted onto the rectangle (PDF 32000 12.5.5)
+	var b bbox
+	f := numbers(bboxArr)
+	b.addRect(formMatrix, f[0], f[1], f[2], f[3])
+	r := numbers(rect)
+	x0, x1 := min(r[0], r[2]), max(r[0], r[2])
+	y0, y1 := min(r[1], r[3]), max(r[1], r[3])
+	if b.x1-b.x0 <= 0 || b.y1-b.y0 <= 0 || x1-x0 <= 0 || y1-y0 <= 0 {
+		return nil, matrix{}, nil
+	}
+	sx, sy := (x1-x0)/(b.x1-b.x0), (y1-y0)/(b.y1-b.y0)
+
+	// Appearance streams are form XObjects, but some writers leave that out
+	sd.Dict["Type"] = types.Name("XObject")
+	sd.Dict["Subtype"] = types.Name("Form")
+
+	return &ref, matrix{sx, 0, 0, sy, x0 - sx*b.x0, y0 - sy*b.y0}, nil
+}
+
+// fieldAppearance builds a plain appearance dictionary for a text or choice
+// field widget that has a value but no appearance of its own
+func fieldAppearance(xRefTable *model.XRefTable, annot types.Dict) (types.Dict, error) {
+	ft, v, da := inheritedFieldEntry(xRefTable, annot, "FT"), inheritedFieldEntry(xRefTable, annot, "V"), inheritedFieldEntry(xRefTable, annot, "DA")
+	if name, ok := ft.(types.Name); !ok || (name != "Tx" && name != "Ch") {
+		return nil, nil
+	}
+
+	var value string
+	switch o := v.(type) {
+	case types.StringLiteral, types.HexLiteral:
+		if s, err := types.StringOrHexLiteral(o); err == nil {
+			value = *s
+		}
+	case types.Array:
+		// Multiple selections of a list box
+		var parts []string
+		for _, e := range o {
+			if s, err := types.StringOrHexLiteral(e); err == nil {
+				parts = append(parts, *s)
+			}
+		}
+		value = strings.Join(parts, ", ")
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	rect, err := xRefTable.DereferenceArray(annot["Rect"])
+	if err != nil || len(rect) != 4 {
+		return nil, err
+	}
+	r := numbers(rect)
+	w, h := math.Abs(r[2]-r[0]), math.Abs(r[3]-r[1])
+
+	size := 0.0
+	if s, ok := da.(types.StringLiteral); ok {
+		// The default appearance holds "/Font size Tf"
+		fields := strings.Fields(string(s))
+		for i := 1; i < len(fields); i++ {
+			if fields[i] == "Tf" {
+				size, _ = strconv.ParseFloat(fields[i-1], 64)
+			}
+		}
+	}
+	if size <= 0 {
+		size = math.Min(12, h*0.7)
+	}
+
+	// Helvetica is set up with WinAnsi encoding
+	text, err := types.Escape(types.UTF8ToCP1252(value))
+	if err != nil {
+		return nil, err
+	}
+	content := fmt.Sprintf("/Tx BMC q 1 1 %s %s re W n BT /Helv %s Tf 0 g 2 %s Td (%s) Tj ET Q EMC",
+		formatNumber(w-2), formatNumber(h-2), formatNumber(size), formatNumber((h-size*0.75)/2), *text)
+
+	sd, err := xRefTable.NewStreamDictForBuf([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	sd.Dict["Type"] = types.Name("XObject")
+	sd.Dict["Subtype"] = types.Name("Form")
+	sd.Dict["BBox"] = types.NewNumberArray(0, 0, w, h)
+	sd.Dict["Resources"] = types.Dict{"Font": types.Dict{"Helv": types.Dict{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name("Helvetica"),
+		"Encoding": types.Name("WinAnsiEncoding"),
+	}}}
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	ref, err := xRefTable.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, err
+	}
+	return types.Dict{"N": *ref}, nil
+}
+
+// inheritedFieldEntry looks up an inheritable field entry on a widget and
+// its parent fields
+func inheritedFieldEntry(xRefTable *model.XRefTable, d types.Dict, key string) types.Object {
+	for depth := 0; d != nil && depth < 32; depth++ {
+		if o, ok := d[key]; ok {
+			o, _ = xRefTable.Dereference(o)
+			return o
+		}
+		d, _ = xRefTable.DereferenceDict(d["Parent"])
+	}
+	return nil
+}
+
+// pageXObjects gives page dict d its own copy of its resources and returns
+// the XObject dictionary of that copy, ready for new entries
+func pageXObjects(xRefTable *model.XRefTable, d types.Dict, inh *model.InheritedPageAttrs) (types.Dict, error) {
+	res := types.Dict{}
+	if inh.Resources != nil {
+		res = inh.Resources.Clone().(types.Dict)
+	}
+	xObjects := types.Dict{}
+	if xo, err := xRefTable.DereferenceDict(res["XObject"]); err != nil {
+		return nil, err
+	} else if xo != nil {
+		xObjects = xo.Clone().(types.Dict)
+	}
+	res["XObject"] = xObjects
+	d["Resources"] = res
+	return xObjects, nil
+}
+
+// uniqueResourceName returns prefix followed by the first number not yet
+// used as a key in d
+func uniqueResourceName(d types.Dict, prefix string) string {
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("%s%d", prefix, i)
+		if _, ok := d[name]; !ok {
+			return name
+		}
+	}
+}
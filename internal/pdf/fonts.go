@@ -0,0 +1,140 @@
+package pdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// subsetPrefix matches the tag naming a font subset, as in ABCDEF+Helvetica
+var subsetPrefix = regexp.MustCompile(`^[A-Z]{6}\+`)
+
+// ListFonts returns the fonts used by a PDF with the pages using them,
+// including fonts of form XObjects, patterns and annotation appearances
+func (s *Service) ListFonts(filePath string) ([]models.Font, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	fonts := map[string]*models.Font{}
+	for page := 1; page <= ctx.PageCount; page++ {
+		err := walkPageResources(ctx, page, func(_ []byte, res types.Dict, _ *types.IndirectRef) {
+			dict, _ := ctx.DereferenceDict(res["Font"])
+			for _, name := range sortedKeys(dict) {
+				font, err := ctx.DereferenceDict(dict[name])
+				if err != nil || font == nil {
+					continue
+				}
+				// Direct fonts are told apart by their resource name
+				key := "/" + name + "/" + fontName(font, name)
+				obj := 0
+				if ref, ok := dict[name].(types.IndirectRef); ok {
+					obj = ref.ObjectNumber.Value()
+					key = fmt.Sprint(obj)
+				}
+				f, ok := fonts[key]
+				if !ok {
+					f = fontDescription(ctx.XRefTable, font, name)
+					f.Object = obj
+					fonts[key] = f
+				}
+				if len(f.Pages) == 0 || f.Pages[len(f.Pages)-1] != page {
+					f.Pages = append(f.Pages, page)
+				}
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+	}
+
+	list := make([]models.Font, 0, len(fonts))
+	for _, f := range fonts {
+		list = append(list, *f)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Object < list[j].Object
+	})
+	return list, nil
+}
+
+// ExportFonts writes the fonts of a PDF to outFile as JSON
+func (s *Service) ExportFonts(filePath, outFile string) error {
+	list, err := s.ListFonts(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(outFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outFile, append(data, '\n'), 0644)
+}
+
+// fontDescription reads the name, type, encoding and embedding of a font
+func fontDescription(xRefTable *model.XRefTable, font types.Dict, name string) *models.Font {
+	f := &models.Font{Name: fontName(font, name), Embedded: fontEmbedded(xRefTable, font)}
+	if subsetPrefix.MatchString(f.Name) {
+		f.Subset = true
+		f.Name = f.Name[7:]
+	}
+
+	if st := font.NameEntry("Subtype"); st != nil {
+		f.Type = *st
+	}
+	if f.Type == "Type0" {
+		if descendants, err := xRefTable.DereferenceArray(font["DescendantFonts"]); err == nil && len(descendants) > 0 {
+			if cid, err := xRefTable.DereferenceDict(descendants[0]); err == nil && cid != nil {
+				if st := cid.NameEntry("Subtype"); st != nil {
+					f.Type += "/" + *st
+				}
+			}
+		}
+	}
+
+	f.Encoding = "built-in"
+	o, err := xRefTable.Dereference(font["Encoding"])
+	if err != nil {
+		return f
+	}
+	switch enc := o.(type) {
+	case types.Name:
+		f.Encoding = string(enc)
+	case types.Dict:
+		if base := enc.NameEntry("BaseEncoding"); base != nil {
+			f.Encoding = *base
+		}
+		if enc["Differences"] != nil {
+			f.Encoding += " with differences"
+		}
+	case types.StreamDict:
+		// An embedded CMap
+		f.Encoding = "embedded CMap"
+		if cmap := enc.Dict.NameEntry("CMapName"); cmap != nil {
+			f.Encoding = *cmap
+		}
+	}
+	return f
+}
@@ -0,0 +1,361 @@
+package pdf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/form"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// formFieldTypes names the pdfcpu field types as used in models.FormField
+var formFieldTypes = map[form.FieldType]string{
+	form.FTText:             "Text",
+	form.FTDate:             "Date",
+	form.FTCheckBox:         "CheckBox",
+	form.FTComboBox:         "ComboBox",
+	form.FTListBox:          "ListBox",
+	form.FTRadioButtonGroup: "RadioButtonGroup",
+}
+
+// ListFormFields returns the fields of a PDF form
+func (s *Service) ListFormFields(filePath string) ([]models.FormField, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fields, err := api.FormFields(f, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read form: %w", err)
+	}
+
+	list := make([]models.FormField, 0, len(fields))
+	for _, field := range fields {
+		item := models.FormField{
+			Name:   field.Name,
+			Type:   formFieldTypes[field.Typ],
+			Value:  field.V,
+			Pages:  field.Pages,
+			Locked: field.Locked,
+		}
+		if field.Opts != "" {
+			item.Options = strings.Split(field.Opts, ",")
+		}
+		if field.Typ == form.FTCheckBox {
+			item.Value = strconv.FormatBool(field.V != "" && field.V != "Off")
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// ExportFormValues writes the current field values of a PDF form to a JSON
+// object keyed by field name, as accepted by ReadFormValues
+func (s *Service) ExportFormValues(filePath, outFile string) error {
+	fields, err := s.ListFormFields(filePath)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+	for _, field := range fields {
+		switch field.Type {
+		case "CheckBox":
+			values[field.Name] = field.Value == "true"
+		case "ListBox":
+			selected := []string{}
+			if field.Value != "" {
+				selected = strings.Split(field.Value, ",")
+			}
+			values[field.Name] = selected
+		default:
+			values[field.Name] = field.Value
+		}
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := utils.EnsureDir(filepath.Dir(outFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return os.WriteFile(outFile, append(data, '\n'), 0644)
+}
+
+// ReadFormValues reads a JSON object mapping field names to values. Values
+// may be strings, numbers, booleans or, for list boxes, arrays of strings.
+func (s *Service) ReadFormValues(jsonFile string) (map[string]string, error) {
+	data, err := os.ReadFile(jsonFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid form data: %w", err)
+	}
+
+	values := map[string]string{}
+	for name, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			values[name] = v
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		case float64:
+			values[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case []any:
+			parts := make([]string, len(v))
+			for i, e := range v {
+				parts[i] = fmt.Sprint(e)
+			}
+			values[name] = strings.Join(parts, ",")
+		default:
+			return nil, fmt.Errorf("unsupported value for field %s", name)
+		}
+	}
+	return values, nil
+}
+
+// FillForm fills the fields of a PDF form
+func (s *Service) FillForm(config models.FormFillConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+	if len(config.Values) == 0 {
+		return fmt.Errorf("no form values given")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return fillFormFile(config.InputFile, config.OutputFile, config.Values, config.Flatten)
+}
+
+// BulkFillForm writes one filled copy of a PDF form per CSV row and returns
+// the paths written
+func (s *Service) BulkFillForm(config models.FormBulkFillConfig) ([]string, error) {
+	if !utils.IsPDF(config.InputFile) {
+		return nil, fmt.Errorf("input file must be a PDF")
+	}
+
+	f, err := os.Open(config.CSVFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("CSV needs a header row with field names and at least one data row")
+	}
+
+	if err := utils.EnsureDir(config.OutputDir); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	header := records[0]
+	rows := records[1:]
+	base := strings.TrimSuffix(filepath.Base(config.InputFile), filepath.Ext(config.InputFile))
+	width := len(strconv.Itoa(len(rows)))
+
+	var written []string
+	for i, row := range rows {
+		values := map[string]string{}
+		for j, name := range header {
+			if name = strings.TrimSpace(name); name != "" && j < len(row) {
+				values[name] = row[j]
+			}
+		}
+		outFile := filepath.Join(config.OutputDir, fmt.Sprintf("%s_%0*d.pdf", base, width, i+1))
+		if err := fillFormFile(config.InputFile, outFile, values, config.Flatten); err != nil {
+			return written, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		written = append(written, outFile)
+	}
+	return written, nil
+}
+
+// fillFormFile fills the form of inFile with values and writes outFile
+func fillFormFile(inFile, outFile string, values map[string]string, flatten bool) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.FILLFORMFIELDS
+	ctx, err := api.ReadValidateAndOptimize(f, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if ctx.Form == nil {
+		return fmt.Errorf("PDF has no form")
+	}
+	// Changing the fields invalidates any signature
+	ctx.RemoveSignature()
+
+	matched := map[string]bool{}
+	fillDetails := func(id, name string, fieldType form.FieldType, _ form.DataFormat) ([]string, bool, bool) {
+		key := name
+		v, ok := values[key]
+		if !ok {
+			key = id
+			if v, ok = values[key]; !ok {
+				return nil, false, false
+			}
+		}
+		matched[key] = true
+		switch fieldType {
+		case form.FTCheckBox:
+			checked := false
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "on", "x", "1", "checked":
+				checked = true
+			}
+			// pdfcpu checks a box for values starting with "t"
+			return []string{strconv.FormatBool(checked)}, false, true
+		case form.FTListBox:
+			if v == "" {
+				return []string{}, false, true
+			}
+			return strings.Split(v, ","), false, true
+		}
+		return []string{v}, false, true
+	}
+
+	if _, _, err := form.FillForm(ctx, fillDetails, nil, form.CSV); err != nil {
+		return fmt.Errorf("failed to fill form: %w", err)
+	}
+
+	// A misspelt name would otherwise leave its field blank without notice
+	var unknown []string
+	for name := range values {
+		if !matched[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("no form field named %s", strings.Join(unknown, ", "))
+	}
+
+	if flatten {
+		if err := flattenForm(ctx.XRefTable); err != nil {
+			return err
+		}
+	}
+
+	return api.WriteContextFile(ctx, outFile)
+}
+
+// flattenForm draws all form fields into the page content and removes the
+// interactive form
+func flattenForm(xRefTable *model.XRefTable) error {
+	if _, err := flattenAnnotations(xRefTable, func(subtype string) bool { return subtype == "Widget" }); err != nil {
+		return err
+	}
+	root, err := xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+	delete(root, "AcroForm")
+	xRefTable.Form = nil
+	return nil
+}
+
+// removeFormFields takes the widgets in removed out of the form field tree,
+// together with fields left without widgets, so that no field remains that
+// has no place on any page
+func removeFormFields(xRefTable *model.XRefTable, removed map[types.IndirectRef]bool) error {
+	if len(removed) == 0 {
+		return nil
+	}
+	root, err := xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+	form, err := xRefTable.DereferenceDict(root["AcroForm"])
+	if err != nil || form == nil {
+		return err
+	}
+
+	gone := map[types.IndirectRef]bool{}
+	// prune returns the fields of o that remain, or false if o is unreadable
+	var prune func(o types.Object, depth int) (types.Array, bool)
+	prune = func(o types.Object, depth int) (types.Array, bool) {
+		fields, err := xRefTable.DereferenceArray(o)
+		if err != nil || depth > 32 {
+			return nil, false
+		}
+		var kept types.Array
+		for _, f := range fields {
+			ref, isRef := f.(types.IndirectRef)
+			if isRef && removed[ref] {
+				gone[ref] = true
+				continue
+			}
+			if field, err := xRefTable.DereferenceDict(f); err == nil && field != nil && field["Kids"] != nil {
+				if kids, ok := prune(field["Kids"], depth+1); ok {
+					if len(kids) == 0 {
+						if isRef {
+							gone[ref] = true
+						}
+						continue
+					}
+					field["Kids"] = kids
+				}
+			}
+			kept = append(kept, f)
+		}
+		return kept, true
+	}
+	if fields, ok := prune(form["Fields"], 0); ok {
+		if len(fields) == 0 {
+			delete(root, "AcroForm")
+			xRefTable.Form = nil
+			return nil
+		}
+		form["Fields"] = fields
+	}
+
+	// The calculation order lists fields too
+	if co, err := xRefTable.DereferenceArray(form["CO"]); err == nil && co != nil {
+		var kept types.Array
+		for _, f := range co {
+			if ref, ok := f.(types.IndirectRef); !ok || !gone[ref] {
+				kept = append(kept, f)
+			}
+		}
+		if len(kept) == 0 {
+			delete(form, "CO")
+		} else {
+			form["CO"] = kept
+		}
+	}
+	return nil
+}
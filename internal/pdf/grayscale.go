@@ -0,0 +1,471 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image/jpeg"
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Grayscale writes a copy of a PDF with its colours and images converted to
+// DeviceGray. Only colour operators are rewritten, so text and vector
+// graphics stay vector. The report lists the pages that contained colour.
+func (s *Service) Grayscale(inputFile, outputFile string) (*models.GrayscaleReport, error) {
+	if !utils.IsPDF(inputFile) {
+		return nil, fmt.Errorf("input file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	pages, err := colorPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.GrayscaleReport{Pages: ctx.PageCount, ColorPages: pages}
+	g := &grayConverter{ctx: ctx, xRefTable: ctx.XRefTable, done: map[int]bool{}, report: report}
+	for page := 1; page <= ctx.PageCount; page++ {
+		if err := g.page(page); err != nil {
+			return nil, fmt.Errorf("failed to convert page %d: %w", page, err)
+		}
+	}
+
+	if err := api.WriteContextFile(ctx, outputFile); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// grayConverter rewrites the colours of a document in place
+type grayConverter struct {
+	ctx       *model.Context
+	xRefTable *model.XRefTable
+	done      map[int]bool // streams, images and shadings already converted
+	report    *models.GrayscaleReport
+}
+
+// page converts the content, resources and annotation colours of a page
+func (g *grayConverter) page(page int) error {
+	d, _, _, err := g.ctx.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	var convertErr error
+	err = walkPageResources(g.ctx, page, func(content []byte, res types.Dict, ref *types.IndirectRef) {
+		if convertErr != nil {
+			return
+		}
+		if convertErr = g.resources(res); convertErr != nil {
+			return
+		}
+		if content == nil {
+			return
+		}
+		if ref != nil {
+			if g.done[ref.ObjectNumber.Value()] {
+				return
+			}
+			g.done[ref.ObjectNumber.Value()] = true
+		}
+		ops, err := parseContent(content)
+		if err != nil {
+			return
+		}
+		if !g.content(ops, res) {
+			return
+		}
+		data := writeContent(ops)
+		if ref == nil {
+			newRef, err := newContentStream(g.xRefTable, data)
+			if err != nil {
+				convertErr = err
+				return
+			}
+			d["Contents"] = *newRef
+			return
+		}
+		convertErr = g.replaceContent(*ref, data)
+	})
+	if err != nil {
+		return err
+	}
+	if convertErr != nil {
+		return convertErr
+	}
+
+	annots, err := g.ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		return nil
+	}
+	for _, o := range annots {
+		annot, err := g.ctx.DereferenceDict(o)
+		if err != nil || annot == nil {
+			continue
+		}
+		// Viewers use these colours when they draw annotations again
+		grayColorEntries(g.xRefTable, annot, "C", "IC")
+		if mk, err := g.ctx.DereferenceDict(annot["MK"]); err == nil && mk != nil {
+			grayColorEntries(g.xRefTable, mk, "BC", "BG")
+		}
+	}
+	return nil
+}
+
+// replaceContent replaces the data of a form-like stream
+func (g *grayConverter) replaceContent(ref types.IndirectRef, data []byte) error {
+	entry, found := g.xRefTable.FindTableEntryForIndRef(&ref)
+	if !found {
+		return fmt.Errorf("missing object %d", ref.ObjectNumber)
+	}
+	sd, ok := entry.Object.(types.StreamDict)
+	if !ok {
+		return nil
+	}
+	dict := sd.Dict.Clone().(types.Dict)
+	delete(dict, "Filter")
+	delete(dict, "DecodeParms")
+	stream := types.StreamDict{Dict: dict, Content: data}
+	stream.InsertName("Filter", "FlateDecode")
+	stream.FilterPipeline = []types.PDFFilter{{Name: "FlateDecode"}}
+	if err := stream.Encode(); err != nil {
+		return err
+	}
+	entry.Object = stream
+	return nil
+}
+
+// resources converts the images and shadings of a resource dictionary
+func (g *grayConverter) resources(res types.Dict) error {
+	if res == nil {
+		return nil
+	}
+	xobjs, _ := g.ctx.DereferenceDict(res["XObject"])
+	for _, name := range sortedKeys(xobjs) {
+		ref, ok := xobjs[name].(types.IndirectRef)
+		if !ok || g.done[ref.ObjectNumber.Value()] {
+			continue
+		}
+		g.done[ref.ObjectNumber.Value()] = true
+		sd, _, err := g.ctx.DereferenceStreamDict(ref)
+		if err != nil || sd == nil {
+			continue
+		}
+		if st := sd.Subtype(); st == nil || *st != "Image" {
+			continue
+		}
+		gray, err := g.image(sd, ref.ObjectNumber.Value())
+		if err != nil {
+			return err
+		}
+		if gray != nil {
+			entry, _ := g.xRefTable.FindTableEntryForIndRef(&ref)
+			entry.Object = *gray
+		}
+	}
+
+	if shadings, _ := g.ctx.DereferenceDict(res["Shading"]); shadings != nil {
+		for _, name := range sortedKeys(shadings) {
+			if err := g.shadingEntry(shadings, name, res); err != nil {
+				return err
+			}
+		}
+	}
+	if patterns, _ := g.ctx.DereferenceDict(res["Pattern"]); patterns != nil {
+		for _, name := range sortedKeys(patterns) {
+			if pattern, err := g.ctx.DereferenceDict(patterns[name]); err == nil && pattern != nil && pattern["Shading"] != nil {
+				if err := g.shadingEntry(pattern, "Shading", res); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// image returns a DeviceGray copy of an image, or nil when it is gray
+// already or cannot be decoded. JPEG images stay JPEG.
+func (g *grayConverter) image(sd *types.StreamDict, objNr int) (*types.StreamDict, error) {
+	if mask := sd.BooleanEntry("ImageMask"); mask != nil && *mask {
+		return nil, nil
+	}
+	if cs := resolveColorSpace(g.xRefTable, sd.Dict["ColorSpace"], nil, 0); cs != nil && cs.isGray() {
+		return nil, nil
+	}
+	img, err := decodeImage(g.ctx, sd, objNr)
+	if err != nil {
+		g.report.Skipped++
+		return nil, nil
+	}
+	gray := toGray(toRGBA(img))
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+
+	var out *types.StreamDict
+	if len(sd.FilterPipeline) > 0 && sd.FilterPipeline[len(sd.FilterPipeline)-1].Name == "DCTDecode" {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, gray, &jpeg.Options{Quality: 92}); err != nil {
+			return nil, err
+		}
+		out, err = model.CreateDCTImageStreamDict(g.xRefTable, buf.Bytes(), w, h, 8, model.DeviceGrayCS)
+	} else {
+		out, err = model.CreateFlateImageStreamDict(g.xRefTable, gray.Pix, nil, w, h, 8, model.DeviceGrayCS)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"SMask", "Interpolate", "Intent", "OC", "StructParent"} {
+		if o, ok := sd.Dict[key]; ok {
+			out.Dict[key] = o
+		}
+	}
+	// A stencil mask still applies; colour key masks refer to the old colours
+	if mask, ok := sd.Dict["Mask"].(types.IndirectRef); ok {
+		out.Dict["Mask"] = mask
+	}
+	return out, nil
+}
+
+// shadingEntry converts the shading stored under key in d
+func (g *grayConverter) shadingEntry(d types.Dict, key string, res types.Dict) error {
+	ref, isRef := d[key].(types.IndirectRef)
+	if isRef {
+		if g.done[ref.ObjectNumber.Value()] {
+			return nil
+		}
+		g.done[ref.ObjectNumber.Value()] = true
+	}
+	gray, err := g.shading(d[key], res)
+	if err != nil || gray == nil {
+		return err
+	}
+	if !isRef {
+		d[key] = gray
+		return nil
+	}
+	entry, found := g.xRefTable.FindTableEntryForIndRef(&ref)
+	if !found {
+		return fmt.Errorf("missing object %d", ref.ObjectNumber)
+	}
+	entry.Object = gray
+	return nil
+}
+
+// shading returns a DeviceGray copy of a shading with its function replaced
+// by a sampled gray function, or nil when it is gray already. Shadings
+// without a readable function keep their colours.
+func (g *grayConverter) shading(o types.Object, res types.Dict) (types.Object, error) {
+	obj, err := g.xRefTable.Dereference(o)
+	if err != nil {
+		return nil, nil
+	}
+	sh := shadingDict(g.xRefTable, obj)
+	if sh == nil {
+		return nil, nil
+	}
+	cs := resolveColorSpace(g.xRefTable, sh["ColorSpace"], res, 0)
+	if cs == nil || cs.isGray() {
+		return nil, nil
+	}
+	f := loadFunction(g.xRefTable, sh["Function"], 0)
+	if f == nil {
+		g.report.Skipped++
+		return nil, nil
+	}
+
+	// Sample finely along one input, coarser over two
+	n, size := 256, types.Array{types.Integer(256)}
+	if len(f.domain) >= 4 {
+		n, size = 64, types.Array{types.Integer(64), types.Integer(64)}
+	}
+	var samples []byte
+	for _, in := range functionSamples(f, n) {
+		if len(in) == 2 {
+			// Type 0 samples vary fastest in the first input
+			in[0], in[1] = in[1], in[0]
+		}
+		level, _ := cs.gray(f.eval(in))
+		samples = append(samples, byte(math.Round(math.Max(0, math.Min(1, level))*255)))
+	}
+	fn, err := g.xRefTable.NewStreamDictForBuf(samples)
+	if err != nil {
+		return nil, err
+	}
+	fn.Dict["FunctionType"] = types.Integer(0)
+	fn.Dict["Domain"] = types.NewNumberArray(f.domain...)
+	fn.Dict["Range"] = types.NewNumberArray(0, 1)
+	fn.Dict["Size"] = size
+	fn.Dict["BitsPerSample"] = types.Integer(8)
+	if err := fn.Encode(); err != nil {
+		return nil, err
+	}
+	fnRef, err := g.xRefTable.IndRefForNewObject(*fn)
+	if err != nil {
+		return nil, err
+	}
+
+	dict := sh.Clone().(types.Dict)
+	dict["ColorSpace"] = types.Name("DeviceGray")
+	dict["Function"] = *fnRef
+	if bg, err := g.xRefTable.DereferenceArray(sh["Background"]); err == nil && bg != nil {
+		level, _ := cs.gray(numbers(bg))
+		dict["Background"] = types.Array{types.Float(grayLevel(level))}
+	}
+	if sd, ok := obj.(types.StreamDict); ok {
+		// Mesh shadings keep their vertex data, which holds function inputs
+		sd.Dict = dict
+		return sd, nil
+	}
+	return dict, nil
+}
+
+// content converts the colour operators and inline images of a content
+// stream in place. It reports whether anything changed.
+func (g *grayConverter) content(ops []contentOp, res types.Dict) bool {
+	// The colour spaces of the original colours; nil where colours are left
+	// as they are
+	state := colorState{deviceGray, deviceGray}
+	var stack []colorState
+	changed := false
+	setGray := func(i int, operator string, level float64) {
+		ops[i] = contentOp{Operator: operator, Operands: []types.Object{types.Float(grayLevel(level))}}
+		changed = true
+	}
+	for i, op := range ops {
+		switch op.Operator {
+		case "q":
+			stack = append(stack, state)
+		case "Q":
+			if len(stack) > 0 {
+				state, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case "rg", "k":
+			cs := deviceRGB
+			if op.Operator == "k" {
+				cs = deviceCMYK
+			}
+			level, _ := cs.gray(numbers(op.Operands))
+			setGray(i, "g", level)
+		case "RG", "K":
+			cs := deviceRGB
+			if op.Operator == "K" {
+				cs = deviceCMYK
+			}
+			level, _ := cs.gray(numbers(op.Operands))
+			setGray(i, "G", level)
+		case "cs", "CS":
+			if len(op.Operands) != 1 {
+				continue
+			}
+			cs := resolveColorSpace(g.xRefTable, op.Operands[0], res, 0)
+			switch {
+			case cs == nil || cs.family == "Pattern":
+				// Unknown spaces and patterns keep their colour values
+				cs = nil
+			case cs != deviceGray || op.Operands[0] != types.Name("DeviceGray"):
+				ops[i].Operands = []types.Object{types.Name("DeviceGray")}
+				changed = true
+			}
+			if op.Operator == "cs" {
+				state.fill = cs
+			} else {
+				state.stroke = cs
+			}
+		case "sc", "scn", "SC", "SCN":
+			cs := state.fill
+			if op.Operator == "SC" || op.Operator == "SCN" {
+				cs = state.stroke
+			}
+			if cs == nil || len(op.Operands) == 0 {
+				continue
+			}
+			if _, ok := op.Operands[len(op.Operands)-1].(types.Name); ok {
+				continue
+			}
+			c := numbers(op.Operands)
+			if cs.n != len(c) {
+				// A space set by an enclosing content stream
+				switch len(c) {
+				case 3:
+					cs = deviceRGB
+				case 4:
+					cs = deviceCMYK
+				default:
+					continue
+				}
+			}
+			if cs == deviceGray {
+				continue
+			}
+			level, _ := cs.gray(c)
+			setGray(i, op.Operator, level)
+		case "BI":
+			if g.inlineImage(&ops[i], res) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// inlineImage converts an unfiltered inline image with 8 bits per component
+// to DeviceGray. It reports whether the image was converted.
+func (g *grayConverter) inlineImage(op *contentOp, res types.Dict) bool {
+	d, _ := op.Operands[0].(types.Dict)
+	if d == nil || d["IM"] == types.Boolean(true) || d["ImageMask"] == types.Boolean(true) {
+		return false
+	}
+	cs := resolveColorSpace(g.xRefTable, inlineEntry(d, "CS", "ColorSpace"), res, 0)
+	if cs == nil || cs.isGray() {
+		return false
+	}
+	samples, ok := inlineSamples(d, op.Inline, cs)
+	if !ok {
+		g.report.Skipped++
+		return false
+	}
+	data := make([]byte, len(samples))
+	for i, c := range samples {
+		level, _ := cs.gray(c)
+		data[i] = byte(math.Round(math.Max(0, math.Min(1, level)) * 255))
+	}
+	dict := d.Clone().(types.Dict)
+	delete(dict, "ColorSpace")
+	delete(dict, "BitsPerComponent")
+	dict["CS"] = types.Name("G")
+	dict["BPC"] = types.Integer(8)
+	op.Operands[0] = dict
+	op.Inline = data
+	return true
+}
+
+// grayColorEntries converts the RGB or CMYK colour arrays stored under keys
+func grayColorEntries(xRefTable *model.XRefTable, d types.Dict, keys ...string) {
+	for _, key := range keys {
+		arr, err := xRefTable.DereferenceArray(d[key])
+		if err != nil {
+			continue
+		}
+		var level float64
+		switch len(arr) {
+		case 3:
+			level, _ = deviceRGB.gray(numbers(arr))
+		case 4:
+			level, _ = deviceCMYK.gray(numbers(arr))
+		default:
+			continue
+		}
+		d[key] = types.Array{types.Float(grayLevel(level))}
+	}
+}
+
+// grayLevel rounds a gray level to three decimals
+func grayLevel(level float64) float64 {
+	return math.Round(math.Max(0, math.Min(1, level))*1000) / 1000
+}
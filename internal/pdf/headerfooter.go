@@ -0,0 +1,90 @@
+package pdf
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// HeaderFooter stamps header and footer text on the selected pages
+func (s *Service) HeaderFooter(config models.HeaderFooterConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(config.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	stamps := map[int][]textStamp{}
+	for _, page := range selectedPages(config.Pages, ctx.PageCount) {
+		if list := headerFooterStamps(config, ctx.Title, page, ctx.PageCount); len(list) > 0 {
+			stamps[page] = list
+		}
+	}
+	if len(stamps) == 0 {
+		return fmt.Errorf("no header or footer text to add")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return stampText(config.InputFile, config.OutputFile, stamps)
+}
+
+// TitleAndPageCount returns the document title and page count of a PDF,
+// the values of {title} and {pages}
+func (s *Service) TitleAndPageCount(filePath string) (string, int, error) {
+	if !utils.IsPDF(filePath) {
+		return "", 0, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return ctx.Title, ctx.PageCount, nil
+}
+
+// HeaderFooterText resolves the header and footer text of one page of a
+// document with the given title and page count, e.g. for previews
+func HeaderFooterText(config models.HeaderFooterConfig, title string, page, pages int) map[models.Position]string {
+	texts := map[models.Position]string{}
+	for _, st := range headerFooterStamps(config, title, page, pages) {
+		texts[st.Position] = st.Text
+	}
+	return texts
+}
+
+// headerFooterStamps returns the non-empty stamps for one page with variables resolved
+func headerFooterStamps(config models.HeaderFooterConfig, title string, page, pages int) []textStamp {
+	layout := config.DateFormat
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+	vars := strings.NewReplacer(
+		"{filename}", filepath.Base(config.InputFile),
+		"{date}", time.Now().Format(layout),
+		"{page}", strconv.Itoa(page),
+		"{pages}", strconv.Itoa(pages),
+		"{title}", title,
+	)
+
+	var list []textStamp
+	for pos, text := range config.Text {
+		resolved := strings.TrimSpace(vars.Replace(text))
+		if resolved == "" {
+			continue
+		}
+		list = append(list, textStamp{Text: resolved, Position: pos, FontName: config.FontName, FontSize: config.FontSize})
+	}
+	return list
+}
@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	_ "golang.org/x/image/bmp"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// ImagesToPDF converts multiple images to a single PDF, one image per page
+func (s *Service) ImagesToPDF(config models.ImagesToPDFConfig) error {
+	if len(config.InputFiles) == 0 {
+		return fmt.Errorf("no image files provided")
+	}
+
+	if config.Margin < 0 {
+		return fmt.Errorf("margin must not be negative")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, err := pdfcpu.CreateContextWithXRefTable(model.NewDefaultConfiguration(), types.PaperSize["A4"])
+	if err != nil {
+		return err
+	}
+
+	pagesIndRef, err := ctx.Pages()
+	if err != nil {
+		return err
+	}
+	pagesDict, err := ctx.DereferenceDict(*pagesIndRef)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range config.InputFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read image %s: %w", filepath.Base(file), err)
+		}
+
+		orientation := 1
+		if !config.IgnoreEXIF {
+			orientation = JPEGOrientation(data)
+		}
+
+		if enhancementEnabled(config.Enhance) {
+			// The pipeline works on upright pixels, so orientation is baked in here
+			if data, err = enhanceImageData(data, orientation, config.Enhance); err != nil {
+				return fmt.Errorf("failed to enhance image %s: %w", filepath.Base(file), err)
+			}
+			orientation = 1
+		}
+
+		resources, err := model.CreateImageResources(ctx.XRefTable, bytes.NewReader(data), false, false)
+		if err != nil {
+			return fmt.Errorf("failed to import image %s: %w", filepath.Base(file), err)
+		}
+
+		for _, res := range resources {
+			indRef, err := newImagePage(ctx.XRefTable, pagesIndRef, res, orientation, config)
+			if err != nil {
+				return fmt.Errorf("failed to import image %s: %w", filepath.Base(file), err)
+			}
+			if err := model.AppendPageTree(indRef, 1, pagesDict); err != nil {
+				return err
+			}
+			ctx.PageCount++
+		}
+	}
+
+	return api.WriteContextFile(ctx, config.OutputFile)
+}
+
+// enhanceImageData decodes an image, applies orientation and the scan
+// enhancement pipeline and re-encodes it. Only the first frame of multi-frame
+// images is kept.
+func enhanceImageData(data []byte, orientation int, opts models.ScanEnhancement) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	img = EnhanceScan(OrientImage(img, orientation), opts)
+
+	var buf bytes.Buffer
+	// Keep photos lossy-compressed; bilevel, gray and lossless sources go to PNG
+	if format == "jpeg" && !opts.Threshold && !opts.Grayscale {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 92})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newImagePage creates a page dict that shows res upright (per EXIF orientation)
+// and laid out according to config
+func newImagePage(xRefTable *model.XRefTable, parent *types.IndirectRef, res model.ImageResource, orientation int, config models.ImagesToPDFConfig) (*types.IndirectRef, error) {
+	dpi := config.DPI
+	if dpi <= 0 {
+		dpi = 72
+	}
+	// natural image size in points
+	iw := float64(res.Width) * 72 / float64(dpi)
+	ih := float64(res.Height) * 72 / float64(dpi)
+	if orientationSwapsAxes(orientation) {
+		iw, ih = ih, iw
+	}
+
+	pageW, pageH, err := imagePageSize(iw, ih, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// content area inside the margins
+	m := config.Margin
+	cw, ch := pageW-2*m, pageH-2*m
+	if cw <= 0 || ch <= 0 {
+		return nil, fmt.Errorf("margins leave no room on the page")
+	}
+
+	var w, h float64
+	switch config.FitMode {
+	case models.FitFill:
+		scale := math.Max(cw/iw, ch/ih)
+		w, h = iw*scale, ih*scale
+	case models.FitStretch:
+		w, h = cw, ch
+	case models.FitCenter:
+		w, h = iw, ih
+	default:
+		scale := math.Min(cw/iw, ch/ih)
+		w, h = iw*scale, ih*scale
+	}
+	x := m + (cw-w)/2
+	y := m + (ch-h)/2
+
+	// Clip to the content area so fill/center never spill into the margins
+	cm := orientationMatrix(orientation, x, y, w, h)
+	content := fmt.Sprintf("q %.3f %.3f %.3f %.3f re W n %.5f %.5f %.5f %.5f %.5f %.5f cm /%s Do Q",
+		m, m, cw, ch, cm[0], cm[1], cm[2], cm[3], cm[4], cm[5], res.Res.ID)
+
+	sd, err := xRefTable.NewStreamDictForBuf([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	contentsIndRef, err := xRefTable.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, err
+	}
+
+	resDict := types.Dict(map[string]types.Object{
+		"ProcSet": types.NewNameArray("PDF", "ImageB", "ImageC", "ImageI"),
+		"XObject": types.Dict(map[string]types.Object{res.Res.ID: *res.Res.IndRef}),
+	})
+
+	pageDict := types.Dict(map[string]types.Object{
+		"Type":      types.Name("Page"),
+		"Parent":    *parent,
+		"MediaBox":  types.RectForDim(pageW, pageH).Array(),
+		"Resources": resDict,
+		"Contents":  *contentsIndRef,
+	})
+
+	return xRefTable.IndRefForNewObject(pageDict)
+}
+
+// imagePageSize returns the page dimensions for an image of natural size iw x ih
+func imagePageSize(iw, ih float64, config models.ImagesToPDFConfig) (float64, float64, error) {
+	if config.PageSize == models.PaperImage {
+		return iw + 2*config.Margin, ih + 2*config.Margin, nil
+	}
+
+	name := string(config.PageSize)
+	if name == "" {
+		name = string(models.PaperA4)
+	}
+	dim, ok := types.PaperSize[name]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown page size: %s", name)
+	}
+
+	w, h := dim.Width, dim.Height
+	landscape := false
+	switch config.Orientation {
+	case models.OrientationLandscape:
+		landscape = true
+	case models.OrientationAuto:
+		landscape = iw > ih
+	}
+	if landscape != (w > h) {
+		w, h = h, w
+	}
+	return w, h, nil
+}
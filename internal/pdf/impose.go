@@ -0,0 +1,90 @@
+package pdf
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// NUp lays out several source pages on each output sheet
+func (s *Service) NUp(config models.NUpConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return nupFile(config.InputFile, config.OutputFile, config.NUpOptions)
+}
+
+// nupFile imposes inFile into outFile according to opts
+func nupFile(inFile, outFile string, opts models.NUpOptions) error {
+	switch opts.PagesPerSheet {
+	case 2, 4, 6, 9, 16:
+	default:
+		return fmt.Errorf("pages per sheet must be one of 2, 4, 6, 9 or 16")
+	}
+
+	nup, err := api.PDFNUpConfig(opts.PagesPerSheet, nupDescription(opts), nil)
+	if err != nil {
+		return fmt.Errorf("invalid N-up options: %w", err)
+	}
+
+	return api.NUpFile([]string{inFile}, outFile, nil, nup, nil)
+}
+
+// nupDescription renders opts in pdfcpu's N-up description syntax
+func nupDescription(opts models.NUpOptions) string {
+	paper := opts.PaperSize
+	if paper == "" {
+		paper = models.PaperA4
+	}
+	order := "rd"
+	if opts.ColumnMajor {
+		order = "dr"
+	}
+	border := "off"
+	if opts.Border {
+		border = "on"
+	}
+	return fmt.Sprintf("papersize:%s, orientation:%s, border:%s, margin:%g", paper, order, border, opts.Margin)
+}
+
+// Booklet reorders pages for saddle-stitch printing, two pages per side of a
+// landscape sheet. The page count is padded with blank pages to a multiple of 4.
+// Long documents can be split into signatures of SheetsPerSignature sheets each.
+func (s *Service) Booklet(config models.BookletConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+
+	if config.SheetsPerSignature < 0 {
+		return fmt.Errorf("sheets per signature must not be negative")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	paper := config.PaperSize
+	if paper == "" {
+		paper = models.PaperA4
+	}
+	desc := fmt.Sprintf("papersize:%sL", strings.TrimSuffix(string(paper), "L"))
+	if config.SheetsPerSignature > 0 {
+		desc += fmt.Sprintf(", multifolio:on, foliosize:%d", config.SheetsPerSignature)
+	}
+
+	booklet, err := api.PDFBookletConfig(2, desc, nil)
+	if err != nil {
+		return fmt.Errorf("invalid booklet options: %w", err)
+	}
+
+	return api.BookletFile([]string{config.InputFile}, config.OutputFile, nil, booklet, nil)
+}
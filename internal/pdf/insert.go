@@ -0,0 +1,130 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Insert adds blank pages or pages of another PDF before or after a page
+func (s *Service) Insert(config models.InsertConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+	if config.SourceFile != "" && !utils.IsPDF(config.SourceFile) {
+		return fmt.Errorf("source file must be a PDF")
+	}
+	if config.SourceFile == "" && config.BlankPages < 1 {
+		return fmt.Errorf("must insert at least one blank page or choose a source PDF")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	dims, err := api.PageDimsFile(config.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	pageCount := len(dims)
+	if config.Page < 1 || config.Page > pageCount {
+		return fmt.Errorf("page %d out of range (1-%d)", config.Page, pageCount)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "pdf-toolbox-insert-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Pages to insert
+	inserted := filepath.Join(tmpDir, "insert.pdf")
+	if config.SourceFile != "" {
+		sourceCount, err := s.GetPageCount(config.SourceFile)
+		if err != nil {
+			return err
+		}
+		pages := selectedPages(config.SourcePages, sourceCount)
+		if len(pages) == 0 {
+			return fmt.Errorf("no source pages in range (1-%d)", sourceCount)
+		}
+		if err := api.TrimFile(config.SourceFile, inserted, pageSelectors(pages), nil); err != nil {
+			return fmt.Errorf("failed to extract source pages: %w", err)
+		}
+	} else {
+		dim := dims[config.Page-1]
+		if err := writeBlankPDF(inserted, config.BlankPages, dim.Width, dim.Height); err != nil {
+			return fmt.Errorf("failed to create blank pages: %w", err)
+		}
+	}
+
+	// Pages of the input before and after the insertion point
+	at := config.Page - 1
+	if config.After {
+		at = config.Page
+	}
+	var parts []string
+	if at > 0 {
+		head := filepath.Join(tmpDir, "head.pdf")
+		if err := api.TrimFile(config.InputFile, head, []string{fmt.Sprintf("1-%d", at)}, nil); err != nil {
+			return fmt.Errorf("failed to split input: %w", err)
+		}
+		parts = append(parts, head)
+	}
+	parts = append(parts, inserted)
+	if at < pageCount {
+		tail := filepath.Join(tmpDir, "tail.pdf")
+		if err := api.TrimFile(config.InputFile, tail, []string{fmt.Sprintf("%d-%d", at+1, pageCount)}, nil); err != nil {
+			return fmt.Errorf("failed to split input: %w", err)
+		}
+		parts = append(parts, tail)
+	}
+
+	return api.MergeCreateFile(parts, config.OutputFile, false, nil)
+}
+
+// pageSelectors turns page numbers into pdfcpu page selection tokens
+func pageSelectors(pages []int) []string {
+	selectors := make([]string, 0, len(pages))
+	for _, page := range pages {
+		selectors = append(selectors, fmt.Sprintf("%d", page))
+	}
+	return selectors
+}
+
+// writeBlankPDF writes a PDF of n empty pages of w x h points
+func writeBlankPDF(outFile string, n int, w, h float64) error {
+	ctx, err := pdfcpu.CreateContextWithXRefTable(model.NewDefaultConfiguration(), types.PaperSize["A4"])
+	if err != nil {
+		return err
+	}
+
+	pagesIndRef, err := ctx.Pages()
+	if err != nil {
+		return err
+	}
+	pagesDict, err := ctx.DereferenceDict(*pagesIndRef)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		indRef, err := ctx.EmptyPage(pagesIndRef, types.RectForDim(w, h))
+		if err != nil {
+			return err
+		}
+		if err := model.AppendPageTree(indRef, 1, pagesDict); err != nil {
+			return err
+		}
+		ctx.PageCount++
+	}
+
+	return api.WriteContextFile(ctx, outFile)
+}
@@ -0,0 +1,103 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// PageNumbering stamps page or Bates numbers on every input file and writes
+// "<name>_numbered.pdf" files to config.OutputDir. The {n} counter continues
+// across files, so a production set gets consecutive numbers. It returns the
+// next unused counter value.
+func (s *Service) PageNumbering(config models.PageNumberingConfig) (int, error) {
+	if len(config.InputFiles) == 0 {
+		return 0, fmt.Errorf("no input files provided")
+	}
+
+	if strings.TrimSpace(config.Format) == "" {
+		return 0, fmt.Errorf("number format must not be empty")
+	}
+
+	for _, file := range config.InputFiles {
+		if !utils.IsPDF(file) {
+			return 0, fmt.Errorf("all input files must be PDFs: %s", file)
+		}
+	}
+
+	counter := config.Start
+	for _, file := range config.InputFiles {
+		pageCount, err := s.GetPageCount(file)
+		if err != nil {
+			return 0, err
+		}
+
+		stamps := map[int][]textStamp{}
+		for _, page := range selectedPages(config.Pages, pageCount) {
+			text := formatPageNumber(config.Format, counter, config.Digits, page, pageCount)
+			stamps[page] = []textStamp{{Text: text, Position: config.Position, FontName: config.FontName, FontSize: config.FontSize}}
+			counter++
+		}
+
+		outputDir := config.OutputDir
+		if outputDir == "" {
+			outputDir = filepath.Dir(file)
+		}
+		if err := utils.EnsureDir(outputDir); err != nil {
+			return 0, fmt.Errorf("failed to create output directory: %w", err)
+		}
+		outputFile := filepath.Join(outputDir, filepath.Base(utils.GenerateOutputFileName(file, "numbered", ".pdf")))
+
+		if len(stamps) == 0 {
+			return 0, fmt.Errorf("no selected pages in %s", filepath.Base(file))
+		}
+		if err := stampText(file, outputFile, stamps); err != nil {
+			os.Remove(outputFile)
+			return 0, fmt.Errorf("failed to number %s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return counter, nil
+}
+
+// formatPageNumber resolves the {n}, {page} and {pages} placeholders
+func formatPageNumber(format string, n, digits, page, pages int) string {
+	counter := strconv.Itoa(n)
+	if len(counter) < digits {
+		counter = strings.Repeat("0", digits-len(counter)) + counter
+	}
+	return strings.NewReplacer(
+		"{n}", counter,
+		"{page}", strconv.Itoa(page),
+		"{pages}", strconv.Itoa(pages),
+	).Replace(format)
+}
+
+// selectedPages returns the pages of selection that exist in a document of
+// pageCount pages, or all pages when selection is empty
+func selectedPages(selection []int, pageCount int) []int {
+	if len(selection) == 0 {
+		pages := make([]int, 0, pageCount)
+		for i := 1; i <= pageCount; i++ {
+			pages = append(pages, i)
+		}
+		return pages
+	}
+
+	seen := map[int]bool{}
+	var pages []int
+	for _, p := range selection {
+		if p >= 1 && p <= pageCount && !seen[p] {
+			seen[p] = true
+			pages = append(pages, p)
+		}
+	}
+	sort.Ints(pages)
+	return pages
+}
@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
-	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"pdf-toolbox/internal/utils"
 	"pdf-toolbox/pkg/models"
 )
@@ -44,21 +43,41 @@ func (s *Service) Split(config models.SplitConfig, inputFile string) error {
 		return err
 	}
 
+	// Impose first so each chunk holds PagesPerFile sheets
+	source := inputFile
+	perSheet := 1
+	if config.NUp.PagesPerSheet > 1 {
+		tmp, err := os.CreateTemp("", "pdf-toolbox-nup-*.pdf")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		tmp.Close()
+		defer os.Remove(tmp.Name())
+		if err := nupFile(inputFile, tmp.Name(), config.NUp); err != nil {
+			return fmt.Errorf("failed to impose pages: %w", err)
+		}
+		source = tmp.Name()
+		perSheet = config.NUp.PagesPerSheet
+	}
+	sheetCount := (pageCount + perSheet - 1) / perSheet
+
 	// Split the PDF
 	baseName := filepath.Base(inputFile)
 	baseName = baseName[:len(baseName)-len(filepath.Ext(baseName))]
 
-	for start := 1; start <= pageCount; start += config.PagesPerFile {
+	for start := 1; start <= sheetCount; start += config.PagesPerFile {
 		end := start + config.PagesPerFile - 1
-		if end > pageCount {
-			end = pageCount
+		if end > sheetCount {
+			end = sheetCount
 		}
 
-		outputFile := filepath.Join(config.OutputDir, fmt.Sprintf("%s_pages_%d-%d.pdf", baseName, start, end))
-		
+		// Name files after the source pages they contain
+		first, last := (start-1)*perSheet+1, min(end*perSheet, pageCount)
+		outputFile := filepath.Join(config.OutputDir, fmt.Sprintf("%s_pages_%d-%d.pdf", baseName, first, last))
+
 		span := fmt.Sprintf("%d-%d", start, end)
-		if err := api.TrimFile(inputFile, outputFile, []string{span}, nil); err != nil {
-			return fmt.Errorf("failed to split pages %d-%d: %w", start, end, err)
+		if err := api.TrimFile(source, outputFile, []string{span}, nil); err != nil {
+			return fmt.Errorf("failed to split pages %d-%d: %w", first, last, err)
 		}
 	}
 
@@ -81,7 +100,22 @@ func (s *Service) Merge(config models.MergeConfig) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	return api.MergeCreateFile(config.InputFiles, config.OutputFile, false, nil)
+	if config.Normalize.PaperSize == "" {
+		return api.MergeCreateFile(config.InputFiles, config.OutputFile, false, nil)
+	}
+
+	// Merge into a temporary file first, then scale every page to one size
+	tmp, err := os.CreateTemp("", "pdf-toolbox-merge-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := api.MergeCreateFile(config.InputFiles, tmp.Name(), false, nil); err != nil {
+		return err
+	}
+	return resizeFile(tmp.Name(), config.OutputFile, nil, config.Normalize)
 }
 
 // DeletePages removes specified pages from a PDF
@@ -130,22 +164,6 @@ func (s *Service) ExtractPages(inputFile, outputFile string, pages []int) error
     return api.TrimFile(inputFile, outputFile, selectors, nil)
 }
 
-// ImagesToPDF converts multiple images to a single PDF
-func (s *Service) ImagesToPDF(imageFiles []string, outputFile string) error {
-	if len(imageFiles) == 0 {
-		return fmt.Errorf("no image files provided")
-	}
-
-	if err := utils.EnsureDir(filepath.Dir(outputFile)); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Create import configuration
-	imp := pdfcpu.DefaultImportConfig()
-
-	return api.ImportImagesFile(imageFiles, outputFile, imp, nil)
-}
-
 // GetInfo returns information about a PDF file
 func (s *Service) GetInfo(filePath string) (map[string]interface{}, error) {
 	if !utils.IsPDF(filePath) {
@@ -0,0 +1,100 @@
+package pdf
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// newContentStream adds a flate encoded stream holding data
+func newContentStream(xRefTable *model.XRefTable, data []byte) (*types.IndirectRef, error) {
+	sd, err := xRefTable.NewStreamDictForBuf(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	return xRefTable.IndRefForNewObject(*sd)
+}
+
+// wrapPageContent surrounds the existing content of page dict d with prefix
+// and suffix, each added as a separate content stream
+func wrapPageContent(xRefTable *model.XRefTable, d types.Dict, prefix, suffix string) error {
+	var contents types.Array
+	switch o := d["Contents"].(type) {
+	case types.IndirectRef:
+		obj, err := xRefTable.Dereference(o)
+		if err != nil {
+			return err
+		}
+		if arr, ok := obj.(types.Array); ok {
+			contents = append(contents, arr...)
+		} else {
+			contents = types.Array{o}
+		}
+	case types.Array:
+		contents = append(contents, o...)
+	}
+
+	pre, err := newContentStream(xRefTable, []byte(prefix+"\n"))
+	if err != nil {
+		return err
+	}
+	post, err := newContentStream(xRefTable, []byte("\n"+suffix))
+	if err != nil {
+		return err
+	}
+	d["Contents"] = append(append(types.Array{*pre}, contents...), *post)
+	return nil
+}
+
+// transformAnnotations moves the annotations of page dict d by m: their
+// rectangles and the points of markup, ink, line and polygon annotations
+func transformAnnotations(xRefTable *model.XRefTable, d types.Dict, m matrix) error {
+	annots, err := xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || annots == nil {
+		return err
+	}
+	for _, o := range annots {
+		annot, err := xRefTable.DereferenceDict(o)
+		if err != nil || annot == nil {
+			continue
+		}
+		if rect, err := xRefTable.DereferenceArray(annot["Rect"]); err == nil && len(rect) == 4 {
+			f := numbers(rect)
+			var b bbox
+			b.addRect(m, f[0], f[1], f[2], f[3])
+			annot["Rect"] = types.NewNumberArray(b.x0, b.y0, b.x1, b.y1)
+		}
+		// Flat arrays of x y pairs
+		for _, key := range []string{"QuadPoints", "Vertices", "L", "CL"} {
+			if points, err := xRefTable.DereferenceArray(annot[key]); err == nil && points != nil {
+				annot[key] = transformPoints(points, m)
+			}
+		}
+		// InkList holds one array of x y pairs per stroke
+		if strokes, err := xRefTable.DereferenceArray(annot["InkList"]); err == nil && strokes != nil {
+			list := make(types.Array, 0, len(strokes))
+			for _, stroke := range strokes {
+				points, err := xRefTable.DereferenceArray(stroke)
+				if err != nil || points == nil {
+					continue
+				}
+				list = append(list, transformPoints(points, m))
+			}
+			annot["InkList"] = list
+		}
+	}
+	return nil
+}
+
+// transformPoints returns the x y pairs of points mapped by m
+func transformPoints(points types.Array, m matrix) types.Array {
+	f := numbers(points)
+	out := make([]float64, 0, len(f))
+	for i := 0; i+1 < len(f); i += 2 {
+		x, y := m.apply(f[i], f[i+1])
+		out = append(out, x, y)
+	}
+	return types.NewNumberArray(out...)
+}
@@ -0,0 +1,230 @@
+package pdf
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// PageLabelRanges returns the page label ranges of a PDF in page order, or
+// nil if its pages are not labeled
+func (s *Service) PageLabelRanges(filePath string) ([]models.PageLabelRange, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return pageLabelRanges(ctx)
+}
+
+// PageLabels returns the label of every page of a PDF, or nil if its pages
+// are not labeled
+func (s *Service) PageLabels(filePath string) ([]string, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := api.ReadContextFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	ranges, err := pageLabelRanges(ctx)
+	if err != nil || ranges == nil {
+		return nil, err
+	}
+	return PageLabelsFor(ranges, ctx.PageCount), nil
+}
+
+// SetPageLabels writes a copy of a PDF labeled with the given ranges. Pages
+// before the first range are numbered 1, 2, 3; no ranges removes the labels.
+func (s *Service) SetPageLabels(inputFile, outputFile string, ranges []models.PageLabelRange) error {
+	if !utils.IsPDF(inputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(outputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, err := api.ReadContextFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	ranges = append([]models.PageLabelRange(nil), ranges...)
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].FirstPage < ranges[j].FirstPage })
+	for i, r := range ranges {
+		if r.FirstPage < 1 || r.FirstPage > ctx.PageCount {
+			return fmt.Errorf("page %d out of range (1-%d)", r.FirstPage, ctx.PageCount)
+		}
+		if i > 0 && r.FirstPage == ranges[i-1].FirstPage {
+			return fmt.Errorf("two ranges start on page %d", r.FirstPage)
+		}
+		if r.Start < 1 && r.Style != models.LabelNone {
+			return fmt.Errorf("the range on page %d must start at 1 or more", r.FirstPage)
+		}
+		switch r.Style {
+		case models.LabelNone, models.LabelDecimal, models.LabelRomanUpper, models.LabelRomanLower,
+			models.LabelLettersUpper, models.LabelLettersLower:
+		default:
+			return fmt.Errorf("unknown page label style %q", r.Style)
+		}
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	if len(ranges) == 0 {
+		delete(catalog, "PageLabels")
+		return api.WriteContextFile(ctx, outputFile)
+	}
+
+	// The number tree must label the first page
+	if ranges[0].FirstPage != 1 {
+		ranges = append([]models.PageLabelRange{{FirstPage: 1, Style: models.LabelDecimal, Start: 1}}, ranges...)
+	}
+
+	nums := types.Array{}
+	for _, r := range ranges {
+		label := types.Dict{"Type": types.Name("PageLabel")}
+		if r.Style != models.LabelNone {
+			label["S"] = types.Name(r.Style)
+		}
+		if r.Prefix != "" {
+			prefix, err := pdfText(r.Prefix)
+			if err != nil {
+				return err
+			}
+			label["P"] = prefix
+		}
+		if r.Start > 1 {
+			label["St"] = types.Integer(r.Start)
+		}
+		nums = append(nums, types.Integer(r.FirstPage-1), label)
+	}
+	catalog["PageLabels"] = types.Dict{"Nums": nums}
+
+	return api.WriteContextFile(ctx, outputFile)
+}
+
+// PageLabelsFor returns the labels of pageCount pages labeled with ranges
+// sorted by first page. Pages before the first range get no label.
+func PageLabelsFor(ranges []models.PageLabelRange, pageCount int) []string {
+	labels := make([]string, pageCount)
+	for i, r := range ranges {
+		end := pageCount
+		if i+1 < len(ranges) && ranges[i+1].FirstPage-1 < end {
+			end = ranges[i+1].FirstPage - 1
+		}
+		for page := r.FirstPage; page <= end; page++ {
+			if page >= 1 {
+				labels[page-1] = r.Prefix + labelNumber(r.Style, r.Start+page-r.FirstPage)
+			}
+		}
+	}
+	return labels
+}
+
+// pageLabelRanges reads the PageLabels number tree of the catalog
+func pageLabelRanges(ctx *model.Context) ([]models.PageLabelRange, error) {
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	if catalog["PageLabels"] == nil {
+		return nil, nil
+	}
+
+	var ranges []models.PageLabelRange
+	var walk func(o types.Object, depth int)
+	walk = func(o types.Object, depth int) {
+		node, err := ctx.DereferenceDict(o)
+		if err != nil || node == nil || depth > 32 {
+			return
+		}
+		if kids, err := ctx.DereferenceArray(node["Kids"]); err == nil {
+			for _, kid := range kids {
+				walk(kid, depth+1)
+			}
+		}
+		nums, err := ctx.DereferenceArray(node["Nums"])
+		if err != nil {
+			return
+		}
+		for i := 0; i+1 < len(nums); i += 2 {
+			index, err := ctx.DereferenceInteger(nums[i])
+			if err != nil || index == nil || index.Value() < 0 || index.Value() >= ctx.PageCount {
+				continue
+			}
+			label, err := ctx.DereferenceDict(nums[i+1])
+			if err != nil || label == nil {
+				continue
+			}
+			r := models.PageLabelRange{FirstPage: index.Value() + 1, Start: 1}
+			if st := label.NameEntry("S"); st != nil {
+				r.Style = models.PageLabelStyle(*st)
+			}
+			r.Prefix = annotationText(ctx.XRefTable, label, "P")
+			if start, err := ctx.DereferenceInteger(label["St"]); err == nil && start != nil && start.Value() > 0 {
+				r.Start = start.Value()
+			}
+			ranges = append(ranges, r)
+		}
+	}
+	walk(catalog["PageLabels"], 0)
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].FirstPage < ranges[j].FirstPage })
+	return ranges, nil
+}
+
+// labelNumber writes n in a page label style
+func labelNumber(style models.PageLabelStyle, n int) string {
+	switch style {
+	case models.LabelDecimal:
+		return fmt.Sprint(n)
+	case models.LabelRomanUpper:
+		return romanNumeral(n)
+	case models.LabelRomanLower:
+		return strings.ToLower(romanNumeral(n))
+	case models.LabelLettersUpper:
+		return letterNumber(n)
+	case models.LabelLettersLower:
+		return strings.ToLower(letterNumber(n))
+	}
+	return ""
+}
+
+// romanNumeral writes n in upper case roman numerals, with as many M as
+// needed for thousands
+func romanNumeral(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	var b strings.Builder
+	for i, v := range values {
+		for n >= v {
+			b.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return b.String()
+}
+
+// letterNumber writes n as A to Z, then AA to ZZ, AAA to ZZZ and so on
+func letterNumber(n int) string {
+	if n < 1 {
+		return ""
+	}
+	letter := string(rune('A' + (n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
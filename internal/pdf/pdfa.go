@@ -0,0 +1,972 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// pdfaForbiddenActions are the action types PDF/A-2 does not allow
+var pdfaForbiddenActions = map[string]bool{
+	"Launch": true, "Sound": true, "Movie": true, "ResetForm": true, "ImportData": true,
+	"Hide": true, "SetOCGState": true, "Rendition": true, "Trans": true, "GoTo3DView": true,
+	"JavaScript": true,
+}
+
+// pdfaNamedActions are the named actions PDF/A-2 allows
+var pdfaNamedActions = map[string]bool{"NextPage": true, "PrevPage": true, "FirstPage": true, "LastPage": true}
+
+// pdfaBlendModes are the blend modes defined by ISO 32000-1
+var pdfaBlendModes = map[string]bool{
+	"Normal": true, "Compatible": true, "Multiply": true, "Screen": true, "Overlay": true,
+	"Darken": true, "Lighten": true, "ColorDodge": true, "ColorBurn": true, "HardLight": true,
+	"SoftLight": true, "Difference": true, "Exclusion": true, "Hue": true, "Saturation": true,
+	"Color": true, "Luminosity": true,
+}
+
+var (
+	xmpPart        = regexp.MustCompile(`pdfaid:part\s*=\s*["'](\d+)["']|<pdfaid:part>\s*(\d+)\s*</pdfaid:part>`)
+	xmpConformance = regexp.MustCompile(`pdfaid:conformance\s*=\s*["']([A-Za-z])["']|<pdfaid:conformance>\s*([A-Za-z])\s*</pdfaid:conformance>`)
+)
+
+// CheckPDFA checks a PDF against the PDF/A-2b requirements on fonts,
+// encryption, XMP metadata, output intents, transparency and actions
+func (s *Service) CheckPDFA(filePath string) (*models.PDFAReport, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+	ctx, err := api.ReadContextFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	violations, err := checkPDFA(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PDFAReport{File: filePath, Violations: violations}, nil
+}
+
+// pdfaChecker collects the violations of one document
+type pdfaChecker struct {
+	ctx        *model.Context
+	violations []models.PDFAViolation
+	fonts      map[int]bool // font objects already checked
+	gstates    map[int]bool // graphics states already checked
+	intent     int          // components of the PDF/A output profile, 0 without one
+}
+
+func (c *pdfaChecker) add(rule string, page, obj int, format string, args ...interface{}) {
+	c.violations = append(c.violations, models.PDFAViolation{
+		Rule: rule, Page: page, Object: obj, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func checkPDFA(ctx *model.Context) ([]models.PDFAViolation, error) {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	c := &pdfaChecker{ctx: ctx, fonts: map[int]bool{}, gstates: map[int]bool{}}
+
+	if ctx.Encrypt != nil {
+		c.add("encryption", 0, ctx.Encrypt.ObjectNumber.Value(), "the document is encrypted")
+	}
+	c.metadata(catalog)
+	c.outputIntents(catalog)
+	c.documentActions(catalog)
+
+	for page := 1; page <= ctx.PageCount; page++ {
+		if err := c.page(page); err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+	}
+	return c.violations, nil
+}
+
+// metadata checks the XMP metadata stream and its PDF/A identification
+func (c *pdfaChecker) metadata(catalog types.Dict) {
+	ref, _ := catalog["Metadata"].(types.IndirectRef)
+	sd, _, err := c.ctx.DereferenceStreamDict(catalog["Metadata"])
+	if err != nil || sd == nil {
+		c.add("metadata", 0, 0, "the document has no XMP metadata stream")
+		return
+	}
+	obj := ref.ObjectNumber.Value()
+	if sd.Dict["Filter"] != nil {
+		c.add("metadata", 0, obj, "the XMP metadata stream is compressed")
+	}
+	if err := sd.Decode(); err != nil {
+		c.add("metadata", 0, obj, "the XMP metadata stream cannot be read: %v", err)
+		return
+	}
+	part, conformance := "", ""
+	if m := xmpPart.FindSubmatch(sd.Content); m != nil {
+		part = string(m[1]) + string(m[2])
+	}
+	if m := xmpConformance.FindSubmatch(sd.Content); m != nil {
+		conformance = strings.ToUpper(string(m[1]) + string(m[2]))
+	}
+	switch {
+	case part == "":
+		c.add("metadata", 0, obj, "the XMP metadata has no PDF/A identification")
+	case part != "2" || !strings.Contains("ABU", conformance) || conformance == "":
+		c.add("metadata", 0, obj, "the XMP metadata identifies the document as PDF/A-%s%s, not PDF/A-2b", part, strings.ToLower(conformance))
+	}
+}
+
+// outputIntents checks the PDF/A output intents and records the number of
+// colour components of their profile
+func (c *pdfaChecker) outputIntents(catalog types.Dict) {
+	intents, err := c.ctx.DereferenceArray(catalog["OutputIntents"])
+	if err != nil {
+		return
+	}
+	var profile *types.IndirectRef
+	for _, o := range intents {
+		intent, err := c.ctx.DereferenceDict(o)
+		if err != nil || intent == nil {
+			continue
+		}
+		if st := intent.NameEntry("S"); st == nil || *st != "GTS_PDFA1" {
+			continue
+		}
+		obj := 0
+		if ref, ok := o.(types.IndirectRef); ok {
+			obj = ref.ObjectNumber.Value()
+		}
+		ref, ok := intent["DestOutputProfile"].(types.IndirectRef)
+		sd, _, err := c.ctx.DereferenceStreamDict(intent["DestOutputProfile"])
+		if !ok || err != nil || sd == nil {
+			c.add("output-intent", 0, obj, "the PDF/A output intent has no destination profile")
+			continue
+		}
+		if profile != nil && *profile != ref {
+			c.add("output-intent", 0, obj, "the PDF/A output intents use different profiles")
+			continue
+		}
+		profile = &ref
+		if n := sd.IntEntry("N"); n != nil {
+			c.intent = *n
+		}
+	}
+}
+
+// documentActions checks the actions run by the catalog and form fields
+func (c *pdfaChecker) documentActions(catalog types.Dict) {
+	if names, err := c.ctx.DereferenceDict(catalog["Names"]); err == nil && names != nil && names["JavaScript"] != nil {
+		c.add("actions", 0, 0, "the document has document-level JavaScript")
+	}
+	if catalog["AA"] != nil {
+		c.add("actions", 0, 0, "the document has additional actions")
+	}
+	if msg := c.action(catalog["OpenAction"], 0); msg != "" {
+		c.add("actions", 0, 0, "the open action %s", msg)
+	}
+	if form, err := c.ctx.DereferenceDict(catalog["AcroForm"]); err == nil && form != nil {
+		c.fieldActions(form["Fields"], 0)
+	}
+}
+
+func (c *pdfaChecker) fieldActions(o types.Object, depth int) {
+	fields, err := c.ctx.DereferenceArray(o)
+	if err != nil || depth > 32 {
+		return
+	}
+	for _, f := range fields {
+		field, err := c.ctx.DereferenceDict(f)
+		if err != nil || field == nil {
+			continue
+		}
+		// Widgets are checked with the annotations of their page
+		if field["AA"] != nil && field["Subtype"] == nil {
+			obj := 0
+			if ref, ok := f.(types.IndirectRef); ok {
+				obj = ref.ObjectNumber.Value()
+			}
+			c.add("actions", 0, obj, "form field %q has additional actions", annotationText(c.ctx.XRefTable, field, "T"))
+		}
+		c.fieldActions(field["Kids"], depth+1)
+	}
+}
+
+// action describes why an action, or one it chains to, is not allowed. It
+// returns "" for an allowed action.
+func (c *pdfaChecker) action(o types.Object, depth int) string {
+	if o == nil || depth > 32 {
+		return ""
+	}
+	obj, err := c.ctx.Dereference(o)
+	if err != nil {
+		return ""
+	}
+	if arr, ok := obj.(types.Array); ok {
+		for _, next := range arr {
+			if msg := c.action(next, depth+1); msg != "" {
+				return msg
+			}
+		}
+		return ""
+	}
+	d, ok := obj.(types.Dict)
+	if !ok {
+		// A destination rather than an action
+		return ""
+	}
+	if st := d.NameEntry("S"); st != nil {
+		if pdfaForbiddenActions[*st] {
+			return fmt.Sprintf("is a %s action", *st)
+		}
+		if *st == "Named" {
+			if n := d.NameEntry("N"); n == nil || !pdfaNamedActions[*n] {
+				return "is a named action other than NextPage, PrevPage, FirstPage or LastPage"
+			}
+		}
+	}
+	return c.action(d["Next"], depth+1)
+}
+
+// page checks the fonts, colours, transparency and actions of a page
+func (c *pdfaChecker) page(page int) error {
+	d, _, _, err := c.ctx.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	if d["AA"] != nil {
+		c.add("actions", page, 0, "the page has additional actions")
+	}
+	if annots, err := c.ctx.DereferenceArray(d["Annots"]); err == nil {
+		for _, o := range annots {
+			annot, err := c.ctx.DereferenceDict(o)
+			if err != nil || annot == nil {
+				continue
+			}
+			obj := 0
+			if ref, ok := o.(types.IndirectRef); ok {
+				obj = ref.ObjectNumber.Value()
+			}
+			if annot["AA"] != nil {
+				c.add("actions", page, obj, "a %s annotation has additional actions", annotationSubtype(annot))
+			}
+			if msg := c.action(annot["A"], 0); msg != "" {
+				c.add("actions", page, obj, "the action of a %s annotation %s", annotationSubtype(annot), msg)
+			}
+		}
+	}
+
+	transparency := false
+	if group, err := c.ctx.DereferenceDict(d["Group"]); err == nil && group != nil {
+		if st := group.NameEntry("S"); st != nil && *st == "Transparency" {
+			transparency = true
+		}
+	}
+	device := map[string]bool{}
+	err = walkPageResources(c.ctx, page, func(content []byte, res types.Dict, _ *types.IndirectRef) {
+		used := map[string]bool{}
+		if c.resources(page, res, used) {
+			transparency = true
+		}
+		if content != nil {
+			c.contentColours(content, res, used)
+		}
+		// Default colour spaces make device colour device independent
+		spaces, _ := c.ctx.DereferenceDict(res["ColorSpace"])
+		for family := range used {
+			if spaces == nil || spaces["Default"+strings.TrimPrefix(family, "Device")] == nil {
+				device[family] = true
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, family := range []string{"DeviceGray", "DeviceRGB", "DeviceCMYK"} {
+		if !device[family] {
+			continue
+		}
+		switch {
+		case c.intent == 0:
+			c.add("output-intent", page, 0, "%s colour is used without a PDF/A output intent", family)
+		case family == "DeviceRGB" && c.intent != 3:
+			c.add("output-intent", page, 0, "DeviceRGB colour is used but the output intent is not RGB")
+		case family == "DeviceCMYK" && c.intent != 4:
+			c.add("output-intent", page, 0, "DeviceCMYK colour is used but the output intent is not CMYK")
+		}
+	}
+
+	if transparency && c.intent == 0 {
+		group, _ := c.ctx.DereferenceDict(d["Group"])
+		if group == nil || group["CS"] == nil {
+			c.add("transparency", page, 0, "the page uses transparency without a blending colour space or an output intent")
+		}
+	}
+	return nil
+}
+
+// resources checks the fonts and graphics states of a resource dictionary
+// and adds the device colour spaces its images and shadings use. It reports
+// whether the resources use transparency.
+func (c *pdfaChecker) resources(page int, res types.Dict, used map[string]bool) bool {
+	if res == nil {
+		return false
+	}
+	transparency := false
+
+	fonts, _ := c.ctx.DereferenceDict(res["Font"])
+	for _, name := range sortedKeys(fonts) {
+		ref, ok := fonts[name].(types.IndirectRef)
+		if ok {
+			if c.fonts[ref.ObjectNumber.Value()] {
+				continue
+			}
+			c.fonts[ref.ObjectNumber.Value()] = true
+		}
+		font, err := c.ctx.DereferenceDict(fonts[name])
+		if err != nil || font == nil {
+			continue
+		}
+		if !fontEmbedded(c.ctx.XRefTable, font) {
+			c.add("fonts", page, ref.ObjectNumber.Value(), "font %s is not embedded", fontName(font, name))
+		}
+	}
+
+	gstates, _ := c.ctx.DereferenceDict(res["ExtGState"])
+	for _, name := range sortedKeys(gstates) {
+		gs, err := c.ctx.DereferenceDict(gstates[name])
+		if err != nil || gs == nil {
+			continue
+		}
+		if graphicsStateTransparent(c.ctx.XRefTable, gs) {
+			transparency = true
+		}
+		ref, ok := gstates[name].(types.IndirectRef)
+		if ok {
+			if c.gstates[ref.ObjectNumber.Value()] {
+				continue
+			}
+			c.gstates[ref.ObjectNumber.Value()] = true
+		}
+		for _, bm := range blendModes(c.ctx.XRefTable, gs["BM"]) {
+			if !pdfaBlendModes[bm] {
+				c.add("transparency", page, ref.ObjectNumber.Value(), "graphics state %s uses the non-standard blend mode %s", name, bm)
+			}
+		}
+		if gs["TR"] != nil {
+			c.add("transparency", page, ref.ObjectNumber.Value(), "graphics state %s has a transfer function", name)
+		}
+	}
+
+	xobjs, _ := c.ctx.DereferenceDict(res["XObject"])
+	for _, name := range sortedKeys(xobjs) {
+		sd, _, err := c.ctx.DereferenceStreamDict(xobjs[name])
+		if err != nil || sd == nil {
+			continue
+		}
+		if st := sd.Subtype(); st != nil && *st == "Form" {
+			if group, err := c.ctx.DereferenceDict(sd.Dict["Group"]); err == nil && group != nil {
+				if st := group.NameEntry("S"); st != nil && *st == "Transparency" {
+					transparency = true
+				}
+			}
+			continue
+		}
+		if sd.Dict["SMask"] != nil {
+			transparency = true
+		}
+		if n := sd.IntEntry("SMaskInData"); n != nil && *n > 0 {
+			transparency = true
+		}
+		c.colourSpace(sd.Dict["ColorSpace"], res, used, 0)
+	}
+
+	shadings, _ := c.ctx.DereferenceDict(res["Shading"])
+	for _, name := range sortedKeys(shadings) {
+		c.shadingColours(shadings[name], res, used)
+	}
+	patterns, _ := c.ctx.DereferenceDict(res["Pattern"])
+	for _, name := range sortedKeys(patterns) {
+		if pattern, err := c.ctx.DereferenceDict(patterns[name]); err == nil && pattern != nil {
+			c.shadingColours(pattern["Shading"], res, used)
+		}
+	}
+	return transparency
+}
+
+func (c *pdfaChecker) shadingColours(o types.Object, res types.Dict, used map[string]bool) {
+	obj, err := c.ctx.Dereference(o)
+	if err != nil || obj == nil {
+		return
+	}
+	switch sh := obj.(type) {
+	case types.Dict:
+		c.colourSpace(sh["ColorSpace"], res, used, 0)
+	case types.StreamDict:
+		c.colourSpace(sh.Dict["ColorSpace"], res, used, 0)
+	}
+}
+
+// contentColours adds the device colour spaces a content stream paints with
+func (c *pdfaChecker) contentColours(content []byte, res types.Dict, used map[string]bool) {
+	ops, err := parseContent(content)
+	if err != nil {
+		return
+	}
+	for _, op := range ops {
+		switch op.Operator {
+		case "g", "G":
+			used["DeviceGray"] = true
+		case "rg", "RG":
+			used["DeviceRGB"] = true
+		case "k", "K":
+			used["DeviceCMYK"] = true
+		case "cs", "CS":
+			if len(op.Operands) == 1 {
+				c.colourSpace(op.Operands[0], res, used, 0)
+			}
+		case "BI":
+			d, _ := op.Operands[0].(types.Dict)
+			if d == nil || d["IM"] == types.Boolean(true) || d["ImageMask"] == types.Boolean(true) {
+				continue
+			}
+			cs := d["CS"]
+			if cs == nil {
+				cs = d["ColorSpace"]
+			}
+			c.colourSpace(cs, res, used, 0)
+		}
+	}
+}
+
+// colourSpace adds the device colour spaces a colour space depends on. Names
+// other than the device families are looked up in the resources.
+func (c *pdfaChecker) colourSpace(o types.Object, res types.Dict, used map[string]bool, depth int) {
+	if o == nil || depth > 8 {
+		return
+	}
+	if name, ok := o.(types.Name); ok {
+		switch name {
+		case "DeviceGray", "G":
+			used["DeviceGray"] = true
+		case "DeviceRGB", "RGB":
+			used["DeviceRGB"] = true
+		case "DeviceCMYK", "CMYK":
+			used["DeviceCMYK"] = true
+		case "Pattern":
+		default:
+			if spaces, err := c.ctx.DereferenceDict(res["ColorSpace"]); err == nil && spaces != nil && spaces[string(name)] != nil {
+				c.colourSpace(spaces[string(name)], nil, used, depth+1)
+			}
+		}
+		return
+	}
+	obj, err := c.ctx.Dereference(o)
+	if err != nil {
+		return
+	}
+	switch cs := obj.(type) {
+	case types.Name:
+		c.colourSpace(cs, nil, used, depth+1)
+	case types.Array:
+		if len(cs) == 0 {
+			return
+		}
+		family, _ := cs[0].(types.Name)
+		switch family {
+		case "Indexed", "I", "Pattern":
+			if len(cs) > 1 {
+				c.colourSpace(cs[1], nil, used, depth+1)
+			}
+		case "Separation", "DeviceN":
+			if len(cs) > 2 {
+				c.colourSpace(cs[2], nil, used, depth+1)
+			}
+		}
+	}
+}
+
+// fontEmbedded reports whether the program of a font is embedded. Type 3
+// glyphs are drawn by the document itself.
+func fontEmbedded(xRefTable *model.XRefTable, font types.Dict) bool {
+	subtype := ""
+	if st := font.NameEntry("Subtype"); st != nil {
+		subtype = *st
+	}
+	if subtype == "Type3" {
+		return true
+	}
+	if subtype == "Type0" {
+		descendants, err := xRefTable.DereferenceArray(font["DescendantFonts"])
+		if err != nil || len(descendants) == 0 {
+			return false
+		}
+		if font, err = xRefTable.DereferenceDict(descendants[0]); err != nil || font == nil {
+			return false
+		}
+	}
+	desc, err := xRefTable.DereferenceDict(font["FontDescriptor"])
+	if err != nil || desc == nil {
+		return false
+	}
+	return desc["FontFile"] != nil || desc["FontFile2"] != nil || desc["FontFile3"] != nil
+}
+
+// fontName returns the BaseFont of a font, or its resource name
+func fontName(font types.Dict, name string) string {
+	if base := font.NameEntry("BaseFont"); base != nil {
+		return *base
+	}
+	return name
+}
+
+// graphicsStateTransparent reports whether a graphics state paints with
+// transparency
+func graphicsStateTransparent(xRefTable *model.XRefTable, gs types.Dict) bool {
+	for _, key := range []string{"CA", "ca"} {
+		if o, err := xRefTable.Dereference(gs[key]); err == nil && o != nil && number(o) < 1 {
+			return true
+		}
+	}
+	if o, err := xRefTable.Dereference(gs["SMask"]); err == nil && o != nil && o != types.Name("None") {
+		return true
+	}
+	for _, bm := range blendModes(xRefTable, gs["BM"]) {
+		if bm != "Normal" && bm != "Compatible" {
+			return true
+		}
+	}
+	return false
+}
+
+// blendModes returns the blend mode names of a BM entry, a name or an array
+func blendModes(xRefTable *model.XRefTable, o types.Object) []string {
+	obj, err := xRefTable.Dereference(o)
+	if err != nil || obj == nil {
+		return nil
+	}
+	switch bm := obj.(type) {
+	case types.Name:
+		return []string{string(bm)}
+	case types.Array:
+		var modes []string
+		for _, m := range bm {
+			if name, ok := m.(types.Name); ok {
+				modes = append(modes, string(name))
+			}
+		}
+		return modes
+	}
+	return nil
+}
+
+// ConvertToPDFA writes a copy of a PDF converted towards PDF/A-2b. It
+// removes encryption, JavaScript and forbidden actions, replaces
+// non-standard blend modes, adds an sRGB output intent when there is none
+// and adds XMP metadata matching the document information. Fonts cannot be
+// embedded, so documents using fonts that are not embedded stay
+// non-conforming; the remaining violations are reported.
+func (s *Service) ConvertToPDFA(inputFile, outputFile string) (*models.PDFAConversion, error) {
+	if !utils.IsPDF(inputFile) {
+		return nil, fmt.Errorf("input file must be a PDF")
+	}
+	ctx, err := api.ReadContextFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	result := &models.PDFAConversion{}
+	change := func(format string, args ...interface{}) {
+		result.Changes = append(result.Changes, fmt.Sprintf(format, args...))
+	}
+
+	if ctx.Encrypt != nil {
+		// Written without encryption, as when decrypting
+		ctx.Cmd = model.DECRYPT
+		change("removed the encryption")
+	}
+	if n := removePDFAActions(ctx, catalog); n > 0 {
+		change("removed %d JavaScript or forbidden actions", n)
+	}
+	if n := fixGraphicsStates(ctx); n > 0 {
+		change("fixed %d graphics states with non-standard blend modes or transfer functions", n)
+	}
+
+	c := &pdfaChecker{ctx: ctx}
+	c.outputIntents(catalog)
+	if c.intent == 0 {
+		if err := addOutputIntent(ctx, catalog); err != nil {
+			return nil, err
+		}
+		change("added an sRGB output intent")
+	}
+	// The metadata is written once the final document information is known
+	delete(catalog, "Metadata")
+
+	if err := validateContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to convert PDF: %w", err)
+	}
+	if err := api.WriteContextFile(ctx, outputFile); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+	if err := addXMPMetadata(outputFile); err != nil {
+		return nil, err
+	}
+	change("added PDF/A-2b XMP metadata")
+
+	report, err := s.CheckPDFA(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	result.Remaining = report.Violations
+	return result, nil
+}
+
+// removePDFAActions removes document JavaScript, additional actions and
+// actions PDF/A does not allow. It returns the number of actions removed.
+func removePDFAActions(ctx *model.Context, catalog types.Dict) int {
+	c := &pdfaChecker{ctx: ctx}
+	n := 0
+	if names, err := ctx.DereferenceDict(catalog["Names"]); err == nil && names != nil && names["JavaScript"] != nil {
+		delete(names, "JavaScript")
+		n++
+	}
+	for _, key := range []string{"AA", "OpenAction"} {
+		if catalog[key] != nil && (key == "AA" || c.action(catalog[key], 0) != "") {
+			delete(catalog, key)
+			n++
+		}
+	}
+	if form, err := ctx.DereferenceDict(catalog["AcroForm"]); err == nil && form != nil {
+		n += removeFieldActions(ctx, form["Fields"], 0)
+	}
+	for page := 1; page <= ctx.PageCount; page++ {
+		d, _, _, err := ctx.PageDict(page, false)
+		if err != nil {
+			continue
+		}
+		if d["AA"] != nil {
+			delete(d, "AA")
+			n++
+		}
+		annots, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil {
+			continue
+		}
+		for _, o := range annots {
+			annot, err := ctx.DereferenceDict(o)
+			if err != nil || annot == nil {
+				continue
+			}
+			if annot["AA"] != nil {
+				delete(annot, "AA")
+				n++
+			}
+			if c.action(annot["A"], 0) != "" {
+				delete(annot, "A")
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func removeFieldActions(ctx *model.Context, o types.Object, depth int) int {
+	fields, err := ctx.DereferenceArray(o)
+	if err != nil || depth > 32 {
+		return 0
+	}
+	n := 0
+	for _, f := range fields {
+		field, err := ctx.DereferenceDict(f)
+		if err != nil || field == nil {
+			continue
+		}
+		if field["AA"] != nil {
+			delete(field, "AA")
+			n++
+		}
+		n += removeFieldActions(ctx, field["Kids"], depth+1)
+	}
+	return n
+}
+
+// fixGraphicsStates replaces non-standard blend modes with Normal and
+// removes transfer functions. It returns the number of graphics states
+// changed.
+func fixGraphicsStates(ctx *model.Context) int {
+	fixed := map[int]bool{}
+	n := 0
+	for page := 1; page <= ctx.PageCount; page++ {
+		_ = walkPageResources(ctx, page, func(_ []byte, res types.Dict, _ *types.IndirectRef) {
+			gstates, _ := ctx.DereferenceDict(res["ExtGState"])
+			for _, name := range sortedKeys(gstates) {
+				ref, ok := gstates[name].(types.IndirectRef)
+				if ok && fixed[ref.ObjectNumber.Value()] {
+					continue
+				}
+				gs, err := ctx.DereferenceDict(gstates[name])
+				if err != nil || gs == nil {
+					continue
+				}
+				changed := false
+				for _, bm := range blendModes(ctx.XRefTable, gs["BM"]) {
+					if !pdfaBlendModes[bm] {
+						gs["BM"] = types.Name("Normal")
+						changed = true
+						break
+					}
+				}
+				if gs["TR"] != nil {
+					delete(gs, "TR")
+					changed = true
+				}
+				if changed {
+					n++
+					if ok {
+						fixed[ref.ObjectNumber.Value()] = true
+					}
+				}
+			}
+		})
+	}
+	return n
+}
+
+// addOutputIntent adds a PDF/A output intent with an sRGB profile
+func addOutputIntent(ctx *model.Context, catalog types.Dict) error {
+	profile, err := ctx.NewStreamDictForBuf(srgbProfile())
+	if err != nil {
+		return err
+	}
+	profile.Dict["N"] = types.Integer(3)
+	if err := profile.Encode(); err != nil {
+		return err
+	}
+	profileRef, err := ctx.IndRefForNewObject(*profile)
+	if err != nil {
+		return err
+	}
+	intentRef, err := ctx.IndRefForNewObject(types.Dict{
+		"Type":                      types.Name("OutputIntent"),
+		"S":                         types.Name("GTS_PDFA1"),
+		"OutputConditionIdentifier": types.StringLiteral("sRGB IEC61966-2.1"),
+		"Info":                      types.StringLiteral("sRGB IEC61966-2.1"),
+		"DestOutputProfile":         *profileRef,
+	})
+	if err != nil {
+		return err
+	}
+	intents, err := ctx.DereferenceArray(catalog["OutputIntents"])
+	if err != nil {
+		intents = nil
+	}
+	catalog["OutputIntents"] = append(append(types.Array(nil), intents...), *intentRef)
+	return nil
+}
+
+// addXMPMetadata appends XMP metadata identifying the document as PDF/A-2b
+// to a written file. It is added as an incremental update because writing
+// the document sets its modification date and producer, which the metadata
+// has to match.
+func addXMPMetadata(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	ctx, err := api.ReadContext(bytes.NewReader(data), model.NewDefaultConfiguration())
+	if err != nil {
+		return fmt.Errorf("failed to read converted PDF: %w", err)
+	}
+	prev, err := lastXRefOffset(data)
+	if err != nil {
+		return err
+	}
+	var info types.Dict
+	if ctx.Info != nil {
+		info, _ = ctx.DereferenceDict(*ctx.Info)
+	}
+
+	xmp := []byte(pdfaXMP(ctx.XRefTable, info))
+	sd := types.NewStreamDict(types.Dict{
+		"Type":    types.Name("Metadata"),
+		"Subtype": types.Name("XML"),
+	}, 0, nil, nil, nil)
+	sd.Content, sd.Raw = xmp, xmp
+
+	u := newIncrementalUpdate(ctx.XRefTable, ctx.Read.UsingXRefStreams)
+	ref, err := u.add(sd)
+	if err != nil {
+		return err
+	}
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	catalog["Metadata"] = *ref
+	u.touch(*ctx.Root)
+	out, _, _, err := u.write(data, prev, 0, 0)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, out, 0644)
+}
+
+// pdfaXMP builds an XMP packet with the PDF/A identification and the
+// document information
+func pdfaXMP(xRefTable *model.XRefTable, info types.Dict) string {
+	text := func(key string) string {
+		if info == nil {
+			return ""
+		}
+		var b strings.Builder
+		_ = xml.EscapeText(&b, []byte(annotationText(xRefTable, info, key)))
+		return b.String()
+	}
+	date := func(key string) string {
+		if info == nil {
+			return ""
+		}
+		t, ok := types.DateTime(annotationText(xRefTable, info, key), true)
+		if !ok {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	var b strings.Builder
+	b.WriteString("<?xpacket begin=\"\uFEFF\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString("<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	b.WriteString("<rdf:Description rdf:about=\"\"")
+	b.WriteString(" xmlns:pdfaid=\"http://www.aiim.org/pdfa/ns/id/\"")
+	b.WriteString(" xmlns:dc=\"http://purl.org/dc/elements/1.1/\"")
+	b.WriteString(" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\"")
+	b.WriteString(" xmlns:pdf=\"http://ns.adobe.com/pdf/1.3/\">\n")
+	b.WriteString("<pdfaid:part>2</pdfaid:part>\n")
+	b.WriteString("<pdfaid:conformance>B</pdfaid:conformance>\n")
+	if v := text("Title"); v != "" {
+		fmt.Fprintf(&b, "<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:title>\n", v)
+	}
+	if v := text("Author"); v != "" {
+		fmt.Fprintf(&b, "<dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>\n", v)
+	}
+	if v := text("Subject"); v != "" {
+		fmt.Fprintf(&b, "<dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:description>\n", v)
+	}
+	if v := text("Keywords"); v != "" {
+		fmt.Fprintf(&b, "<pdf:Keywords>%s</pdf:Keywords>\n", v)
+	}
+	if v := text("Creator"); v != "" {
+		fmt.Fprintf(&b, "<xmp:CreatorTool>%s</xmp:CreatorTool>\n", v)
+	}
+	if v := text("Producer"); v != "" {
+		fmt.Fprintf(&b, "<pdf:Producer>%s</pdf:Producer>\n", v)
+	}
+	if v := date("CreationDate"); v != "" {
+		fmt.Fprintf(&b, "<xmp:CreateDate>%s</xmp:CreateDate>\n", v)
+	}
+	if v := date("ModDate"); v != "" {
+		fmt.Fprintf(&b, "<xmp:ModifyDate>%s</xmp:ModifyDate>\n", v)
+		fmt.Fprintf(&b, "<xmp:MetadataDate>%s</xmp:MetadataDate>\n", v)
+	}
+	b.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n")
+	// Padding lets editors update the packet in place
+	for i := 0; i < 20; i++ {
+		b.WriteString(strings.Repeat(" ", 99) + "\n")
+	}
+	b.WriteString("<?xpacket end=\"w\"?>")
+	return b.String()
+}
+
+// srgbProfile builds an ICC version 2 display profile for sRGB from its
+// primaries and white point, with a gamma 2.2 tone curve
+func srgbProfile() []byte {
+	s15Fixed16 := func(b []byte, v float64) []byte {
+		return binary.BigEndian.AppendUint32(b, uint32(int32(math.Round(v*65536))))
+	}
+	xyz := func(x, y, z float64) []byte {
+		b := append([]byte("XYZ "), 0, 0, 0, 0)
+		return s15Fixed16(s15Fixed16(s15Fixed16(b, x), y), z)
+	}
+	const description = "sRGB IEC61966-2.1"
+	desc := append([]byte("desc"), 0, 0, 0, 0)
+	desc = binary.BigEndian.AppendUint32(desc, uint32(len(description)+1))
+	desc = append(append(desc, description...), 0)
+	// Empty Unicode and ScriptCode descriptions
+	desc = append(desc, make([]byte, 4+4+2+1+67)...)
+	cprt := append(append([]byte("text"), 0, 0, 0, 0), "No copyright, use freely\x00"...)
+	// A single gamma value of 2.2 as u8Fixed8Number
+	curve := append([]byte("curv"), 0, 0, 0, 0, 0, 0, 0, 1, 0x02, 0x33)
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", desc},
+		{"cprt", cprt},
+		{"wtpt", xyz(0.9642, 1, 0.8249)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	// Tag data follows the header and the tag table, 4-byte aligned, with
+	// identical data shared between tags
+	var table, body []byte
+	offsets := map[string]int{}
+	start := 128 + 4 + 12*len(tags)
+	for _, t := range tags {
+		at, ok := offsets[string(t.data)]
+		if !ok {
+			at = start + len(body)
+			offsets[string(t.data)] = at
+			body = append(body, t.data...)
+			for len(body)%4 != 0 {
+				body = append(body, 0)
+			}
+		}
+		table = append(table, t.sig...)
+		table = binary.BigEndian.AppendUint32(table, uint32(at))
+		table = binary.BigEndian.AppendUint32(table, uint32(len(t.data)))
+	}
+
+	header := make([]byte, 0, 128)
+	header = binary.BigEndian.AppendUint32(header, uint32(start+len(body)))
+	header = append(header, 0, 0, 0, 0)               // preferred CMM
+	header = append(header, 0x02, 0x10, 0, 0)         // version 2.1
+	header = append(header, "mntrRGB XYZ "...)        // display class, RGB data, XYZ connection space
+	for _, v := range []uint16{2024, 1, 1, 0, 0, 0} { // creation date
+		header = binary.BigEndian.AppendUint16(header, v)
+	}
+	header = append(header, "acsp"...)
+	header = append(header, make([]byte, 4+4+4+4+8+4)...) // platform, flags, manufacturer, model, attributes, intent
+	header = s15Fixed16(s15Fixed16(s15Fixed16(header, 0.9642), 1), 0.8249)
+	header = append(header, make([]byte, 128-len(header))...)
+
+	profile := append(header, binary.BigEndian.AppendUint32(nil, uint32(len(tags)))...)
+	profile = append(profile, table...)
+	return append(profile, body...)
+}
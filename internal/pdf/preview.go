@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+)
+
+// PagePreview is a rough preview of a page. pdfcpu cannot rasterize page
+// content, so the largest image placed on the page stands in for it, which
+// covers scanned documents.
+type PagePreview struct {
+	Width  float64     // page width in points
+	Height float64     // page height in points
+	Image  image.Image // nil when the page has no decodable image
+}
+
+// PageThumbnail returns a preview of the given page
+func (s *Service) PageThumbnail(filePath string, page int) (*PagePreview, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	ctx, err := readOptimizedContext(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 || page > ctx.PageCount {
+		return nil, fmt.Errorf("page %d out of range (1-%d)", page, ctx.PageCount)
+	}
+
+	// Size the preview to the visible area of the page as displayed
+	pbs, err := ctx.PageBoundaries(types.IntSet{page: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %w", page, err)
+	}
+	pb := pbs[page-1]
+	dim := pb.CropBox().Dimensions()
+	if pb.Rot%180 != 0 {
+		dim.Width, dim.Height = dim.Height, dim.Width
+	}
+	preview := &PagePreview{Width: dim.Width, Height: dim.Height}
+
+	images, err := pdfcpu.ExtractPageImages(ctx, page, false)
+	if err != nil {
+		// Not being able to extract images only costs us the picture
+		return preview, nil
+	}
+
+	best := 0
+	for _, img := range images {
+		decoded, _, err := image.Decode(img)
+		if err != nil {
+			continue
+		}
+		if area := decoded.Bounds().Dx() * decoded.Bounds().Dy(); area > best {
+			best = area
+			preview.Image = decoded
+		}
+	}
+	return preview, nil
+}
@@ -0,0 +1,787 @@
+package pdf
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	_ "golang.org/x/image/tiff"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Redact permanently removes the text, images and annotations under the given
+// areas and under the text matching the given patterns, and paints the areas
+// black. The returned report lists every redacted area.
+func (s *Service) Redact(config models.RedactConfig) (*models.RedactionReport, error) {
+	if !utils.IsPDF(config.InputFile) {
+		return nil, fmt.Errorf("input file must be a PDF")
+	}
+	if len(config.Areas) == 0 && len(config.Patterns) == 0 {
+		return nil, fmt.Errorf("no areas or search patterns given")
+	}
+
+	var patterns []*regexp.Regexp
+	for _, p := range config.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, err := api.ReadContextFile(config.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	areas := map[int][]models.RedactArea{}
+	for _, a := range config.Areas {
+		if a.Page < 1 || a.Page > ctx.PageCount {
+			return nil, fmt.Errorf("page %d out of range (1-%d)", a.Page, ctx.PageCount)
+		}
+		areas[a.Page] = append(areas[a.Page], a)
+	}
+	searched := map[int]bool{}
+	if len(patterns) > 0 {
+		for _, page := range selectedPages(config.Pages, ctx.PageCount) {
+			searched[page] = true
+		}
+	}
+
+	report := &models.RedactionReport{}
+	r := &redactor{ctx: ctx, xRefTable: ctx.XRefTable, fonts: map[types.IndirectRef]*fontInfo{}, report: report}
+	for page := 1; page <= ctx.PageCount; page++ {
+		if len(areas[page]) == 0 && !searched[page] {
+			continue
+		}
+		var pagePatterns []*regexp.Regexp
+		if searched[page] {
+			pagePatterns = patterns
+		}
+		if err := r.redactPage(page, areas[page], pagePatterns); err != nil {
+			return nil, fmt.Errorf("failed to redact page %d: %w", page, err)
+		}
+	}
+
+	if err := api.WriteContextFile(ctx, config.OutputFile); err != nil {
+		return nil, err
+	}
+
+	if config.ReportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(config.ReportFile, append(data, '\n'), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// placedGlyph is a glyph with where it is drawn, in default user space
+type placedGlyph struct {
+	glyph
+	box  bbox
+	size float64 // font size as drawn
+}
+
+// redactor removes content under rects from content streams. While rects
+// is empty it only collects the glyphs it walks over.
+type redactor struct {
+	ctx       *model.Context
+	xRefTable *model.XRefTable
+	fonts     map[types.IndirectRef]*fontInfo
+	rects     []bbox
+	glyphs    []placedGlyph
+	report    *models.RedactionReport
+}
+
+// redactScope holds the resources of the content stream being redacted
+type redactScope struct {
+	res      types.Dict
+	writable func() (types.Dict, error) // makes an XObject dictionary that may get new entries
+	xObjects types.Dict
+	used     map[string]bool // XObjects still drawn unchanged
+	replaced map[string]bool // XObjects redacted or removed at least once
+}
+
+// add registers an XObject under a new name and returns the name
+func (sc *redactScope) add(ref types.IndirectRef) (string, error) {
+	if sc.xObjects == nil {
+		xo, err := sc.writable()
+		if err != nil {
+			return "", err
+		}
+		sc.xObjects = xo
+	}
+	name := uniqueResourceName(sc.xObjects, "Rd")
+	sc.xObjects[name] = ref
+	return name, nil
+}
+
+// prune drops the XObjects that are no longer drawn, so that the unredacted
+// originals are not written to the output
+func (sc *redactScope) prune() error {
+	for name := range sc.replaced {
+		if sc.used[name] {
+			continue
+		}
+		if sc.xObjects == nil {
+			xo, err := sc.writable()
+			if err != nil {
+				return err
+			}
+			sc.xObjects = xo
+		}
+		delete(sc.xObjects, name)
+	}
+	return nil
+}
+
+func (r *redactor) redactPage(page int, areas []models.RedactArea, patterns []*regexp.Regexp) error {
+	d, _, inh, err := r.xRefTable.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	var ops []contentOp
+	data, err := r.xRefTable.PageContent(d, page)
+	if err != nil && err != model.ErrNoContent {
+		return err
+	}
+	if err == nil {
+		if ops, err = parseContent(data); err != nil {
+			return err
+		}
+	}
+
+	// Areas are given as displayed; work in default user space
+	visible := inh.MediaBox
+	if inh.CropBox != nil {
+		visible = inh.CropBox
+	}
+	toDisplay := displayMatrix(visible, inh.Rotate)
+	toUser := toDisplay.invert()
+	displayRect := func(b bbox) [4]float64 {
+		var d bbox
+		d.addRect(toDisplay, b.x0, b.y0, b.x1, b.y1)
+		return [4]float64{d.x0, d.y0, d.x1, d.y1}
+	}
+
+	scope := func() *redactScope {
+		return &redactScope{res: inh.Resources, writable: func() (types.Dict, error) {
+			return pageXObjects(r.xRefTable, d, inh)
+		}}
+	}
+
+	// First pass: find the text on the page
+	r.rects, r.glyphs = nil, nil
+	if _, _, err := r.process(ops, scope(), identity, 0); err != nil {
+		return err
+	}
+
+	var redactions []models.Redaction
+	for _, a := range areas {
+		var b bbox
+		b.addRect(toUser, a.Rect[0], a.Rect[1], a.Rect[2], a.Rect[3])
+		r.rects = append(r.rects, b)
+		redactions = append(redactions, models.Redaction{Page: page, Rect: displayRect(b)})
+	}
+	text, index := pageText(r.glyphs)
+	for i, re := range patterns {
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			for _, b := range r.matchRects(index[m[0]:m[1]]) {
+				r.rects = append(r.rects, b)
+				redactions = append(redactions, models.Redaction{
+					Page:    page,
+					Rect:    displayRect(b),
+					Pattern: patterns[i].String(),
+				})
+			}
+		}
+	}
+	if len(r.rects) == 0 {
+		return nil
+	}
+	r.report.Redactions = append(r.report.Redactions, redactions...)
+
+	// Second pass: remove what lies under the rectangles
+	out, changed, err := r.process(ops, scope(), identity, 0)
+	if err != nil {
+		return err
+	}
+	if changed {
+		ref, err := newContentStream(r.xRefTable, writeContent(out))
+		if err != nil {
+			return err
+		}
+		d["Contents"] = *ref
+	}
+
+	if err := r.removeAnnotations(d); err != nil {
+		return err
+	}
+
+	var boxes strings.Builder
+	boxes.WriteString("q 0 g\n")
+	for _, b := range r.rects {
+		fmt.Fprintf(&boxes, "%s %s %s %s re f\n",
+			formatNumber(b.x0), formatNumber(b.y0), formatNumber(b.x1-b.x0), formatNumber(b.y1-b.y0))
+	}
+	boxes.WriteString("Q")
+	return wrapPageContent(r.xRefTable, d, "q", "Q\n"+boxes.String())
+}
+
+// displayMatrix maps default user space to the visible area as displayed,
+// with the origin at its bottom-left corner
+func displayMatrix(visible *types.Rectangle, rotate int) matrix {
+	w, h := visible.Width(), visible.Height()
+	m := matrix{1, 0, 0, 1, -visible.LL.X, -visible.LL.Y}
+	switch ((rotate % 360) + 360) % 360 {
+	case 90:
+		return m.mul(matrix{0, -1, 1, 0, 0, w})
+	case 180:
+		return m.mul(matrix{-1, 0, 0, -1, w, h})
+	case 270:
+		return m.mul(matrix{0, 1, -1, 0, h, 0})
+	}
+	return m
+}
+
+// covered reports whether b touches a redaction rectangle, so that glyphs
+// and pixels partly under a rectangle are removed too. Boxes that only meet
+// a rectangle edge, such as the glyph after a matched word, are not covered.
+func (r *redactor) covered(b bbox) bool {
+	const eps = 1e-6
+	touched, _ := r.overlaps(bbox{b.x0 + eps, b.y0 + eps, b.x1 - eps, b.y1 - eps, true})
+	return touched
+}
+
+// overlaps reports whether b touches a redaction rectangle, and whether a
+// single rectangle contains it completely
+func (r *redactor) overlaps(b bbox) (touched, inside bool) {
+	for _, rect := range r.rects {
+		if b.x0 < rect.x1 && b.x1 > rect.x0 && b.y0 < rect.y1 && b.y1 > rect.y0 {
+			touched = true
+			if b.x0 >= rect.x0 && b.x1 <= rect.x1 && b.y0 >= rect.y0 && b.y1 <= rect.y1 {
+				return true, true
+			}
+		}
+	}
+	return touched, false
+}
+
+// matchRects returns one rectangle per line around the glyphs of a match
+func (r *redactor) matchRects(index []int) []bbox {
+	var rects []bbox
+	var line bbox
+	lastY, lastSize := 0.0, 0.0
+	seen := map[int]bool{}
+	for _, i := range index {
+		if i < 0 || seen[i] {
+			continue
+		}
+		seen[i] = true
+		g := r.glyphs[i]
+		if strings.TrimSpace(g.text) == "" {
+			continue
+		}
+		if line.ok && math.Abs(g.box.y0-lastY) > 0.5*lastSize {
+			rects = append(rects, line)
+			line = bbox{}
+		}
+		line.union(g.box)
+		lastY, lastSize = g.box.y0, g.size
+	}
+	if line.ok {
+		rects = append(rects, line)
+	}
+	return rects
+}
+
+// pageText joins glyphs into searchable text, adding spaces at gaps and line
+// breaks between lines. index maps each byte of the text to its glyph, or -1.
+func pageText(glyphs []placedGlyph) (string, []int) {
+	var b strings.Builder
+	var index []int
+	for i, g := range glyphs {
+		if i > 0 {
+			prev := glyphs[i-1]
+			sep := ""
+			switch {
+			case math.Abs(g.box.y0-prev.box.y0) > 0.5*math.Max(prev.size, g.size):
+				sep = "\n"
+			case g.box.x0-prev.box.x1 > 0.25*prev.size && !strings.HasSuffix(prev.text, " ") && !strings.HasPrefix(g.text, " "):
+				sep = " "
+			}
+			b.WriteString(sep)
+			for range sep {
+				index = append(index, -1)
+			}
+		}
+		b.WriteString(g.text)
+		for range g.text {
+			index = append(index, i)
+		}
+	}
+	// index is per byte; ranging over a string yields one entry per rune
+	text := b.String()
+	byteIndex := make([]int, 0, len(text))
+	j := 0
+	for _, c := range text {
+		n := len(string(c))
+		for k := 0; k < n; k++ {
+			byteIndex = append(byteIndex, index[j])
+		}
+		j++
+	}
+	return text, byteIndex
+}
+
+// font returns the decoder for the named font resource
+func (r *redactor) font(res types.Dict, name string) *fontInfo {
+	fonts, err := r.xRefTable.DereferenceDict(res["Font"])
+	if err != nil || fonts == nil {
+		return loadFont(r.xRefTable, nil)
+	}
+	ref, isRef := fonts[name].(types.IndirectRef)
+	if isRef {
+		if f, ok := r.fonts[ref]; ok {
+			return f
+		}
+	}
+	d, err := r.xRefTable.DereferenceDict(fonts[name])
+	if err != nil {
+		d = nil
+	}
+	f := loadFont(r.xRefTable, d)
+	if isRef {
+		r.fonts[ref] = f
+	}
+	return f
+}
+
+// process walks a content stream drawn with ctm. When redacting it returns
+// the stream without the covered content and whether anything changed.
+func (r *redactor) process(ops []contentOp, sc *redactScope, ctm matrix, depth int) ([]contentOp, bool, error) {
+	type state struct {
+		ctm  matrix
+		text textState
+		font *fontInfo
+	}
+	gs := state{ctm: ctm, text: textState{scale: 1}, font: loadFont(r.xRefTable, nil)}
+	var stack []state
+	sc.used, sc.replaced = map[string]bool{}, map[string]bool{}
+	redacting := len(r.rects) > 0
+	changed := false
+	out := make([]contentOp, 0, len(ops))
+
+	for _, op := range ops {
+		f := numbers(op.Operands)
+		switch op.Operator {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if n := len(stack); n > 0 {
+				gs, stack = stack[n-1], stack[:n-1]
+			}
+		case "cm":
+			gs.ctm = toMatrix(f).mul(gs.ctm)
+		case "BT":
+			gs.text.tm, gs.text.tlm = identity, identity
+		case "Tf":
+			if len(op.Operands) >= 2 {
+				if name, ok := op.Operands[0].(types.Name); ok {
+					gs.font = r.font(sc.res, string(name))
+				}
+				gs.text.size = f[1]
+			}
+		case "Tc":
+			if len(f) >= 1 {
+				gs.text.charSpace = f[0]
+			}
+		case "Tw":
+			if len(f) >= 1 {
+				gs.text.wordSpace = f[0]
+			}
+		case "Tz":
+			if len(f) >= 1 {
+				gs.text.scale = f[0] / 100
+			}
+		case "TL":
+			if len(f) >= 1 {
+				gs.text.leading = f[0]
+			}
+		case "Ts":
+			if len(f) >= 1 {
+				gs.text.rise = f[0]
+			}
+		case "Td", "TD":
+			if len(f) >= 2 {
+				if op.Operator == "TD" {
+					gs.text.leading = -f[1]
+				}
+				gs.text.tlm = matrix{1, 0, 0, 1, f[0], f[1]}.mul(gs.text.tlm)
+				gs.text.tm = gs.text.tlm
+			}
+		case "Tm":
+			gs.text.tlm = toMatrix(f)
+			gs.text.tm = gs.text.tlm
+		case "T*":
+			gs.text.tlm = matrix{1, 0, 0, 1, 0, -gs.text.leading}.mul(gs.text.tlm)
+			gs.text.tm = gs.text.tlm
+
+		case "Tj", "'", "\"", "TJ":
+			if len(op.Operands) == 0 {
+				break
+			}
+			var prefix []contentOp
+			if op.Operator == "\"" && len(f) >= 3 {
+				gs.text.wordSpace, gs.text.charSpace = f[0], f[1]
+				prefix = append(prefix,
+					contentOp{Operator: "Tw", Operands: op.Operands[:1]},
+					contentOp{Operator: "Tc", Operands: op.Operands[1:2]})
+			}
+			if op.Operator == "'" || op.Operator == "\"" {
+				gs.text.tlm = matrix{1, 0, 0, 1, 0, -gs.text.leading}.mul(gs.text.tlm)
+				gs.text.tm = gs.text.tlm
+				prefix = append(prefix, contentOp{Operator: "T*"})
+			}
+			shown, removed := r.showText(&gs.text, gs.font, gs.ctm, op.Operands[len(op.Operands)-1])
+			if removed {
+				changed = true
+				out = append(out, prefix...)
+				out = append(out, contentOp{Operator: "TJ", Operands: []types.Object{shown}})
+				continue
+			}
+
+		case "BI":
+			var b bbox
+			b.addRect(gs.ctm, 0, 0, 1, 1)
+			if touched, _ := r.overlaps(b); redacting && touched {
+				// Inline images are small; drop them whole
+				r.report.ImagesRemoved++
+				changed = true
+				continue
+			}
+
+		case "Do":
+			if len(op.Operands) != 1 {
+				break
+			}
+			name, ok := op.Operands[0].(types.Name)
+			if !ok {
+				break
+			}
+			replaced, drop, err := r.xObject(sc, string(name), gs.ctm, depth)
+			if err != nil {
+				return nil, false, err
+			}
+			if drop || replaced != "" {
+				changed = true
+				sc.replaced[string(name)] = true
+				if replaced != "" {
+					out = append(out, contentOp{Operator: "Do", Operands: []types.Object{types.Name(replaced)}})
+				}
+				continue
+			}
+			sc.used[string(name)] = true
+		}
+		out = append(out, op)
+	}
+	if changed {
+		if err := sc.prune(); err != nil {
+			return nil, false, err
+		}
+	}
+	return out, changed, nil
+}
+
+// showText places the glyphs of a text showing operand and advances the text
+// matrix. When redacting, covered glyphs are replaced by the equivalent
+// spacing so that the remaining text keeps its position.
+func (r *redactor) showText(ts *textState, fi *fontInfo, ctm matrix, o types.Object) (types.Array, bool) {
+	elements := types.Array{o}
+	if arr, ok := o.(types.Array); ok {
+		elements = arr
+	}
+
+	var shown types.Array
+	removed := false
+	for _, e := range elements {
+		switch e.(type) {
+		case types.StringLiteral, types.HexLiteral:
+		default:
+			ts.tm = matrix{1, 0, 0, 1, -number(e) / 1000 * ts.size * ts.scale, 0}.mul(ts.tm)
+			shown = append(shown, e)
+			continue
+		}
+
+		var kept []byte
+		flush := func() {
+			if len(kept) > 0 {
+				shown = append(shown, types.HexLiteral(hex.EncodeToString(kept)))
+				kept = nil
+			}
+		}
+		trm := ts.tm.mul(ctm)
+		for _, g := range fi.glyphs(stringBytes(e)) {
+			advance := g.width*ts.size + ts.charSpace
+			if len(g.code) == 1 && g.code[0] == ' ' {
+				advance += ts.wordSpace
+			}
+			var b bbox
+			b.addRect(ts.tm.mul(ctm), 0, ts.rise-0.25*ts.size, g.width*ts.size*ts.scale, ts.rise+0.85*ts.size)
+
+			if len(r.rects) == 0 {
+				size := ts.size * math.Hypot(trm[2], trm[3])
+				r.glyphs = append(r.glyphs, placedGlyph{glyph: g, box: b, size: size})
+			} else if r.covered(b) {
+				flush()
+				if ts.size != 0 {
+					shown = append(shown, types.Float(-advance*1000/ts.size))
+				}
+				removed = true
+				r.report.GlyphsRemoved++
+			} else {
+				kept = append(kept, g.code...)
+			}
+			ts.tm = matrix{1, 0, 0, 1, advance * ts.scale, 0}.mul(ts.tm)
+		}
+		flush()
+	}
+	return shown, removed
+}
+
+// xObject handles drawing the named XObject. It returns the name of a
+// redacted copy to draw instead, or drop when the drawing must be removed.
+func (r *redactor) xObject(sc *redactScope, name string, ctm matrix, depth int) (string, bool, error) {
+	if sc.res == nil {
+		return "", false, nil
+	}
+	xobjs, err := r.xRefTable.DereferenceDict(sc.res["XObject"])
+	if err != nil || xobjs == nil {
+		return "", false, nil
+	}
+	ref, _ := xobjs[name].(types.IndirectRef)
+	sd, _, err := r.xRefTable.DereferenceStreamDict(xobjs[name])
+	if err != nil || sd == nil {
+		return "", false, nil
+	}
+	subtype := sd.Subtype()
+	if subtype == nil {
+		return "", false, nil
+	}
+
+	switch *subtype {
+	case "Image":
+		if len(r.rects) == 0 {
+			return "", false, nil
+		}
+		var b bbox
+		b.addRect(ctm, 0, 0, 1, 1)
+		touched, inside := r.overlaps(b)
+		if !touched {
+			return "", false, nil
+		}
+		if !inside {
+			if cleared, err := r.clearImage(sd, ref.ObjectNumber.Value(), ctm); err == nil {
+				newName, err := sc.add(*cleared)
+				if err != nil {
+					return "", false, err
+				}
+				r.report.ImagesCleared++
+				return newName, false, nil
+			}
+		}
+		// Covered completely, or not decodable: remove rather than leak
+		r.report.ImagesRemoved++
+		return "", true, nil
+
+	case "Form":
+		if depth >= maxFormDepth {
+			return "", false, nil
+		}
+		if err := sd.Decode(); err != nil {
+			return "", false, nil
+		}
+		ops, err := parseContent(sd.Content)
+		if err != nil {
+			return "", false, nil
+		}
+		m := identity
+		if arr, err := r.xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(arr) == 6 {
+			m = toMatrix(numbers(arr))
+		}
+		formRes := sc.res
+		if d, err := r.xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+			formRes = d
+		}
+
+		// The form may be used elsewhere, so changes go into a copy
+		copyRes := formRes.Clone().(types.Dict)
+		inner := &redactScope{res: formRes, writable: func() (types.Dict, error) {
+			xo := types.Dict{}
+			if d, err := r.xRefTable.DereferenceDict(copyRes["XObject"]); err != nil {
+				return nil, err
+			} else if d != nil {
+				xo = d.Clone().(types.Dict)
+			}
+			copyRes["XObject"] = xo
+			return xo, nil
+		}}
+		out, changed, err := r.process(ops, inner, m.mul(ctm), depth+1)
+		if err != nil || !changed {
+			return "", false, err
+		}
+
+		dict := sd.Dict.Clone().(types.Dict)
+		delete(dict, "Filter")
+		delete(dict, "DecodeParms")
+		dict["Resources"] = copyRes
+		form := types.StreamDict{Dict: dict, Content: writeContent(out)}
+		form.InsertName("Filter", "FlateDecode")
+		form.FilterPipeline = []types.PDFFilter{{Name: "FlateDecode"}}
+		if err := form.Encode(); err != nil {
+			return "", false, err
+		}
+		newRef, err := r.xRefTable.IndRefForNewObject(form)
+		if err != nil {
+			return "", false, err
+		}
+		newName, err := sc.add(*newRef)
+		return newName, false, err
+	}
+	return "", false, nil
+}
+
+// clearImage returns a copy of an image with the pixels under the redaction
+// rectangles painted black
+func (r *redactor) clearImage(sd *types.StreamDict, objNr int, ctm matrix) (*types.IndirectRef, error) {
+	if mask := sd.BooleanEntry("ImageMask"); mask != nil && *mask {
+		return nil, fmt.Errorf("stencil masks are not decoded")
+	}
+	decoded, err := pdfcpu.ExtractImage(r.ctx, sd, false, "", objNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		return nil, fmt.Errorf("unsupported image filter")
+	}
+	img, _, err := image.Decode(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	_, gray := img.(*image.Gray)
+	channels := 3
+	if gray {
+		channels = 1
+	}
+	buf := make([]byte, 0, w*h*channels)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Image space is the unit square with its origin at the bottom left
+			var px bbox
+			px.addRect(ctm, float64(x)/float64(w), 1-float64(y+1)/float64(h), float64(x+1)/float64(w), 1-float64(y)/float64(h))
+			if r.covered(px) {
+				buf = append(buf, make([]byte, channels)...)
+				continue
+			}
+			cr, cg, cb, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if gray {
+				buf = append(buf, byte(cr>>8))
+			} else {
+				buf = append(buf, byte(cr>>8), byte(cg>>8), byte(cb>>8))
+			}
+		}
+	}
+
+	cs := model.DeviceRGBCS
+	if gray {
+		cs = model.DeviceGrayCS
+	}
+	cleared, err := model.CreateFlateImageStreamDict(r.xRefTable, buf, nil, w, h, 8, cs)
+	if err != nil {
+		return nil, err
+	}
+	if smask, ok := sd.Dict["SMask"]; ok {
+		cleared.Dict["SMask"] = smask
+	}
+	return r.xRefTable.IndRefForNewObject(*cleared)
+}
+
+// removeAnnotations deletes the annotations of page dict d that overlap a
+// redaction rectangle, so links and notes cannot reveal what was removed
+func (r *redactor) removeAnnotations(d types.Dict) error {
+	annots, err := r.xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		return err
+	}
+	var kept types.Array
+	removed := map[types.IndirectRef]bool{}
+	for _, o := range annots {
+		annot, err := r.xRefTable.DereferenceDict(o)
+		if err != nil || annot == nil || annotationSubtype(annot) == "Popup" {
+			kept = append(kept, o)
+			continue
+		}
+		rect, err := r.xRefTable.DereferenceArray(annot["Rect"])
+		if err != nil || len(rect) != 4 {
+			kept = append(kept, o)
+			continue
+		}
+		f := numbers(rect)
+		var b bbox
+		b.addRect(identity, f[0], f[1], f[2], f[3])
+		if touched, _ := r.overlaps(b); !touched {
+			kept = append(kept, o)
+			continue
+		}
+		if ref, ok := o.(types.IndirectRef); ok {
+			removed[ref] = true
+		}
+		if annotationSubtype(annot) == "Widget" {
+			r.clearFieldValue(annot)
+		}
+		r.report.AnnotationsRemoved++
+	}
+	if len(kept) < len(annots) {
+		setPageAnnotations(r.xRefTable, d, kept, removed)
+		// A removed widget's field would still hold the value in the form
+		return removeFormFields(r.xRefTable, removed)
+	}
+	return nil
+}
+
+// clearFieldValue deletes the value of the field a widget belongs to, which
+// stays in the file while other widgets of the field remain
+func (r *redactor) clearFieldValue(widget types.Dict) {
+	field := widget
+	for depth := 0; field != nil && depth < 32; depth++ {
+		delete(field, "V")
+		delete(field, "DV")
+		parent, err := r.xRefTable.DereferenceDict(field["Parent"])
+		if err != nil {
+			return
+		}
+		field = parent
+	}
+}
@@ -0,0 +1,240 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/pkg/models"
+)
+
+// writeTestPDF writes a PDF built from the given objects, numbered from 1,
+// with object 1 as the catalog
+func writeTestPDF(t *testing.T, path string, objects []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testStream returns a stream object holding content
+func testStream(dict, content string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(content), content)
+}
+
+// shownText returns the text shown by the page content streams and all form
+// XObjects of a PDF, one string per text showing operator
+func shownText(t *testing.T, path string) string {
+	t.Helper()
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var streams [][]byte
+	for page := 1; page <= ctx.PageCount; page++ {
+		d, _, _, err := ctx.PageDict(page, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ctx.PageContent(d, page)
+		if err != nil && err != model.ErrNoContent {
+			t.Fatal(err)
+		}
+		streams = append(streams, data)
+	}
+	for _, entry := range ctx.Table {
+		if entry == nil || entry.Free {
+			continue
+		}
+		sd, ok := entry.Object.(types.StreamDict)
+		if !ok || sd.Subtype() == nil || *sd.Subtype() != "Form" {
+			continue
+		}
+		if err := sd.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		streams = append(streams, sd.Content)
+	}
+
+	var text []string
+	for _, data := range streams {
+		ops, err := parseContent(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, op := range ops {
+			if op.Operator != "Tj" && op.Operator != "TJ" && op.Operator != "'" && op.Operator != "\"" {
+				continue
+			}
+			var b strings.Builder
+			o := op.Operands[len(op.Operands)-1]
+			elements := types.Array{o}
+			if arr, ok := o.(types.Array); ok {
+				elements = arr
+			}
+			for _, e := range elements {
+				switch e.(type) {
+				case types.StringLiteral, types.HexLiteral:
+					b.Write(stringBytes(e))
+				}
+			}
+			text = append(text, b.String())
+		}
+	}
+	return strings.Join(text, "|")
+}
+
+func TestRedactRemovesText(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.pdf")
+	output := filepath.Join(dir, "out.pdf")
+
+	font := "/Font << /F1 5 0 R >>"
+	writeTestPDF(t, input, []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << " + font + " /XObject << /Fm1 6 0 R >> >> /Contents 4 0 R >>",
+		testStream("", "BT /F1 12 Tf 72 700 Td (Public line) Tj ET\nBT /F1 12 Tf 72 600 Td (Top SECRET here) Tj ET\nq /Fm1 Do Q"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		testStream("/Type /XObject /Subtype /Form /BBox [0 0 612 792] /Resources << "+font+" >>", "BT /F1 12 Tf 72 500 Td (Card 4111-1111 end) Tj ET"),
+	})
+
+	if before := shownText(t, input); !strings.Contains(before, "SECRET") || !strings.Contains(before, "4111-1111") {
+		t.Fatalf("test PDF text not found: %q", before)
+	}
+
+	// "Top " ends at x 96.0 and SECRET runs from there to 144.7. The area only
+	// reaches into the S and the T, which must go with the rest of the word.
+	report, err := NewService().Redact(models.RedactConfig{
+		InputFile:  input,
+		OutputFile: output,
+		Areas:      []models.RedactArea{{Page: 1, Rect: [4]float64{100, 598, 140, 612}}},
+		Patterns:   []string{`\d{4}-\d{4}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Redactions) != 2 {
+		t.Errorf("got %d redactions, want 2", len(report.Redactions))
+	}
+	if report.GlyphsRemoved != len("SECRET")+len("4111-1111") {
+		t.Errorf("removed %d glyphs, want %d", report.GlyphsRemoved, len("SECRET")+len("4111-1111"))
+	}
+
+	after := shownText(t, output)
+	for _, gone := range []string{"S", "ECRE", "4111", "1111", "-"} {
+		if strings.Contains(after, gone) {
+			t.Errorf("redacted text %q still in output: %q", gone, after)
+		}
+	}
+	for _, kept := range []string{"Public line", "Top ", " here", "Card ", " end"} {
+		if !strings.Contains(after, kept) {
+			t.Errorf("text %q outside the redactions missing from output: %q", kept, after)
+		}
+	}
+}
+
+// pdfContains reports whether any object of a PDF, or the decoded data of
+// any stream, holds s
+func pdfContains(t *testing.T, path, s string) bool {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte(s)) {
+		return true
+	}
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range ctx.Table {
+		if entry == nil || entry.Free || entry.Object == nil {
+			continue
+		}
+		if strings.Contains(entry.Object.String(), s) {
+			return true
+		}
+		if sd, ok := entry.Object.(types.StreamDict); ok {
+			if err := sd.Decode(); err == nil && bytes.Contains(sd.Content, []byte(s)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestRedactRemovesFormFieldValue(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.pdf")
+	output := filepath.Join(dir, "out.pdf")
+
+	appearance := func(text string) string {
+		return testStream("/Type /XObject /Subtype /Form /BBox [0 0 200 20] /Resources << /Font << /F1 5 0 R >> >>",
+			"/Tx BMC BT /F1 12 Tf 2 5 Td ("+text+") Tj ET EMC")
+	}
+	writeTestPDF(t, input, []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm 4 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [6 0 R 9 0 R] >>",
+		"<< /Fields [6 0 R 8 0 R] /DA (/Helv 0 Tf 0 g) >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		// A field with its widget merged into it
+		"<< /Type /Annot /Subtype /Widget /FT /Tx /T (ssn) /V (123-45-6789) /DA (/F1 12 Tf 0 g) /Rect [72 600 272 620] /P 3 0 R /AP << /N 7 0 R >> >>",
+		appearance("123-45-6789"),
+		// A field with a separate widget outside the redaction
+		"<< /FT /Tx /T (name) /V (Jane Public) /DA (/F1 12 Tf 0 g) /Kids [9 0 R] >>",
+		"<< /Type /Annot /Subtype /Widget /Parent 8 0 R /Rect [72 500 272 520] /P 3 0 R /AP << /N 10 0 R >> >>",
+		appearance("Jane Public"),
+	})
+	if !pdfContains(t, input, "123-45-6789") {
+		t.Fatal("test PDF does not hold the field value")
+	}
+
+	report, err := NewService().Redact(models.RedactConfig{
+		InputFile:  input,
+		OutputFile: output,
+		Areas:      []models.RedactArea{{Page: 1, Rect: [4]float64{60, 590, 300, 630}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.AnnotationsRemoved != 1 {
+		t.Errorf("removed %d annotations, want 1", report.AnnotationsRemoved)
+	}
+	if pdfContains(t, output, "123-45-6789") {
+		t.Error("redacted field value still in output")
+	}
+	if !pdfContains(t, output, "Jane Public") {
+		t.Error("field outside the redaction missing from output")
+	}
+
+	fields, err := NewService().ListFormFields(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 1 || fields[0].Name != "name" {
+		t.Errorf("got fields %+v, want only name", fields)
+	}
+}
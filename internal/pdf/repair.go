@@ -0,0 +1,893 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Validate checks the structure of a PDF: its header, the cross-reference
+// sections and the object offsets they record, the trailer, references to
+// missing objects and finally the document structure as pdfcpu validates it.
+// The cross-reference data is read independently of pdfcpu, which quietly
+// works around many of these problems when opening a file.
+func (s *Service) Validate(filePath string, level models.ValidationLevel) (*models.ValidationReport, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if level != models.ValidationStrict {
+		level = models.ValidationRelaxed
+	}
+	strict := level == models.ValidationStrict
+
+	report := &models.ValidationReport{File: filePath, Level: level}
+	problem := func(kind string, obj int, offset int64, format string, args ...interface{}) {
+		report.Problems = append(report.Problems, models.ValidationProblem{
+			Kind: kind, Object: obj, Offset: offset, Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	switch at := bytes.Index(data[:min(len(data), 1024)], []byte("%PDF-")); {
+	case at < 0:
+		problem("header", 0, 0, "the %%PDF- header is missing")
+	case at > 0 && strict:
+		problem("header", 0, int64(at), "the %%PDF- header starts at offset %d instead of the start of the file", at)
+	}
+	if strict && !bytes.Contains(data[max(0, len(data)-1024):], []byte("%%EOF")) {
+		problem("trailer", 0, -1, "the %%%%EOF marker is missing at the end of the file")
+	}
+
+	scan := scanObjects(data)
+	entries, trailer := map[int]xrefEntry{}, types.Dict(nil)
+	if start, ok := findStartXRef(data); !ok {
+		problem("xref", 0, -1, "startxref is missing, the cross-reference data cannot be located")
+	} else {
+		seen := map[int64]bool{}
+		for offset := start; ; {
+			if offset <= 0 || offset >= int64(len(data)) {
+				problem("xref", 0, offset, "a cross-reference section is expected at offset %d, outside the file", offset)
+				break
+			}
+			if seen[offset] {
+				problem("xref", 0, offset, "the cross-reference sections form a loop at offset %d", offset)
+				break
+			}
+			seen[offset] = true
+			section, dict, err := readXRefSection(data, offset, scan, strict)
+			if err != nil {
+				problem("xref", 0, offset, "%v", err)
+				// Check the objects against the last section in the file
+				// when startxref itself is wrong
+				found, ok := lastXRefSection(data, scan)
+				if offset != start || !ok || seen[found] {
+					break
+				}
+				problem("xref", 0, found, "the last cross-reference section is at offset %d, startxref gives %d", found, start)
+				offset = found
+				continue
+			}
+			// Sections are read newest first, so earlier entries win
+			for nr, e := range section {
+				if _, ok := entries[nr]; !ok {
+					entries[nr] = e
+				}
+			}
+			if trailer == nil {
+				trailer = dict
+			}
+			prev, ok := dict["Prev"].(types.Integer)
+			if !ok {
+				break
+			}
+			offset = int64(prev)
+		}
+	}
+
+	nrs := make([]int, 0, len(entries))
+	for nr := range entries {
+		nrs = append(nrs, nr)
+	}
+	sort.Ints(nrs)
+	streams := map[int]map[int]int{}
+	for _, nr := range nrs {
+		e := entries[nr]
+		switch e.kind {
+		case xrefInUse:
+			report.Objects++
+			if n, gen, ok := objectHeaderAt(data, e.offset, strict); ok && n == nr && gen == e.gen {
+				continue
+			}
+			if o, ok := scan.latest[nr]; ok {
+				problem("offset", nr, e.offset, "object %d is recorded at offset %d but starts at offset %d", nr, e.offset, o.offset)
+			} else {
+				problem("missing", nr, e.offset, "object %d is recorded at offset %d but does not exist in the file", nr, e.offset)
+			}
+		case xrefCompressed:
+			report.Objects++
+			if se := entries[e.stream]; se.kind != xrefInUse {
+				problem("missing", nr, -1, "object %d is stored in object stream %d, which is missing", nr, e.stream)
+				continue
+			}
+			if _, ok := streams[e.stream]; !ok {
+				streams[e.stream] = nil
+				if o, ok := scan.latest[e.stream]; ok {
+					streams[e.stream], _ = objectStreamIndex(o)
+				}
+			}
+			if i, ok := streams[e.stream][nr]; !ok || i != e.index {
+				problem("missing", nr, -1, "object %d is not at index %d of object stream %d", nr, e.index, e.stream)
+			}
+		}
+	}
+
+	if trailer != nil {
+		highest := 0
+		if len(nrs) > 0 {
+			highest = nrs[len(nrs)-1]
+		}
+		if root, ok := trailer["Root"].(types.IndirectRef); !ok {
+			problem("trailer", 0, -1, "the trailer has no Root entry pointing to the document catalog")
+		} else if e, ok := entries[root.ObjectNumber.Value()]; !ok || e.kind == xrefFree {
+			problem("missing", root.ObjectNumber.Value(), -1, "the document catalog (object %d) is missing", root.ObjectNumber.Value())
+		}
+		if size, ok := trailer["Size"].(types.Integer); !ok {
+			problem("trailer", 0, -1, "the trailer has no Size entry")
+		} else if strict && int(size) <= highest {
+			problem("trailer", 0, -1, "the trailer Size %d does not exceed the highest object number %d", int(size), highest)
+		}
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	if strict {
+		conf.ValidationMode = model.ValidationStrict
+	}
+	ctx, err := api.ReadContext(bytes.NewReader(data), conf)
+	if err != nil {
+		problem("read", 0, -1, "the file cannot be opened: %v", err)
+		return report, nil
+	}
+	report.Readable = true
+
+	missing := missingReferences(ctx)
+	for _, nr := range sortedNumbers(missing) {
+		problem("missing", nr, -1, "object %d is referenced by object %d but does not exist", nr, missing[nr])
+	}
+	if err := validateContext(ctx); err != nil {
+		problem("structure", ctx.CurObj, -1, "%v", err)
+	}
+	return report, nil
+}
+
+// Repair writes a fixed copy of a PDF whose cross-reference data is broken.
+// The objects are found by scanning the file, the newest definition of each
+// winning as in an incremental update, and a new cross-reference section is
+// built for them. References to objects that cannot be found are dropped.
+func (s *Service) Repair(inputFile, outputFile string) (*models.RepairReport, error) {
+	if !utils.IsPDF(inputFile) {
+		return nil, fmt.Errorf("input file must be a PDF")
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	// Offsets count from the header, so anything before it is dropped
+	if at := bytes.Index(data[:min(len(data), 1024)], []byte("%PDF-")); at > 0 {
+		data = data[at:]
+	} else if at < 0 {
+		data = append([]byte("%PDF-1.7\n"), data...)
+	}
+	if len(data) > math.MaxUint32 {
+		return nil, fmt.Errorf("file is too large to repair")
+	}
+
+	scan := scanObjects(data)
+	entries := map[int]xrefEntry{}
+	var catalog *types.IndirectRef
+	for _, o := range scan.objects {
+		if o.dict.Type() != nil && *o.dict.Type() == "XRef" {
+			continue
+		}
+		entries[o.nr] = xrefEntry{kind: xrefInUse, offset: o.offset, gen: o.gen}
+		if o.dict.Type() != nil && *o.dict.Type() == "Catalog" {
+			catalog = types.NewIndirectRef(o.nr, o.gen)
+		}
+		if index, err := objectStreamIndex(o); err == nil {
+			for nr, i := range index {
+				entries[nr] = xrefEntry{kind: xrefCompressed, stream: o.nr, index: i}
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no objects found, the file cannot be repaired")
+	}
+
+	// Take the trailer entries from the newest trailer that has them
+	trailer := types.Dict{}
+	for i := len(scan.trailers) - 1; i >= 0; i-- {
+		t := scan.trailers[i]
+		for _, key := range []string{"Root", "Info", "Encrypt"} {
+			ref, ok := t[key].(types.IndirectRef)
+			if _, done := trailer[key]; done || !ok {
+				continue
+			}
+			if e, ok := entries[ref.ObjectNumber.Value()]; ok && e.kind != xrefFree {
+				trailer[key] = ref
+			}
+		}
+		if _, done := trailer["ID"]; !done && t["ID"] != nil {
+			trailer["ID"] = t["ID"]
+		}
+	}
+	if _, ok := trailer["Root"]; !ok {
+		if catalog == nil {
+			return nil, fmt.Errorf("no document catalog found, the file cannot be repaired")
+		}
+		trailer["Root"] = *catalog
+	}
+
+	report := &models.RepairReport{ObjectsRecovered: len(entries)}
+	var buf bytes.Buffer
+	buf.Write(data)
+	buf.WriteString("\n")
+	// Streams whose Length is wrong or missing are copied with the length
+	// found by scanning
+	for _, nr := range sortedNumbers(scan.latest) {
+		o := scan.latest[nr]
+		if o.stream == nil || o.dict == nil || entries[nr].kind != xrefInUse || streamLength(o.dict, scan) == len(o.stream) {
+			continue
+		}
+		d := o.dict.Clone().(types.Dict)
+		d["Length"] = types.Integer(len(o.stream))
+		entries[nr] = xrefEntry{kind: xrefInUse, offset: int64(buf.Len()), gen: o.gen}
+		fmt.Fprintf(&buf, "%d %d obj\n%s\nstream\n", nr, o.gen, d.PDFString())
+		buf.Write(o.stream)
+		buf.WriteString("\nendstream\nendobj\n")
+		report.StreamLengthsFixed++
+	}
+	writeRebuiltXRef(&buf, entries, trailer)
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	ctx, err := api.ReadContext(bytes.NewReader(buf.Bytes()), conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the rebuilt PDF: %w", err)
+	}
+	report.ReferencesDropped = dropMissingReferences(ctx)
+	// pdfcpu only writes every object of a validated context
+	if err := validateContext(ctx); err != nil {
+		return nil, fmt.Errorf("the rebuilt PDF is still invalid: %w", err)
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(outputFile)); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := api.WriteContextFile(ctx, outputFile); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+
+	check, err := s.Validate(outputFile, models.ValidationRelaxed)
+	if err != nil {
+		return nil, err
+	}
+	report.Remaining = check.Problems
+	return report, nil
+}
+
+const (
+	xrefFree = iota
+	xrefInUse
+	xrefCompressed
+)
+
+// xrefEntry is a cross-reference entry
+type xrefEntry struct {
+	kind   int
+	offset int64 // in-use objects
+	gen    int
+	stream int // compressed objects: the object stream and the index in it
+	index  int
+}
+
+// rawObject is an indirect object found by scanning the bytes of a file
+type rawObject struct {
+	nr, gen int
+	offset  int64        // of the "nr gen obj" header
+	value   types.Object // nil when the object cannot be parsed
+	dict    types.Dict   // nil unless the object is a dictionary or a stream
+	stream  []byte       // encoded stream data, nil for other objects
+}
+
+// objectScan holds the objects and trailer dictionaries of a file in the
+// order they appear
+type objectScan struct {
+	objects  []*rawObject
+	trailers []types.Dict // classic trailers and cross-reference stream dictionaries
+	byOffset map[int64]*rawObject
+	latest   map[int]*rawObject // the last definition of each object number
+}
+
+var (
+	objHeaderRe = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj\b`)
+	trailerRe   = regexp.MustCompile(`trailer[\x00\t\n\f\r ]*<<`)
+)
+
+// scanObjects finds the indirect objects of a file without using its
+// cross-reference data. Streams are skipped so that their content is not
+// mistaken for objects.
+func scanObjects(data []byte) *objectScan {
+	scan := &objectScan{byOffset: map[int64]*rawObject{}, latest: map[int]*rawObject{}}
+	addTrailers := func(gap []byte) {
+		for _, loc := range trailerRe.FindAllIndex(gap, -1) {
+			text := string(gap[loc[1]-2:])
+			if d, err := model.ParseObject(&text); err == nil {
+				if d, ok := d.(types.Dict); ok {
+					scan.trailers = append(scan.trailers, d)
+				}
+			}
+		}
+	}
+
+	pos := 0
+	for pos < len(data) {
+		loc := objHeaderRe.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			addTrailers(data[pos:])
+			break
+		}
+		start, body := pos+loc[0], pos+loc[1]
+		if start > 0 && !isPDFSpaceOrDelimiter(data[start-1]) {
+			pos = body
+			continue
+		}
+		addTrailers(data[pos:start])
+
+		nr, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		gen, _ := strconv.Atoi(string(data[pos+loc[4] : pos+loc[5]]))
+		o := &rawObject{nr: nr, gen: gen, offset: int64(start)}
+		pos = body
+
+		end := bytes.Index(data[body:], []byte("endobj"))
+		if end < 0 {
+			end = len(data) - body
+		}
+		text := data[body : body+end]
+		if at := bytes.Index(text, []byte("stream")); at >= 0 && bytes.HasPrefix(bytes.TrimLeft(text, "\x00\t\n\f\r "), []byte("<<")) {
+			text = text[:at]
+			streamAt := body + at + len("stream")
+			if bytes.HasPrefix(data[streamAt:], []byte("\r\n")) {
+				streamAt += 2
+			} else if streamAt < len(data) && (data[streamAt] == '\n' || data[streamAt] == '\r') {
+				streamAt++
+			}
+			o.stream, pos = rawStream(data, streamAt, text)
+			end = bytes.Index(data[pos:], []byte("endobj"))
+			if end < 0 {
+				end = len(data) - pos
+			}
+			pos += end
+		} else {
+			pos = body + end
+		}
+		if pos < len(data) {
+			pos += len("endobj")
+		}
+
+		s := string(text)
+		if v, err := model.ParseObject(&s); err == nil {
+			o.value = v
+			o.dict, _ = v.(types.Dict)
+		}
+		scan.objects = append(scan.objects, o)
+		scan.byOffset[o.offset] = o
+		scan.latest[nr] = o
+		if o.dict.Type() != nil && *o.dict.Type() == "XRef" {
+			scan.trailers = append(scan.trailers, o.dict)
+		}
+	}
+	return scan
+}
+
+// rawStream returns the stream data starting at offset and the offset after
+// the endstream keyword. A direct Length is used when endstream follows it,
+// otherwise the data runs to the next endstream.
+func rawStream(data []byte, offset int, dictText []byte) ([]byte, int) {
+	s := string(dictText)
+	if d, err := model.ParseObject(&s); err == nil {
+		if d, ok := d.(types.Dict); ok {
+			if n, ok := d["Length"].(types.Integer); ok && n >= 0 && offset+int(n) <= len(data) {
+				after := bytes.TrimLeft(data[offset+int(n):], "\x00\t\n\f\r ")
+				if bytes.HasPrefix(after, []byte("endstream")) {
+					return data[offset : offset+int(n)], len(data) - len(after) + len("endstream")
+				}
+			}
+		}
+	}
+	end := bytes.Index(data[offset:], []byte("endstream"))
+	if end < 0 {
+		return data[offset:], len(data)
+	}
+	raw := data[offset : offset+end]
+	if bytes.HasSuffix(raw, []byte("\r\n")) {
+		raw = raw[:len(raw)-2]
+	} else if bytes.HasSuffix(raw, []byte("\n")) || bytes.HasSuffix(raw, []byte("\r")) {
+		raw = raw[:len(raw)-1]
+	}
+	return raw, offset + end + len("endstream")
+}
+
+// streamLength returns the Length of a scanned stream, or -1 when it is
+// missing or refers to an object that is not an integer
+func streamLength(d types.Dict, scan *objectScan) int {
+	switch l := d["Length"].(type) {
+	case types.Integer:
+		return l.Value()
+	case types.IndirectRef:
+		if o, ok := scan.latest[l.ObjectNumber.Value()]; ok {
+			if n, ok := o.value.(types.Integer); ok {
+				return n.Value()
+			}
+		}
+	}
+	return -1
+}
+
+func isPDFSpaceOrDelimiter(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ', '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// findStartXRef returns the offset given by the last startxref keyword
+func findStartXRef(data []byte) (int64, bool) {
+	at := bytes.LastIndex(data, []byte("startxref"))
+	if at < 0 {
+		return 0, false
+	}
+	rest := bytes.TrimLeft(data[at+len("startxref"):], "\x00\t\n\f\r ")
+	n := 0
+	for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+		n++
+	}
+	offset, err := strconv.ParseInt(string(rest[:n]), 10, 64)
+	return offset, err == nil
+}
+
+// lastXRefSection finds the last cross-reference table or stream in a file
+func lastXRefSection(data []byte, scan *objectScan) (int64, bool) {
+	found := int64(-1)
+	for at := len(data); at > 0; {
+		at = bytes.LastIndex(data[:at], []byte("xref"))
+		if at < 0 {
+			break
+		}
+		// The keyword stands on its own, unlike in startxref
+		if (at == 0 || isPDFSpaceOrDelimiter(data[at-1])) && at+4 < len(data) && isPDFSpaceOrDelimiter(data[at+4]) {
+			found = int64(at)
+			break
+		}
+	}
+	for i := len(scan.objects) - 1; i >= 0; i-- {
+		if o := scan.objects[i]; o.dict.Type() != nil && *o.dict.Type() == "XRef" {
+			found = max(found, o.offset)
+			break
+		}
+	}
+	return found, found >= 0
+}
+
+// objectHeaderAt parses the "nr gen obj" header at offset. Leading white
+// space is tolerated unless strict.
+func objectHeaderAt(data []byte, offset int64, strict bool) (nr, gen int, ok bool) {
+	if offset < 0 || offset >= int64(len(data)) {
+		return 0, 0, false
+	}
+	text := data[offset:min(int64(len(data)), offset+64)]
+	if !strict {
+		text = bytes.TrimLeft(text, "\x00\t\n\f\r ")
+	}
+	loc := objHeaderRe.FindSubmatchIndex(text)
+	if loc == nil || loc[0] != 0 {
+		return 0, 0, false
+	}
+	nr, _ = strconv.Atoi(string(text[loc[2]:loc[3]]))
+	gen, _ = strconv.Atoi(string(text[loc[4]:loc[5]]))
+	return nr, gen, true
+}
+
+// readXRefSection reads the cross-reference table or stream at offset along
+// with its trailer dictionary. A hybrid file's XRefStm entries are included,
+// the table taking precedence.
+func readXRefSection(data []byte, offset int64, scan *objectScan, strict bool) (map[int]xrefEntry, types.Dict, error) {
+	text := data[offset:]
+	if !strict {
+		text = bytes.TrimLeft(text, "\x00\t\n\f\r ")
+	}
+	if !bytes.HasPrefix(text, []byte("xref")) {
+		at := offset + int64(len(data[offset:])-len(text))
+		o, ok := scan.byOffset[at]
+		if !ok {
+			return nil, nil, fmt.Errorf("there is no cross-reference section at offset %d", offset)
+		}
+		if o.dict.Type() == nil || *o.dict.Type() != "XRef" {
+			return nil, nil, fmt.Errorf("offset %d points to object %d, not to a cross-reference section", offset, o.nr)
+		}
+		entries, err := xrefStreamEntries(o)
+		return entries, o.dict, err
+	}
+
+	entries, trailer, err := xrefTableEntries(text[len("xref"):])
+	if err != nil {
+		return nil, nil, fmt.Errorf("the cross-reference table at offset %d is malformed: %w", offset, err)
+	}
+	if stm, ok := trailer["XRefStm"].(types.Integer); ok {
+		if o, ok := scan.byOffset[int64(stm)]; ok {
+			if more, err := xrefStreamEntries(o); err == nil {
+				for nr, e := range more {
+					if _, ok := entries[nr]; !ok {
+						entries[nr] = e
+					}
+				}
+			}
+		}
+	}
+	return entries, trailer, nil
+}
+
+// xrefTableEntries parses the subsections of a classic cross-reference table
+// and the trailer dictionary following them
+func xrefTableEntries(text []byte) (map[int]xrefEntry, types.Dict, error) {
+	end := bytes.Index(text, []byte("trailer"))
+	if end < 0 {
+		return nil, nil, fmt.Errorf("the trailer is missing")
+	}
+	fields := bytes.Fields(text[:end])
+	entries := map[int]xrefEntry{}
+	for i := 0; i < len(fields); {
+		if i+2 > len(fields) {
+			return nil, nil, fmt.Errorf("incomplete subsection header")
+		}
+		first, err1 := strconv.Atoi(string(fields[i]))
+		count, err2 := strconv.Atoi(string(fields[i+1]))
+		if err1 != nil || err2 != nil || first < 0 || count < 0 {
+			return nil, nil, fmt.Errorf("invalid subsection header %q", bytes.Join(fields[i:i+2], []byte(" ")))
+		}
+		i += 2
+		if i+3*count > len(fields) {
+			return nil, nil, fmt.Errorf("subsection %d has fewer than %d entries", first, count)
+		}
+		for j := 0; j < count; j, i = j+1, i+3 {
+			offset, err1 := strconv.ParseInt(string(fields[i]), 10, 64)
+			gen, err2 := strconv.Atoi(string(fields[i+1]))
+			if err1 != nil || err2 != nil {
+				return nil, nil, fmt.Errorf("invalid entry for object %d", first+j)
+			}
+			switch string(fields[i+2]) {
+			case "n":
+				entries[first+j] = xrefEntry{kind: xrefInUse, offset: offset, gen: gen}
+			case "f":
+				entries[first+j] = xrefEntry{kind: xrefFree, gen: gen}
+			default:
+				return nil, nil, fmt.Errorf("invalid entry type %q for object %d", fields[i+2], first+j)
+			}
+		}
+	}
+
+	s := string(bytes.TrimLeft(text[end+len("trailer"):], "\x00\t\n\f\r "))
+	o, err := model.ParseObject(&s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the trailer cannot be parsed: %w", err)
+	}
+	trailer, ok := o.(types.Dict)
+	if !ok {
+		return nil, nil, fmt.Errorf("the trailer is not a dictionary")
+	}
+	return entries, trailer, nil
+}
+
+// xrefStreamEntries decodes the entries of a cross-reference stream
+func xrefStreamEntries(o *rawObject) (map[int]xrefEntry, error) {
+	content, err := decodeRawStream(o)
+	if err != nil {
+		return nil, fmt.Errorf("the cross-reference stream (object %d) cannot be decoded: %w", o.nr, err)
+	}
+	w := integers(o.dict.ArrayEntry("W"))
+	size := o.dict.IntEntry("Size")
+	if len(w) != 3 || size == nil {
+		return nil, fmt.Errorf("the cross-reference stream (object %d) lacks W or Size", o.nr)
+	}
+	index := integers(o.dict.ArrayEntry("Index"))
+	if index == nil {
+		index = []int{0, *size}
+	}
+
+	field := func(b []byte) int64 {
+		var v int64
+		for _, c := range b {
+			v = v<<8 | int64(c)
+		}
+		return v
+	}
+	width := w[0] + w[1] + w[2]
+	entries := map[int]xrefEntry{}
+	for i := 0; i+1 < len(index); i += 2 {
+		for nr := index[i]; nr < index[i]+index[i+1]; nr++ {
+			if len(content) < width {
+				return nil, fmt.Errorf("the cross-reference stream (object %d) ends before object %d", o.nr, nr)
+			}
+			kind := int64(1)
+			if w[0] > 0 {
+				kind = field(content[:w[0]])
+			}
+			f2, f3 := field(content[w[0]:w[0]+w[1]]), field(content[w[0]+w[1]:width])
+			content = content[width:]
+			switch kind {
+			case 0:
+				entries[nr] = xrefEntry{kind: xrefFree, gen: int(f3)}
+			case 1:
+				entries[nr] = xrefEntry{kind: xrefInUse, offset: f2, gen: int(f3)}
+			case 2:
+				entries[nr] = xrefEntry{kind: xrefCompressed, stream: int(f2), index: int(f3)}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// objectStreamIndex maps the numbers of the objects in an object stream to
+// their index in it
+func objectStreamIndex(o *rawObject) (map[int]int, error) {
+	if o.stream == nil || o.dict.Type() == nil || *o.dict.Type() != "ObjStm" {
+		return nil, fmt.Errorf("object %d is not an object stream", o.nr)
+	}
+	content, err := decodeRawStream(o)
+	if err != nil {
+		return nil, err
+	}
+	n, first := o.dict.IntEntry("N"), o.dict.IntEntry("First")
+	if n == nil || first == nil || *first > len(content) {
+		return nil, fmt.Errorf("object stream %d lacks N or First", o.nr)
+	}
+	fields := bytes.Fields(content[:*first])
+	index := map[int]int{}
+	for i := 0; i < *n && 2*i+1 < len(fields); i++ {
+		nr, err := strconv.Atoi(string(fields[2*i]))
+		if err != nil {
+			return nil, fmt.Errorf("object stream %d has an invalid header", o.nr)
+		}
+		index[nr] = i
+	}
+	return index, nil
+}
+
+// decodeRawStream applies the filters of a scanned stream object
+func decodeRawStream(o *rawObject) ([]byte, error) {
+	var names []types.Object
+	var parms []types.Object
+	switch f := o.dict["Filter"].(type) {
+	case types.Name:
+		names = []types.Object{f}
+		parms = []types.Object{o.dict["DecodeParms"]}
+	case types.Array:
+		names = f
+		parms, _ = o.dict["DecodeParms"].(types.Array)
+	}
+	var pipeline []types.PDFFilter
+	for i, name := range names {
+		name, ok := name.(types.Name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter %v", name)
+		}
+		filter := types.PDFFilter{Name: name.Value()}
+		if i < len(parms) {
+			filter.DecodeParms, _ = parms[i].(types.Dict)
+		}
+		pipeline = append(pipeline, filter)
+	}
+	sd := types.NewStreamDict(o.dict, 0, nil, nil, pipeline)
+	sd.Raw = o.stream
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+	return sd.Content, nil
+}
+
+// writeRebuiltXRef appends a cross-reference stream for entries and the
+// trailer entries to buf, which holds the whole file so far
+func writeRebuiltXRef(buf *bytes.Buffer, entries map[int]xrefEntry, trailer types.Dict) {
+	nrs := sortedNumbers(entries)
+	nr := nrs[len(nrs)-1] + 1
+	entries[nr] = xrefEntry{kind: xrefInUse, offset: int64(buf.Len())}
+	nrs = append(nrs, nr)
+	if nrs[0] != 0 {
+		entries[0] = xrefEntry{kind: xrefFree, gen: 65535}
+		nrs = append([]int{0}, nrs...)
+	}
+
+	var index types.Array
+	var data []byte
+	for i := 0; i < len(nrs); {
+		j := i
+		for j+1 < len(nrs) && nrs[j+1] == nrs[j]+1 {
+			j++
+		}
+		index = append(index, types.Integer(nrs[i]), types.Integer(j-i+1))
+		for _, n := range nrs[i : j+1] {
+			e := entries[n]
+			switch e.kind {
+			case xrefFree:
+				data = append(data, 0)
+				data = binary.BigEndian.AppendUint32(data, 0)
+				data = binary.BigEndian.AppendUint16(data, uint16(e.gen))
+			case xrefInUse:
+				data = append(data, 1)
+				data = binary.BigEndian.AppendUint32(data, uint32(e.offset))
+				data = binary.BigEndian.AppendUint16(data, uint16(e.gen))
+			case xrefCompressed:
+				data = append(data, 2)
+				data = binary.BigEndian.AppendUint32(data, uint32(e.stream))
+				data = binary.BigEndian.AppendUint16(data, uint16(e.index))
+			}
+		}
+		i = j + 1
+	}
+
+	trailer["Type"] = types.Name("XRef")
+	trailer["Size"] = types.Integer(nr + 1)
+	trailer["Index"] = index
+	trailer["W"] = types.NewIntegerArray(1, 4, 2)
+	trailer["Length"] = types.Integer(len(data))
+	at := entries[nr].offset
+	fmt.Fprintf(buf, "%d 0 obj\n%s\nstream\n", nr, trailer.PDFString())
+	buf.Write(data)
+	fmt.Fprintf(buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", at)
+}
+
+// missingReferences maps the numbers of referenced objects that do not
+// exist to the first object referring to them
+func missingReferences(ctx *model.Context) map[int]int {
+	missing := map[int]int{}
+	visitReferences(ctx, func(from int, ref types.IndirectRef) bool {
+		nr := ref.ObjectNumber.Value()
+		if _, seen := missing[nr]; !seen && !objectExists(ctx, nr) {
+			missing[nr] = from
+		}
+		return true
+	})
+	return missing
+}
+
+// dropMissingReferences removes references to objects that do not exist
+// and returns the numbers of the missing objects. A reference is removed
+// from a list of references such as Kids or Annots; any other array holding
+// one is removed as a whole, as is a dictionary entry.
+func dropMissingReferences(ctx *model.Context) []int {
+	dropped := map[int]int{}
+	// Removing a reference may leave an object empty and remove it in turn
+	for n := -1; n != len(dropped); {
+		n = len(dropped)
+		visitReferences(ctx, func(from int, ref types.IndirectRef) bool {
+			nr := ref.ObjectNumber.Value()
+			if objectExists(ctx, nr) {
+				return true
+			}
+			dropped[nr] = from
+			return false
+		})
+	}
+	return sortedNumbers(dropped)
+}
+
+func objectExists(ctx *model.Context, nr int) bool {
+	e, found := ctx.FindTableEntryLight(nr)
+	return found && e != nil && !e.Free && e.Object != nil
+}
+
+// visitReferences calls keep for every reference held by an object of ctx
+// and removes the references keep rejects
+func visitReferences(ctx *model.Context, keep func(from int, ref types.IndirectRef) bool) {
+	var visit func(from int, o types.Object) types.Object
+	visit = func(from int, o types.Object) types.Object {
+		switch o := o.(type) {
+		case types.IndirectRef:
+			if !keep(from, o) {
+				return nil
+			}
+		case types.Dict:
+			for k, v := range o {
+				if nv := visit(from, v); nv == nil && v != nil {
+					delete(o, k)
+				} else {
+					o[k] = nv
+				}
+			}
+		case types.StreamDict:
+			visit(from, o.Dict)
+		case types.Array:
+			list := true
+			for _, v := range o {
+				if _, ok := v.(types.IndirectRef); !ok {
+					list = false
+				}
+			}
+			kept := make(types.Array, 0, len(o))
+			for _, v := range o {
+				if nv := visit(from, v); nv != nil || v == nil {
+					kept = append(kept, nv)
+				} else if !list {
+					return nil
+				}
+			}
+			return kept
+		}
+		return o
+	}
+	for _, nr := range sortedNumbers(ctx.Table) {
+		e := ctx.Table[nr]
+		if e == nil || e.Free || e.Object == nil {
+			continue
+		}
+		// Objects in object streams are decoded on first use
+		if _, lazy := e.Object.(types.LazyObjectStreamObject); lazy {
+			if _, err := ctx.Dereference(*types.NewIndirectRef(nr, 0)); err != nil {
+				continue
+			}
+		}
+		if o := visit(nr, e.Object); o != nil {
+			e.Object = o
+		} else {
+			// Nothing is left of the object, e.g. an array naming a
+			// missing ICC profile
+			delete(ctx.Table, nr)
+		}
+	}
+}
+
+// validateContext validates ctx with pdfcpu, which may panic on the
+// malformed files validated here
+func validateContext(ctx *model.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pdfcpu: %v", r)
+		}
+	}()
+	return api.ValidateContext(ctx)
+}
+
+// integers returns the values of an array of direct integers
+func integers(a types.Array) []int {
+	var values []int
+	for _, o := range a {
+		if i, ok := o.(types.Integer); ok {
+			values = append(values, i.Value())
+		}
+	}
+	return values
+}
+
+func sortedNumbers[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
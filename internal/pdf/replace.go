@@ -0,0 +1,101 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// pageSizeTolerance is the relative difference in width or height up to which
+// two pages count as the same size, to allow for scanner rounding
+const pageSizeTolerance = 0.02
+
+// ReplacePages swaps the selected pages of a document for pages of another PDF
+func (s *Service) ReplacePages(config models.ReplacePagesConfig) error {
+	if !utils.IsPDF(config.InputFile) || !utils.IsPDF(config.SourceFile) {
+		return fmt.Errorf("input and source files must be PDFs")
+	}
+	if len(config.Pages) == 0 {
+		return fmt.Errorf("no pages specified")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	dims, err := api.PageDimsFile(config.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	sourceDims, err := api.PageDimsFile(config.SourceFile)
+	if err != nil {
+		return fmt.Errorf("failed to read source PDF: %w", err)
+	}
+
+	sourcePages := config.SourcePages
+	if len(sourcePages) == 0 {
+		for i := 1; i <= len(sourceDims); i++ {
+			sourcePages = append(sourcePages, i)
+		}
+	}
+	if len(sourcePages) != len(config.Pages) {
+		return fmt.Errorf("%d pages selected but %d source pages given", len(config.Pages), len(sourcePages))
+	}
+
+	// Map each replaced page to its replacement
+	replacement := map[int]int{}
+	for i, page := range config.Pages {
+		if page < 1 || page > len(dims) {
+			return fmt.Errorf("page %d out of range (1-%d)", page, len(dims))
+		}
+		if _, dup := replacement[page]; dup {
+			return fmt.Errorf("page %d selected more than once", page)
+		}
+		src := sourcePages[i]
+		if src < 1 || src > len(sourceDims) {
+			return fmt.Errorf("source page %d out of range (1-%d)", src, len(sourceDims))
+		}
+		if !config.IgnoreSizes && !sameSize(dims[page-1].Width, dims[page-1].Height, sourceDims[src-1].Width, sourceDims[src-1].Height) {
+			return fmt.Errorf("source page %d (%.0fx%.0f pt) does not match the size of page %d (%.0fx%.0f pt)",
+				src, sourceDims[src-1].Width, sourceDims[src-1].Height, page, dims[page-1].Width, dims[page-1].Height)
+		}
+		replacement[page] = src
+	}
+
+	// Merge both documents and collect the pages in their new order
+	tmp, err := os.CreateTemp("", "pdf-toolbox-replace-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := api.MergeCreateFile([]string{config.InputFile, config.SourceFile}, tmp.Name(), false, nil); err != nil {
+		return fmt.Errorf("failed to combine documents: %w", err)
+	}
+
+	selectors := make([]string, 0, len(dims))
+	for page := 1; page <= len(dims); page++ {
+		if src, ok := replacement[page]; ok {
+			selectors = append(selectors, strconv.Itoa(len(dims)+src))
+		} else {
+			selectors = append(selectors, strconv.Itoa(page))
+		}
+	}
+
+	return api.CollectFile(tmp.Name(), config.OutputFile, selectors, nil)
+}
+
+// sameSize reports whether two page sizes match within pageSizeTolerance
+func sameSize(w1, h1, w2, h2 float64) bool {
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) <= pageSizeTolerance*math.Max(a, b)
+	}
+	return near(w1, w2) && near(h1, h2)
+}
@@ -0,0 +1,121 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Resize scales the selected pages to a paper size, keeping their aspect ratio
+func (s *Service) Resize(config models.ResizeConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return resizeFile(config.InputFile, config.OutputFile, config.Pages, config.ResizeOptions)
+}
+
+// resizeFile scales pages of inFile according to opts and writes outFile
+func resizeFile(inFile, outFile string, pages []int, opts models.ResizeOptions) error {
+	name := string(opts.PaperSize)
+	if name == "" {
+		name = string(models.PaperA4)
+	}
+	dim, ok := types.PaperSize[name]
+	if !ok {
+		return fmt.Errorf("unknown page size: %s", name)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	for _, page := range selectedPages(pages, ctx.PageCount) {
+		if err := resizePage(ctx.XRefTable, page, dim.Width, dim.Height, opts); err != nil {
+			return fmt.Errorf("failed to resize page %d: %w", page, err)
+		}
+	}
+
+	return api.WriteContextFile(ctx, outFile)
+}
+
+// resizePage scales the visible area of a page onto a sheet of paperW x paperH
+func resizePage(xRefTable *model.XRefTable, page int, paperW, paperH float64, opts models.ResizeOptions) error {
+	d, _, inh, err := xRefTable.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	visible := inh.MediaBox
+	if inh.CropBox != nil {
+		visible = inh.CropBox
+	}
+	w, h := visible.Width(), visible.Height()
+	if w <= 0 || h <= 0 {
+		return fmt.Errorf("page has no size")
+	}
+	rot := ((inh.Rotate % 360) + 360) % 360
+	sideways := rot%180 != 0
+
+	// Pick the sheet orientation as displayed, then work in unrotated space
+	landscape := w > h
+	if sideways {
+		landscape = h > w
+	}
+	switch opts.Orientation {
+	case models.OrientationPortrait:
+		landscape = false
+	case models.OrientationLandscape:
+		landscape = true
+	}
+	W, H := math.Min(paperW, paperH), math.Max(paperW, paperH)
+	if landscape != sideways {
+		W, H = H, W
+	}
+
+	scale := math.Min(W/w, H/h)
+	dx, dy := (W-w*scale)/2, (H-h*scale)/2
+	if !opts.Center {
+		// Anchor at the top-left corner as displayed
+		fx, fy := 0.0, 1.0
+		switch rot {
+		case 90:
+			fx, fy = 0, 0
+		case 180:
+			fx, fy = 1, 0
+		case 270:
+			fx, fy = 1, 1
+		}
+		dx, dy = (W-w*scale)*fx, (H-h*scale)*fy
+	}
+
+	m := matrix{scale, 0, 0, scale, dx - scale*visible.LL.X, dy - scale*visible.LL.Y}
+	// Clip to the old visible area so content hidden by the crop box stays hidden
+	prefix := fmt.Sprintf("q %s 0 0 %s %s %s cm %s %s %s %s re W n",
+		formatNumber(m[0]), formatNumber(m[3]), formatNumber(m[4]), formatNumber(m[5]),
+		formatNumber(visible.LL.X), formatNumber(visible.LL.Y), formatNumber(w), formatNumber(h))
+	if err := wrapPageContent(xRefTable, d, prefix, "Q"); err != nil {
+		return err
+	}
+	if err := transformAnnotations(xRefTable, d, m); err != nil {
+		return err
+	}
+
+	sheet := types.NewRectangle(0, 0, W, H).Array()
+	d["MediaBox"] = sheet
+	d["CropBox"] = sheet
+	for _, box := range []string{"TrimBox", "BleedBox", "ArtBox"} {
+		delete(d, box)
+	}
+	return nil
+}
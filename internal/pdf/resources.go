@@ -0,0 +1,150 @@
+package pdf
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// resourceWalk visits the content streams a page draws and the resources
+// each of them uses: the page content, form XObjects, tiling patterns,
+// Type 3 glyph procedures and annotation appearances. Shared streams are
+// visited once.
+type resourceWalk struct {
+	xRefTable *model.XRefTable
+	seen      map[int]bool
+	// visit is called for each content stream; content is nil when the
+	// stream could not be decoded. ref is the stream object, or nil for the
+	// page content.
+	visit func(content []byte, res types.Dict, ref *types.IndirectRef)
+}
+
+// walkPageResources calls visit for every content stream drawn by a page
+func walkPageResources(ctx *model.Context, pageNr int, visit func(content []byte, res types.Dict, ref *types.IndirectRef)) error {
+	d, _, inh, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+	var res types.Dict
+	if inh != nil {
+		res = inh.Resources
+	}
+	content, err := ctx.PageContent(d, pageNr)
+	if err != nil && err != model.ErrNoContent {
+		return err
+	}
+
+	w := &resourceWalk{xRefTable: ctx.XRefTable, seen: map[int]bool{}, visit: visit}
+	w.content(content, res, nil, 0)
+
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		return nil
+	}
+	for _, o := range annots {
+		annot, err := ctx.DereferenceDict(o)
+		if err != nil || annot == nil {
+			continue
+		}
+		ap, err := ctx.DereferenceDict(annot["AP"])
+		if err != nil || ap == nil {
+			continue
+		}
+		for _, key := range []string{"N", "R", "D"} {
+			appearance, err := ctx.Dereference(ap[key])
+			if err != nil {
+				continue
+			}
+			if states, ok := appearance.(types.Dict); ok {
+				// One appearance per state, as for check boxes
+				for _, state := range sortedKeys(states) {
+					w.stream(states[state], nil, 0)
+				}
+				continue
+			}
+			w.stream(ap[key], nil, 0)
+		}
+	}
+	return nil
+}
+
+// content visits a content stream and the streams its resources draw
+func (w *resourceWalk) content(content []byte, res types.Dict, ref *types.IndirectRef, depth int) {
+	w.visit(content, res, ref)
+	if res == nil || depth >= maxFormDepth {
+		return
+	}
+	if xobjs, err := w.xRefTable.DereferenceDict(res["XObject"]); err == nil {
+		for _, name := range sortedKeys(xobjs) {
+			if sd, _, err := w.xRefTable.DereferenceStreamDict(xobjs[name]); err == nil && sd != nil {
+				if st := sd.Subtype(); st != nil && *st == "Form" {
+					w.stream(xobjs[name], res, depth+1)
+				}
+			}
+		}
+	}
+	if patterns, err := w.xRefTable.DereferenceDict(res["Pattern"]); err == nil {
+		for _, name := range sortedKeys(patterns) {
+			// Only tiling patterns are streams with content of their own
+			if sd, _, err := w.xRefTable.DereferenceStreamDict(patterns[name]); err == nil && sd != nil {
+				w.stream(patterns[name], res, depth+1)
+			}
+		}
+	}
+	if fonts, err := w.xRefTable.DereferenceDict(res["Font"]); err == nil {
+		for _, name := range sortedKeys(fonts) {
+			font, err := w.xRefTable.DereferenceDict(fonts[name])
+			if err != nil || font == nil {
+				continue
+			}
+			if st := font.NameEntry("Subtype"); st == nil || *st != "Type3" {
+				continue
+			}
+			if ref, ok := fonts[name].(types.IndirectRef); ok {
+				if w.seen[ref.ObjectNumber.Value()] {
+					continue
+				}
+				w.seen[ref.ObjectNumber.Value()] = true
+			}
+			fontRes := res
+			if d, err := w.xRefTable.DereferenceDict(font["Resources"]); err == nil && d != nil {
+				fontRes = d
+			}
+			procs, err := w.xRefTable.DereferenceDict(font["CharProcs"])
+			if err != nil {
+				continue
+			}
+			for _, glyph := range sortedKeys(procs) {
+				w.stream(procs[glyph], fontRes, depth+1)
+			}
+		}
+	}
+}
+
+// stream visits a form-like stream, which uses the parent resources when it
+// has none of its own
+func (w *resourceWalk) stream(o types.Object, parent types.Dict, depth int) {
+	ref, ok := o.(types.IndirectRef)
+	if ok {
+		if w.seen[ref.ObjectNumber.Value()] {
+			return
+		}
+		w.seen[ref.ObjectNumber.Value()] = true
+	}
+	sd, _, err := w.xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return
+	}
+	res := parent
+	if d, err := w.xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		res = d
+	}
+	var content []byte
+	if err := sd.Decode(); err == nil {
+		content = sd.Content
+	}
+	if ok {
+		w.content(content, res, &ref, depth)
+	} else {
+		w.content(content, res, nil, depth)
+	}
+}
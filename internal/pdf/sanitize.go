@@ -0,0 +1,507 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// sanitizeAlways are the actions removed wherever they appear
+var sanitizeAlways = map[string]bool{"JavaScript": true, "Launch": true}
+
+// sanitizeAutomatic are the actions removed when they run without the
+// reader clicking anything, as open actions and additional actions do
+var sanitizeAutomatic = map[string]bool{
+	"URI": true, "SubmitForm": true, "ImportData": true, "GoToR": true, "GoToE": true, "Rendition": true,
+}
+
+// executableExtensions are file name extensions of programs and scripts
+var executableExtensions = map[string]bool{
+	".exe": true, ".com": true, ".scr": true, ".pif": true, ".bat": true, ".cmd": true, ".msi": true,
+	".dll": true, ".cpl": true, ".vbs": true, ".vbe": true, ".js": true, ".jse": true, ".wsf": true,
+	".wsh": true, ".hta": true, ".ps1": true, ".psm1": true, ".lnk": true, ".reg": true, ".jar": true,
+	".sh": true, ".app": true, ".dmg": true, ".pkg": true, ".command": true, ".apk": true, ".deb": true,
+	".rpm": true, ".elf": true, ".bin": true, ".run": true,
+}
+
+// executableMagic are the leading bytes of native executables and scripts
+var executableMagic = [][]byte{
+	[]byte("MZ"),             // Windows
+	[]byte("\x7fELF"),        // Linux and other Unix systems
+	{0xfe, 0xed, 0xfa, 0xce}, // Mach-O
+	{0xfe, 0xed, 0xfa, 0xcf}, // Mach-O 64-bit
+	{0xce, 0xfa, 0xed, 0xfe}, // Mach-O, little endian
+	{0xcf, 0xfa, 0xed, 0xfe}, // Mach-O 64-bit, little endian
+	{0xca, 0xfe, 0xba, 0xbe}, // Mach-O universal binary or Java class
+	[]byte("#!"),             // Unix script
+}
+
+// Sanitize writes a copy of a PDF without JavaScript, launch actions,
+// embedded executables and actions that open URIs or other files on their
+// own, keeping the visible content. Clicking a link to a web page still
+// works. The returned report lists everything removed.
+func (s *Service) Sanitize(config models.SanitizeConfig) (*models.SanitizeReport, error) {
+	if !utils.IsPDF(config.InputFile) {
+		return nil, fmt.Errorf("input file must be a PDF")
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, err := api.ReadContextFile(config.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	report := &models.SanitizeReport{}
+	z := &sanitizer{ctx: ctx, xRefTable: ctx.XRefTable, removed: map[int]bool{}, report: report}
+	if err := z.document(); err != nil {
+		return nil, err
+	}
+
+	if err := api.WriteContextFile(ctx, config.OutputFile); err != nil {
+		return nil, err
+	}
+
+	if config.ReportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(config.ReportFile, append(data, '\n'), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// sanitizer removes active content from a document in place
+type sanitizer struct {
+	ctx       *model.Context
+	xRefTable *model.XRefTable
+	removed   map[int]bool // action and file specification objects already removed
+	report    *models.SanitizeReport
+}
+
+func (z *sanitizer) add(kind, location string, page, obj int, detail string) {
+	z.report.Removed = append(z.report.Removed, models.SanitizeRemoval{
+		Kind: kind, Location: location, Page: page, Object: obj, Detail: detail,
+	})
+}
+
+// document sanitizes the catalog, the name trees, the bookmarks, every page
+// and the form
+func (z *sanitizer) document() error {
+	catalog, err := z.xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+
+	// An open action may also be a plain destination, which is kept
+	if d, err := z.xRefTable.DereferenceDict(catalog["OpenAction"]); err == nil && d != nil {
+		z.entry(catalog, "OpenAction", true, "document open action", 0)
+	}
+	z.additionalActions(catalog, "document action", 0)
+
+	if names, err := z.xRefTable.DereferenceDict(catalog["Names"]); err == nil && names != nil {
+		if names["JavaScript"] != nil {
+			z.scripts(names["JavaScript"], 0)
+			delete(names, "JavaScript")
+			delete(z.xRefTable.Names, "JavaScript")
+		}
+		if names["EmbeddedFiles"] != nil && z.embeddedFiles(names["EmbeddedFiles"], 0) {
+			// Keep pdfcpu from writing back its cached copy of the tree
+			delete(z.xRefTable.Names, "EmbeddedFiles")
+		}
+	}
+
+	if outlines, err := z.xRefTable.DereferenceDict(catalog["Outlines"]); err == nil && outlines != nil {
+		z.bookmarks(outlines["First"])
+	}
+
+	// Pages go before the form so widget actions are reported with their page
+	for page := 1; page <= z.ctx.PageCount; page++ {
+		if err := z.page(page); err != nil {
+			return fmt.Errorf("failed to sanitize page %d: %w", page, err)
+		}
+	}
+
+	if form, err := z.xRefTable.DereferenceDict(catalog["AcroForm"]); err == nil && form != nil {
+		z.fields(form["Fields"], 0)
+		if form["XFA"] != nil && z.xfaHasScripts(form["XFA"]) {
+			delete(form, "XFA")
+			delete(catalog, "NeedsRendering")
+			z.add("XFA", "form", 0, 0, "XFA form with scripts")
+		}
+	}
+	return nil
+}
+
+// page sanitizes the page actions and the annotations of a page, removing
+// attachment annotations holding executables
+func (z *sanitizer) page(page int) error {
+	d, _, _, err := z.ctx.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	z.additionalActions(d, "page action", page)
+
+	annots, err := z.xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || annots == nil {
+		return nil
+	}
+	kept := make(types.Array, 0, len(annots))
+	for _, o := range annots {
+		annot, err := z.xRefTable.DereferenceDict(o)
+		if err != nil || annot == nil {
+			kept = append(kept, o)
+			continue
+		}
+		subtype := annotationSubtype(annot)
+		location := annotationWords(subtype) + " annotation"
+		if subtype == "FileAttachment" {
+			if name, ok := z.executable(annot["FS"]); ok {
+				z.add("EmbeddedFile", location, page, objectNumber(o), name)
+				continue
+			}
+		}
+		z.entry(annot, "A", false, location, page)
+		z.additionalActions(annot, location, page)
+		kept = append(kept, o)
+	}
+	if len(kept) < len(annots) {
+		d["Annots"] = kept
+	}
+	return nil
+}
+
+// fields removes the additional actions of form fields, which run while a
+// field is edited
+func (z *sanitizer) fields(o types.Object, depth int) {
+	fields, err := z.xRefTable.DereferenceArray(o)
+	if err != nil || depth > 32 {
+		return
+	}
+	for _, f := range fields {
+		field, err := z.xRefTable.DereferenceDict(f)
+		if err != nil || field == nil {
+			continue
+		}
+		z.additionalActions(field, "form field", 0)
+		z.fields(field["Kids"], depth+1)
+	}
+}
+
+// bookmarks sanitizes the actions of an outline item and its siblings and
+// children. Bookmark actions run only when clicked.
+func (z *sanitizer) bookmarks(o types.Object) {
+	seen := map[int]bool{}
+	var visit func(o types.Object, depth int)
+	visit = func(o types.Object, depth int) {
+		for o != nil && depth <= 32 {
+			if ref, ok := o.(types.IndirectRef); ok {
+				if seen[ref.ObjectNumber.Value()] {
+					return
+				}
+				seen[ref.ObjectNumber.Value()] = true
+			}
+			item, err := z.xRefTable.DereferenceDict(o)
+			if err != nil || item == nil {
+				return
+			}
+			z.entry(item, "A", false, "bookmark", 0)
+			visit(item["First"], depth+1)
+			o = item["Next"]
+		}
+	}
+	visit(o, 0)
+}
+
+// additionalActions removes the unsafe actions of an AA dictionary, and the
+// dictionary itself once it is empty. These actions run on their own.
+func (z *sanitizer) additionalActions(d types.Dict, location string, page int) {
+	aa, err := z.xRefTable.DereferenceDict(d["AA"])
+	if err != nil || aa == nil {
+		return
+	}
+	for _, key := range sortedKeys(aa) {
+		z.entry(aa, key, true, location, page)
+	}
+	if len(aa) == 0 {
+		delete(d, "AA")
+	}
+}
+
+// entry sanitizes the action in d[key], deleting the entry when nothing safe
+// is left
+func (z *sanitizer) entry(d types.Dict, key string, automatic bool, location string, page int) {
+	if d[key] == nil {
+		return
+	}
+	if a := z.action(d[key], automatic, location, page); a != nil {
+		d[key] = a
+	} else {
+		delete(d, key)
+	}
+}
+
+// action returns an action or array of actions without the unsafe ones,
+// following Next chains, or nil when none is left
+func (z *sanitizer) action(o types.Object, automatic bool, location string, page int) types.Object {
+	return z.actionDepth(o, automatic, location, page, 0)
+}
+
+func (z *sanitizer) actionDepth(o types.Object, automatic bool, location string, page, depth int) types.Object {
+	obj := objectNumber(o)
+	if z.removed[obj] {
+		return nil
+	}
+	if depth > 32 {
+		return o
+	}
+	v, err := z.xRefTable.Dereference(o)
+	if err != nil {
+		return o
+	}
+
+	switch v := v.(type) {
+	case types.Array:
+		var kept types.Array
+		for _, next := range v {
+			if a := z.actionDepth(next, automatic, location, page, depth+1); a != nil {
+				kept = append(kept, a)
+			}
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		return kept
+
+	case types.Dict:
+		kind := ""
+		if st := v.NameEntry("S"); st != nil {
+			kind = *st
+		}
+		if kind == "Rendition" && v["JS"] != nil {
+			kind = "JavaScript"
+		}
+		if sanitizeAlways[kind] || automatic && sanitizeAutomatic[kind] {
+			z.add(kind, location, page, obj, z.actionDetail(v, kind))
+			if obj > 0 {
+				z.removed[obj] = true
+			}
+			return nil
+		}
+		if v["Next"] != nil {
+			z.entry(v, "Next", automatic, location, page)
+		}
+	}
+	return o
+}
+
+// actionDetail describes what an action runs or opens
+func (z *sanitizer) actionDetail(d types.Dict, kind string) string {
+	switch kind {
+	case "JavaScript":
+		return z.script(d["JS"])
+	case "URI":
+		return annotationText(z.xRefTable, d, "URI")
+	case "Launch":
+		if name := z.fileSpecName(d["F"]); name != "" {
+			return name
+		}
+		if win, err := z.xRefTable.DereferenceDict(d["Win"]); err == nil && win != nil {
+			return annotationText(z.xRefTable, win, "F")
+		}
+	case "SubmitForm", "ImportData", "GoToR", "GoToE":
+		return z.fileSpecName(d["F"])
+	}
+	return ""
+}
+
+// script returns the start of a script held in a string or stream
+func (z *sanitizer) script(o types.Object) string {
+	v, err := z.xRefTable.Dereference(o)
+	if err != nil || v == nil {
+		return ""
+	}
+	var text string
+	if sd, ok := v.(types.StreamDict); ok {
+		if err := sd.Decode(); err != nil {
+			return ""
+		}
+		text = string(sd.Content)
+	} else if s, err := types.StringOrHexLiteral(v); err == nil && s != nil {
+		text = *s
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if r := []rune(text); len(r) > 80 {
+		text = string(r[:80]) + "…"
+	}
+	return text
+}
+
+// scripts reports the document-level scripts of the JavaScript name tree
+func (z *sanitizer) scripts(o types.Object, depth int) {
+	node, err := z.xRefTable.DereferenceDict(o)
+	if err != nil || node == nil || depth > 32 {
+		return
+	}
+	if kids, err := z.xRefTable.DereferenceArray(node["Kids"]); err == nil {
+		for _, kid := range kids {
+			z.scripts(kid, depth+1)
+		}
+	}
+	names, err := z.xRefTable.DereferenceArray(node["Names"])
+	if err != nil {
+		return
+	}
+	for i := 0; i+1 < len(names); i += 2 {
+		name := ""
+		if s, err := types.StringOrHexLiteral(names[i]); err == nil && s != nil {
+			name = *s
+		}
+		detail := name
+		if action, err := z.xRefTable.DereferenceDict(names[i+1]); err == nil && action != nil {
+			detail = strings.TrimPrefix(name+": "+z.script(action["JS"]), ": ")
+		}
+		z.add("JavaScript", "document-level script", 0, objectNumber(names[i+1]), detail)
+	}
+}
+
+// embeddedFiles drops executables from the leaves of the EmbeddedFiles
+// name tree and reports whether any were dropped
+func (z *sanitizer) embeddedFiles(o types.Object, depth int) bool {
+	node, err := z.xRefTable.DereferenceDict(o)
+	if err != nil || node == nil || depth > 32 {
+		return false
+	}
+	changed := false
+	if kids, err := z.xRefTable.DereferenceArray(node["Kids"]); err == nil {
+		for _, kid := range kids {
+			if z.embeddedFiles(kid, depth+1) {
+				changed = true
+			}
+		}
+	}
+	names, err := z.xRefTable.DereferenceArray(node["Names"])
+	if err != nil || names == nil {
+		return changed
+	}
+	kept := make(types.Array, 0, len(names))
+	for i := 0; i+1 < len(names); i += 2 {
+		if name, ok := z.executable(names[i+1]); ok {
+			z.add("EmbeddedFile", "embedded files", 0, objectNumber(names[i+1]), name)
+			continue
+		}
+		kept = append(kept, names[i], names[i+1])
+	}
+	if len(kept) < len(names) {
+		node["Names"] = kept
+		changed = true
+	}
+	return changed
+}
+
+// executable reports whether a file specification embeds a program or
+// script, judged by its file name and by the leading bytes of its content,
+// and returns the file name
+func (z *sanitizer) executable(o types.Object) (string, bool) {
+	name := z.fileSpecName(o)
+	fs, err := z.xRefTable.DereferenceDict(o)
+	if err != nil || fs == nil {
+		return name, false
+	}
+	ef, err := z.xRefTable.DereferenceDict(fs["EF"])
+	if err != nil || ef == nil {
+		// A reference to an outside file, not an embedded one
+		return name, false
+	}
+	if executableExtensions[strings.ToLower(filepath.Ext(name))] {
+		return name, true
+	}
+	for _, key := range []string{"F", "UF"} {
+		sd, _, err := z.xRefTable.DereferenceStreamDict(ef[key])
+		if err != nil || sd == nil || sd.Decode() != nil {
+			continue
+		}
+		for _, magic := range executableMagic {
+			if bytes.HasPrefix(sd.Content, magic) {
+				return name, true
+			}
+		}
+	}
+	return name, false
+}
+
+// fileSpecName returns the file name or URL of a file specification
+func (z *sanitizer) fileSpecName(o types.Object) string {
+	v, err := z.xRefTable.Dereference(o)
+	if err != nil || v == nil {
+		return ""
+	}
+	fs, ok := v.(types.Dict)
+	if !ok {
+		if s, err := types.StringOrHexLiteral(v); err == nil && s != nil {
+			return *s
+		}
+		return ""
+	}
+	for _, key := range []string{"UF", "F", "Unix", "DOS", "Mac"} {
+		if name := annotationText(z.xRefTable, fs, key); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// xfaHasScripts reports whether an XFA form, a stream or an array of names
+// and streams, contains scripts
+func (z *sanitizer) xfaHasScripts(o types.Object) bool {
+	parts := types.Array{o}
+	if arr, err := z.xRefTable.DereferenceArray(o); err == nil && arr != nil {
+		parts = arr
+	}
+	for _, part := range parts {
+		sd, _, err := z.xRefTable.DereferenceStreamDict(part)
+		if err != nil || sd == nil || sd.Decode() != nil {
+			continue
+		}
+		if bytes.Contains(bytes.ToLower(sd.Content), []byte("<script")) {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationWords spells an annotation subtype as lower case words, as in
+// "file attachment" for FileAttachment
+func annotationWords(subtype string) string {
+	var b strings.Builder
+	for i, r := range subtype {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// objectNumber returns the object number of a reference, or 0
+func objectNumber(o types.Object) int {
+	if ref, ok := o.(types.IndirectRef); ok {
+		return ref.ObjectNumber.Value()
+	}
+	return 0
+}
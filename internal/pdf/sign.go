@@ -0,0 +1,516 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// Sign adds a PAdES baseline signature (B-B, or B-T with a timestamp) to a
+// PDF. The signature is appended as an incremental update, so the original
+// bytes and any earlier signatures stay intact.
+func (s *Service) Sign(config models.SignConfig) error {
+	if !utils.IsPDF(config.InputFile) {
+		return fmt.Errorf("input file must be a PDF")
+	}
+	signer, err := loadSigner(config.CertFile, config.Password)
+	if err != nil {
+		return err
+	}
+	var tsa *cmsSigner
+	if config.Timestamp != nil {
+		if tsa, err = loadSigner(config.Timestamp.CertFile, config.Timestamp.Password); err != nil {
+			return fmt.Errorf("timestamp authority: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(config.InputFile)
+	if err != nil {
+		return err
+	}
+	ctx, err := api.ReadContext(bytes.NewReader(data), model.NewDefaultConfiguration())
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if ctx.Encrypt != nil {
+		return fmt.Errorf("encrypted PDFs cannot be signed")
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	prev, err := lastXRefOffset(data)
+	if err != nil {
+		return err
+	}
+
+	page, rect := 1, [4]float64{}
+	if a := config.Appearance; a != nil {
+		if a.Page < 1 || a.Page > ctx.PageCount {
+			return fmt.Errorf("page %d out of range (1-%d)", a.Page, ctx.PageCount)
+		}
+		if a.Rect[2] <= a.Rect[0] || a.Rect[3] <= a.Rect[1] {
+			return fmt.Errorf("the signature rectangle is empty")
+		}
+		page, rect = a.Page, a.Rect
+	}
+
+	u := newIncrementalUpdate(ctx.XRefTable, ctx.Read.UsingXRefStreams)
+	now := time.Now()
+
+	sig := types.Dict{
+		"Type":      types.Name("Sig"),
+		"Filter":    types.Name("Adobe.PPKLite"),
+		"SubFilter": types.Name("ETSI.CAdES.detached"),
+		"M":         types.StringLiteral(types.DateString(now)),
+	}
+	for key, value := range map[string]string{"Name": signer.cert.Subject.CommonName, "Reason": config.Reason, "Location": config.Location} {
+		if value == "" {
+			continue
+		}
+		text, err := pdfText(value)
+		if err != nil {
+			return err
+		}
+		sig[key] = text
+	}
+	sigRef, err := u.add(sig)
+	if err != nil {
+		return err
+	}
+
+	pageDict, pageRef, _, err := ctx.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	root, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	formOwner := *ctx.Root
+	if ref, ok := root["AcroForm"].(types.IndirectRef); ok {
+		formOwner = ref
+	}
+	form, err := ctx.DereferenceDict(root["AcroForm"])
+	if err != nil {
+		return err
+	}
+	if form == nil {
+		form = types.Dict{}
+		root["AcroForm"] = form
+	}
+
+	name, err := pdfText(signatureFieldName(ctx.XRefTable, form))
+	if err != nil {
+		return err
+	}
+	field := types.Dict{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name("Widget"),
+		"FT":      types.Name("Sig"),
+		"T":       name,
+		"V":       *sigRef,
+		"P":       *pageRef,
+		"F":       types.Integer(132), // print, locked
+		"Rect":    types.NewNumberArray(rect[0], rect[1], rect[2], rect[3]),
+	}
+	if config.Appearance != nil {
+		ap, err := signatureAppearance(ctx.XRefTable, signer, config, now)
+		if err != nil {
+			return err
+		}
+		field["AP"] = types.Dict{"N": *ap}
+	}
+	fieldRef, err := u.add(field)
+	if err != nil {
+		return err
+	}
+
+	if err := u.appendToArray(*pageRef, pageDict, "Annots", *fieldRef); err != nil {
+		return err
+	}
+	if err := u.appendToArray(formOwner, form, "Fields", *fieldRef); err != nil {
+		return err
+	}
+	form["SigFlags"] = types.Integer(3) // signatures exist, append only
+	u.touch(formOwner)
+
+	// Reserve room for the CMS signature and its certificates
+	reserve := 8192
+	for _, c := range signer.certificates() {
+		reserve += len(c.Raw)
+	}
+	if tsa != nil {
+		reserve += 4096
+		for _, c := range tsa.certificates() {
+			reserve += len(c.Raw)
+		}
+	}
+
+	out, byteRangeAt, contentsAt, err := u.write(data, prev, sigRef.ObjectNumber.Value(), reserve)
+	if err != nil {
+		return err
+	}
+
+	// Sign everything but the hex string reserved for the signature
+	contentsEnd := contentsAt + 2*reserve + 2
+	byteRange := fmt.Sprintf("[0 %d %d %d]", contentsAt, contentsEnd, len(out)-contentsEnd)
+	copy(out[byteRangeAt:], byteRange)
+
+	h := sha256.New()
+	h.Write(out[:contentsAt])
+	h.Write(out[contentsEnd:])
+	cms, err := signer.sign(oidData, h.Sum(nil), nil, nil, tsa)
+	if err != nil {
+		return err
+	}
+	if len(cms) > reserve {
+		return fmt.Errorf("signature too large (%d bytes)", len(cms))
+	}
+	hex.Encode(out[contentsAt+1:], cms)
+
+	if err := utils.EnsureDir(filepath.Dir(config.OutputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return os.WriteFile(config.OutputFile, out, 0644)
+}
+
+// pdfText encodes s as a PDF text string, using UTF-16 when it is not ASCII
+func pdfText(s string) (types.StringLiteral, error) {
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			s = types.EncodeUTF16String(s)
+			break
+		}
+	}
+	esc, err := types.Escape(s)
+	if err != nil {
+		return "", err
+	}
+	return types.StringLiteral(*esc), nil
+}
+
+// signatureFieldName returns the first unused name SignatureN
+func signatureFieldName(xRefTable *model.XRefTable, form types.Dict) string {
+	used := map[string]bool{}
+	var walk func(o types.Object, depth int)
+	walk = func(o types.Object, depth int) {
+		arr, err := xRefTable.DereferenceArray(o)
+		if err != nil || depth > 32 {
+			return
+		}
+		for _, o := range arr {
+			d, err := xRefTable.DereferenceDict(o)
+			if err != nil || d == nil {
+				continue
+			}
+			if t := d.StringLiteralEntry("T"); t != nil {
+				if s, err := types.StringLiteralToString(*t); err == nil {
+					used[s] = true
+				}
+			}
+			walk(d["Kids"], depth+1)
+		}
+	}
+	walk(form["Fields"], 0)
+
+	for i := 1; ; i++ {
+		if name := fmt.Sprintf("Signature%d", i); !used[name] {
+			return name
+		}
+	}
+}
+
+// signatureAppearance draws the signer details, and the optional picture
+// next to them, into the appearance of a visible signature
+func signatureAppearance(xRefTable *model.XRefTable, signer *cmsSigner, config models.SignConfig, when time.Time) (*types.IndirectRef, error) {
+	a := config.Appearance
+	w, h := a.Rect[2]-a.Rect[0], a.Rect[3]-a.Rect[1]
+
+	lines := []string{
+		"Digitally signed by " + signer.cert.Subject.CommonName,
+		"Date: " + when.Format("2006-01-02 15:04:05 -07:00"),
+	}
+	if config.Reason != "" {
+		lines = append(lines, "Reason: "+config.Reason)
+	}
+	if config.Location != "" {
+		lines = append(lines, "Location: "+config.Location)
+	}
+
+	var content strings.Builder
+	resources := types.Dict{"Font": types.Dict{"Helv": types.Dict{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name("Helvetica"),
+		"Encoding": types.Name("WinAnsiEncoding"),
+	}}}
+
+	textX := 2.0
+	if a.ImageFile != "" {
+		f, err := os.Open(a.ImageFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature image: %w", err)
+		}
+		defer f.Close()
+		sd, iw, ih, err := model.CreateImageStreamDict(xRefTable, f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature image: %w", err)
+		}
+		img, err := xRefTable.IndRefForNewObject(*sd)
+		if err != nil {
+			return nil, err
+		}
+		resources["XObject"] = types.Dict{"Img": *img}
+
+		// The picture takes the left half and keeps its proportions
+		scale := math.Min(w/2/float64(iw), h/float64(ih))
+		dw, dh := float64(iw)*scale, float64(ih)*scale
+		fmt.Fprintf(&content, "q %s 0 0 %s %s %s cm /Img Do Q\n",
+			formatNumber(dw), formatNumber(dh), formatNumber((w/2-dw)/2), formatNumber((h-dh)/2))
+		textX = w/2 + 2
+	}
+
+	// Fit the lines into the remaining box
+	size := math.Min(10, h/(float64(len(lines))*1.2))
+	for _, line := range lines {
+		if lw := font.TextWidth(line, "Helvetica", 1000) / 1000; lw > 0 {
+			size = math.Min(size, (w-textX-2)/lw)
+		}
+	}
+	content.WriteString("BT 0 g\n")
+	fmt.Fprintf(&content, "/Helv %s Tf\n", formatNumber(size))
+	top := (h + float64(len(lines))*size*1.2) / 2
+	for i, line := range lines {
+		text, err := types.Escape(types.UTF8ToCP1252(line))
+		if err != nil {
+			return nil, err
+		}
+		y := top - float64(i+1)*size*1.2 + size*0.25
+		fmt.Fprintf(&content, "1 0 0 1 %s %s Tm (%s) Tj\n", formatNumber(textX), formatNumber(y), *text)
+	}
+	content.WriteString("ET")
+
+	sd, err := xRefTable.NewStreamDictForBuf([]byte(content.String()))
+	if err != nil {
+		return nil, err
+	}
+	sd.Dict["Type"] = types.Name("XObject")
+	sd.Dict["Subtype"] = types.Name("Form")
+	sd.Dict["BBox"] = types.NewNumberArray(0, 0, w, h)
+	sd.Dict["Resources"] = resources
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	return xRefTable.IndRefForNewObject(*sd)
+}
+
+var startXRef = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+
+// lastXRefOffset returns the offset of the last cross-reference section
+func lastXRefOffset(data []byte) (int, error) {
+	tail := data[max(0, len(data)-1024):]
+	m := startXRef.FindSubmatch(tail)
+	if m == nil {
+		return 0, fmt.Errorf("cannot sign this PDF: missing startxref, please repair it first")
+	}
+	return strconv.Atoi(string(m[1]))
+}
+
+// incrementalUpdate collects the objects added or changed in a document so
+// they can be appended to the original file
+type incrementalUpdate struct {
+	xRefTable  *model.XRefTable
+	xRefStream bool         // the original uses cross-reference streams
+	size       int          // object count of the original
+	changed    map[int]bool // original objects to write again
+}
+
+func newIncrementalUpdate(xRefTable *model.XRefTable, xRefStream bool) *incrementalUpdate {
+	// New objects must get new numbers, as reusing a freed number would
+	// need its next generation number
+	if head, err := xRefTable.Free(0); err == nil && head.Offset != nil {
+		*head.Offset = 0
+	}
+	return &incrementalUpdate{xRefTable: xRefTable, xRefStream: xRefStream, size: *xRefTable.Size, changed: map[int]bool{}}
+}
+
+func (u *incrementalUpdate) add(o types.Object) (*types.IndirectRef, error) {
+	return u.xRefTable.IndRefForNewObject(o)
+}
+
+// touch marks an original object as changed
+func (u *incrementalUpdate) touch(ref types.IndirectRef) {
+	if nr := ref.ObjectNumber.Value(); nr < u.size {
+		u.changed[nr] = true
+	}
+}
+
+// appendToArray appends ref to the array d[key], which is written again as
+// part of owner unless it is an object of its own
+func (u *incrementalUpdate) appendToArray(owner types.IndirectRef, d types.Dict, key string, ref types.IndirectRef) error {
+	arr, err := u.xRefTable.DereferenceArray(d[key])
+	if err != nil {
+		return err
+	}
+	arr = append(append(types.Array(nil), arr...), ref)
+	if arrRef, ok := d[key].(types.IndirectRef); ok {
+		entry, found := u.xRefTable.FindTableEntryForIndRef(&arrRef)
+		if !found {
+			return fmt.Errorf("missing object %d", arrRef.ObjectNumber)
+		}
+		entry.Object = arr
+		u.touch(arrRef)
+		return nil
+	}
+	d[key] = arr
+	u.touch(owner)
+	return nil
+}
+
+// write appends the update to data. The signature dictionary sigNr gets a
+// ByteRange placeholder and a Contents string of reserve zero bytes; their
+// offsets are returned.
+func (u *incrementalUpdate) write(data []byte, prev, sigNr, reserve int) (out []byte, byteRangeAt, contentsAt int, err error) {
+	var nrs []int
+	for nr := range u.changed {
+		nrs = append(nrs, nr)
+	}
+	for nr := u.size; nr < *u.xRefTable.Size; nr++ {
+		nrs = append(nrs, nr)
+	}
+	sort.Ints(nrs)
+
+	var buf bytes.Buffer
+	buf.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	offsets := map[int]int{}
+	for _, nr := range nrs {
+		entry, found := u.xRefTable.FindTableEntryLight(nr)
+		if !found || entry.Object == nil {
+			return nil, 0, 0, fmt.Errorf("missing object %d", nr)
+		}
+		offsets[nr] = buf.Len()
+		gen := 0
+		if nr < u.size && entry.Generation != nil {
+			gen = *entry.Generation
+		}
+		fmt.Fprintf(&buf, "%d %d obj\n", nr, gen)
+
+		switch o := entry.Object.(type) {
+		case types.StreamDict:
+			o.Dict["Length"] = types.Integer(len(o.Raw))
+			buf.WriteString(o.Dict.PDFString())
+			buf.WriteString("\nstream\n")
+			buf.Write(o.Raw)
+			buf.WriteString("\nendstream")
+		case types.Dict:
+			if nr != sigNr {
+				buf.WriteString(o.PDFString())
+				break
+			}
+			buf.WriteString(strings.TrimSuffix(o.PDFString(), ">>"))
+			buf.WriteString("/ByteRange ")
+			byteRangeAt = buf.Len()
+			buf.WriteString("[0 0 0 0]" + strings.Repeat(" ", 40))
+			buf.WriteString("/Contents ")
+			contentsAt = buf.Len()
+			buf.WriteString("<" + strings.Repeat("0", 2*reserve) + ">>>")
+		default:
+			buf.WriteString(entry.Object.PDFString())
+		}
+		buf.WriteString("\nendobj\n")
+	}
+
+	trailer := types.Dict{
+		"Size": types.Integer(*u.xRefTable.Size),
+		"Root": *u.xRefTable.Root,
+		"Prev": types.Integer(prev),
+	}
+	if u.xRefTable.Info != nil {
+		trailer["Info"] = *u.xRefTable.Info
+	}
+	if u.xRefTable.ID != nil {
+		trailer["ID"] = u.xRefTable.ID
+	}
+
+	xrefAt := buf.Len()
+	if u.xRefStream {
+		u.writeXRefStream(&buf, trailer, nrs, offsets, xrefAt)
+	} else {
+		buf.WriteString("xref\n")
+		for i := 0; i < len(nrs); {
+			j := i
+			for j+1 < len(nrs) && nrs[j+1] == nrs[j]+1 {
+				j++
+			}
+			fmt.Fprintf(&buf, "%d %d\n", nrs[i], j-i+1)
+			for _, nr := range nrs[i : j+1] {
+				gen := 0
+				if entry, found := u.xRefTable.FindTableEntryLight(nr); found && nr < u.size && entry.Generation != nil {
+					gen = *entry.Generation
+				}
+				fmt.Fprintf(&buf, "%010d %05d n\r\n", offsets[nr], gen)
+			}
+			i = j + 1
+		}
+		fmt.Fprintf(&buf, "trailer\n%s\n", trailer.PDFString())
+	}
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefAt)
+	return buf.Bytes(), byteRangeAt, contentsAt, nil
+}
+
+// writeXRefStream writes the cross-reference section as a stream object
+func (u *incrementalUpdate) writeXRefStream(buf *bytes.Buffer, trailer types.Dict, nrs []int, offsets map[int]int, at int) {
+	nr := *u.xRefTable.Size
+	nrs = append(nrs, nr)
+	offsets[nr] = at
+
+	var index types.Array
+	var entries []byte
+	for i := 0; i < len(nrs); {
+		j := i
+		for j+1 < len(nrs) && nrs[j+1] == nrs[j]+1 {
+			j++
+		}
+		index = append(index, types.Integer(nrs[i]), types.Integer(j-i+1))
+		for _, n := range nrs[i : j+1] {
+			gen := 0
+			if entry, found := u.xRefTable.FindTableEntryLight(n); found && n < u.size && entry.Generation != nil {
+				gen = *entry.Generation
+			}
+			entries = append(entries, 1)
+			entries = binary.BigEndian.AppendUint32(entries, uint32(offsets[n]))
+			entries = binary.BigEndian.AppendUint16(entries, uint16(gen))
+		}
+		i = j + 1
+	}
+
+	trailer["Type"] = types.Name("XRef")
+	trailer["Size"] = types.Integer(nr + 1)
+	trailer["Index"] = index
+	trailer["W"] = types.NewIntegerArray(1, 4, 2)
+	trailer["Length"] = types.Integer(len(entries))
+	fmt.Fprintf(buf, "%d 0 obj\n%s\nstream\n", nr, trailer.PDFString())
+	buf.Write(entries)
+	buf.WriteString("\nendstream\nendobj\n")
+}
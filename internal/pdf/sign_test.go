@@ -0,0 +1,130 @@
+package pdf
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pdf-toolbox/pkg/models"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// writeTestCertificate writes a self-signed signing certificate with its key
+// to a .p12 file and the certificate alone to a .pem trust store
+func writeTestCertificate(t *testing.T, dir, password string) (p12File, pemFile string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Signer", Organization: []string{"PDF Toolbox"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := pkcs12.Modern.Encode(key, cert, nil, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p12File = filepath.Join(dir, "signer.p12")
+	if err := os.WriteFile(p12File, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	pemFile = filepath.Join(dir, "trust.pem")
+	if err := os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p12File, pemFile
+}
+
+// checkSignatures verifies the signatures of a PDF and fails unless there are
+// want of them, all valid and trusted
+func checkSignatures(t *testing.T, s *Service, path, trustStore string, want int) []models.SignatureInfo {
+	t.Helper()
+	signatures, err := s.VerifySignatures(path, trustStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signatures) != want {
+		t.Fatalf("%s has %d signatures, want %d", filepath.Base(path), len(signatures), want)
+	}
+	for _, sig := range signatures {
+		if sig.Status != "valid" || !sig.Trusted {
+			t.Errorf("%s: signature %s is %s (trusted %v): %s %v",
+				filepath.Base(path), sig.Field, sig.Status, sig.Trusted, sig.Details, sig.Problems)
+		}
+	}
+	return signatures
+}
+
+func TestSignAndResign(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.pdf")
+	signed := filepath.Join(dir, "signed.pdf")
+	resigned := filepath.Join(dir, "resigned.pdf")
+
+	writeTestPDF(t, input, []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		testStream("", "BT /F1 12 Tf 72 700 Td (Contract) Tj ET"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	})
+	p12File, trustStore := writeTestCertificate(t, dir, "secret")
+
+	s := NewService()
+	if err := s.Sign(models.SignConfig{
+		InputFile:  input,
+		OutputFile: signed,
+		CertFile:   p12File,
+		Password:   "secret",
+		Reason:     "Approved",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	first := checkSignatures(t, s, signed, trustStore, 1)
+	if !first[0].WholeFile {
+		t.Errorf("the only signature does not cover the whole file")
+	}
+
+	// A second signature is appended, leaving the first one valid
+	if err := s.Sign(models.SignConfig{
+		InputFile:  signed,
+		OutputFile: resigned,
+		CertFile:   p12File,
+		Password:   "secret",
+		Appearance: &models.SignatureAppearance{Page: 1, Rect: [4]float64{36, 36, 236, 96}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	both := checkSignatures(t, s, resigned, trustStore, 2)
+	if both[0].Field == both[1].Field {
+		t.Errorf("both signatures use the field %s", both[0].Field)
+	}
+	// Only the new signature reaches the end of the file; the first one
+	// verifies although the second revision was appended after it
+	if both[0].WholeFile == both[1].WholeFile {
+		t.Errorf("want exactly one signature covering the whole file, got %v and %v", both[0].WholeFile, both[1].WholeFile)
+	}
+}
@@ -0,0 +1,173 @@
+package pdf
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/internal/utils"
+	"pdf-toolbox/pkg/models"
+)
+
+// trustMu guards model.UserCertPool, which pdfcpu reads as the trust store
+var trustMu sync.Mutex
+
+// VerifySignatures lists the digital signatures of a PDF and verifies them
+// against the certificates in trustStore, a certificate file (.pem, .crt,
+// .cer, .p7c) or a directory of them. With an empty trustStore no
+// certificate chain is trusted. Nothing is fetched from the network, so
+// revocation is only checked against data embedded in the PDF.
+func (s *Service) VerifySignatures(filePath, trustStore string) ([]models.SignatureInfo, error) {
+	if !utils.IsPDF(filePath) {
+		return nil, fmt.Errorf("file must be a PDF")
+	}
+
+	pool, err := loadTrustStore(trustStore)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.VALIDATESIGNATURE
+	conf.Offline = true
+	ctx, err := api.ReadValidateAndOptimize(f, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if len(ctx.Signatures) == 0 && ctx.URSignature == nil {
+		return nil, nil
+	}
+
+	trustMu.Lock()
+	saved := model.UserCertPool
+	model.UserCertPool = pool
+	results, err := pdfcpu.ValidateSignatures(f, ctx, true)
+	model.UserCertPool = saved
+	trustMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify signatures: %w", err)
+	}
+
+	list := make([]models.SignatureInfo, 0, len(results))
+	for _, r := range results {
+		list = append(list, signatureInfo(ctx, r, st.Size()))
+	}
+	return list, nil
+}
+
+// loadTrustStore reads the certificates of a file or directory into a pool
+func loadTrustStore(path string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if path == "" {
+		return pool, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+	if fi.IsDir() {
+		if _, err := pdfcpu.LoadCertificatesToCertPool(path, pool); err != nil {
+			return nil, fmt.Errorf("failed to read trust store: %w", err)
+		}
+		return pool, nil
+	}
+	certs, err := pdfcpu.LoadCertificates(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store %s: %w", path, err)
+	}
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+func signatureInfo(ctx *model.Context, r *model.SignatureValidationResult, fileSize int64) models.SignatureInfo {
+	info := models.SignatureInfo{
+		Field:       r.Details.FieldName,
+		Kind:        r.Signature.String(r.Status),
+		SigningTime: r.Details.SigningTime,
+		Reason:      r.Details.Reason,
+		Location:    r.Details.Location,
+		SubFilter:   r.Details.SubFilter,
+		Modified:    "unknown",
+		Status:      "unknown",
+		Details:     r.Reason.String(),
+		Problems:    r.Problems,
+	}
+	switch r.DocModified {
+	case model.True:
+		info.Modified = "yes"
+	case model.False:
+		info.Modified = "no"
+	}
+	switch r.Status {
+	case model.SignatureStatusValid:
+		info.Status = "valid"
+	case model.SignatureStatusInvalid:
+		info.Status = "invalid"
+	}
+
+	for _, signer := range r.Details.Signers {
+		if signer.Certificate != nil && info.Signer == "" {
+			info.Signer = signer.Certificate.Subject
+			info.Issuer = signer.Certificate.Issuer
+			info.Trusted = signer.Certificate.Trust.Status == model.True
+		}
+		info.Problems = append(info.Problems, signer.Problems...)
+	}
+	if info.Signer == "" {
+		info.Signer = r.Details.SignerIdentity
+	}
+	if info.Trusted && r.Reason == model.SignatureReasonCertNotTrusted {
+		// pdfcpu reports revocation it could not check as untrusted
+		info.Details = "certificate chain is trusted, revocation could not be checked offline"
+	}
+
+	info.ByteRange = signatureByteRange(ctx, r.Signature.ObjNr)
+	if n := len(info.ByteRange); n >= 2 {
+		info.WholeFile = info.ByteRange[n-2]+info.ByteRange[n-1] == fileSize
+	}
+	return info
+}
+
+// signatureByteRange returns the ByteRange of the signature dictionary of a
+// signature field
+func signatureByteRange(ctx *model.Context, fieldObjNr int) []int64 {
+	if fieldObjNr == 0 {
+		return nil
+	}
+	field, err := ctx.DereferenceDict(*types.NewIndirectRef(fieldObjNr, 0))
+	if err != nil || field == nil {
+		return nil
+	}
+	sig, err := ctx.DereferenceDict(field["V"])
+	if err != nil || sig == nil {
+		return nil
+	}
+	arr, err := ctx.DereferenceArray(sig["ByteRange"])
+	if err != nil {
+		return nil
+	}
+	var br []int64
+	for _, o := range arr {
+		if o, err := ctx.Dereference(o); err == nil {
+			br = append(br, int64(number(o)))
+		}
+	}
+	return br
+}
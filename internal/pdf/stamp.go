@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdf-toolbox/pkg/models"
+)
+
+// StampMargin is the distance in points between stamped text and the page edge
+const StampMargin = 24
+
+// StampTextWidth returns the width in points of text stamped in a core font
+func StampTextWidth(text, fontName string, fontSize int) float64 {
+	return font.TextWidth(text, fontName, fontSize)
+}
+
+// textStamp is a line of text drawn on top of a page
+type textStamp struct {
+	Text     string
+	Position models.Position
+	FontName string
+	FontSize int
+}
+
+// stampText writes inFile to outFile with the given stamps added per page number
+func stampText(inFile, outFile string, stamps map[int][]textStamp) error {
+	wms := make(map[int][]*model.Watermark, len(stamps))
+	for page, list := range stamps {
+		for _, st := range list {
+			wm, err := newTextWatermark(st)
+			if err != nil {
+				return err
+			}
+			wms[page] = append(wms[page], wm)
+		}
+	}
+	return api.AddWatermarksSliceMapFile(inFile, outFile, wms, nil)
+}
+
+func newTextWatermark(st textStamp) (*model.Watermark, error) {
+	pos := st.Position
+	switch pos {
+	case "":
+		pos = models.PosBottomCenter
+	case models.PosTopLeft, models.PosTopCenter, models.PosTopRight,
+		models.PosBottomLeft, models.PosBottomCenter, models.PosBottomRight:
+	default:
+		return nil, fmt.Errorf("unknown stamp position %q", pos)
+	}
+	fontName := st.FontName
+	if fontName == "" {
+		fontName = "Helvetica"
+	}
+	size := st.FontSize
+	if size <= 0 {
+		size = 10
+	}
+
+	// Offsets move the text inwards from the anchored edges
+	var dx, dy int
+	switch pos[0] {
+	case 't':
+		dy = -StampMargin
+	case 'b':
+		dy = StampMargin
+	}
+	switch pos[1] {
+	case 'l':
+		dx = StampMargin
+	case 'r':
+		dx = -StampMargin
+	}
+
+	desc := fmt.Sprintf("fontname:%s, points:%d, position:%s, offset:%d %d, scalefactor:1 abs, rotation:0, fillcolor:#000000",
+		fontName, size, pos, dx, dy)
+
+	// pdfcpu expands %p/%P itself; double '%' so text is stamped verbatim
+	text := strings.ReplaceAll(st.Text, "%", "%%")
+
+	return api.TextWatermark(text, desc, true, false, types.POINTS)
+}
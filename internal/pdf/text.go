@@ -0,0 +1,283 @@
+package pdf
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// fontInfo holds what is needed to split strings of one font into glyphs
+// and to place them
+type fontInfo struct {
+	twoByte      bool            // composite font with 2-byte codes
+	widths       map[int]float64 // glyph widths in text space units per unit of font size
+	defaultWidth float64
+	toUnicode    map[int]string
+	names        map[int]string // glyph names from the encoding differences
+	coreFont     string         // standard 14 font providing missing widths
+}
+
+// glyph is one character code of a shown string
+type glyph struct {
+	code  []byte
+	text  string
+	width float64 // horizontal displacement per unit of font size
+}
+
+// loadFont reads the encoding and metrics of a font dictionary. Fonts that
+// cannot be read fall back to single-byte codes of half an em.
+func loadFont(xRefTable *model.XRefTable, d types.Dict) *fontInfo {
+	f := &fontInfo{widths: map[int]float64{}, defaultWidth: 0.5}
+	if d == nil {
+		return f
+	}
+	subtype := ""
+	if st := d.NameEntry("Subtype"); st != nil {
+		subtype = *st
+	}
+
+	scale := 0.001
+	if subtype == "Type3" {
+		// Type 3 glyph space is defined by the font matrix
+		if m, err := xRefTable.DereferenceArray(d["FontMatrix"]); err == nil && len(m) == 6 {
+			scale = number(m[0])
+		}
+	}
+
+	if subtype == "Type0" {
+		f.twoByte = true
+		f.defaultWidth = 1
+		if descendants, err := xRefTable.DereferenceArray(d["DescendantFonts"]); err == nil && len(descendants) > 0 {
+			if cid, err := xRefTable.DereferenceDict(descendants[0]); err == nil && cid != nil {
+				if dw := cid.IntEntry("DW"); dw != nil {
+					f.defaultWidth = float64(*dw) * scale
+				}
+				if w, err := xRefTable.DereferenceArray(cid["W"]); err == nil {
+					f.cidWidths(xRefTable, w, scale)
+				}
+			}
+		}
+	} else {
+		first := 0
+		if fc := d.IntEntry("FirstChar"); fc != nil {
+			first = *fc
+		}
+		if w, err := xRefTable.DereferenceArray(d["Widths"]); err == nil {
+			for i, o := range w {
+				if o, err := xRefTable.Dereference(o); err == nil {
+					f.widths[first+i] = number(o) * scale
+				}
+			}
+		}
+		if desc, err := xRefTable.DereferenceDict(d["FontDescriptor"]); err == nil && desc != nil {
+			if o, err := xRefTable.Dereference(desc["MissingWidth"]); err == nil && o != nil {
+				f.defaultWidth = number(o) * scale
+			}
+		}
+		if name := d.NameEntry("BaseFont"); name != nil {
+			base := *name
+			if i := strings.IndexByte(base, '+'); i == 6 {
+				base = base[i+1:]
+			}
+			if font.IsCoreFont(base) {
+				f.coreFont = base
+			}
+		}
+		if enc, err := xRefTable.DereferenceDict(d["Encoding"]); err == nil && enc != nil {
+			f.names = encodingDifferences(xRefTable, enc)
+		}
+	}
+
+	if sd, _, err := xRefTable.DereferenceStreamDict(d["ToUnicode"]); err == nil && sd != nil {
+		if err := sd.Decode(); err == nil {
+			f.toUnicode, f.twoByte = parseToUnicode(sd.Content, f.twoByte)
+		}
+	}
+	return f
+}
+
+// cidWidths reads a W array: c [w1 w2 ...] or cFirst cLast w
+func (f *fontInfo) cidWidths(xRefTable *model.XRefTable, w types.Array, scale float64) {
+	for i := 0; i < len(w); {
+		start := int(number(w[i]))
+		if i+1 >= len(w) {
+			return
+		}
+		if arr, err := xRefTable.DereferenceArray(w[i+1]); err == nil && arr != nil {
+			for j, o := range arr {
+				f.widths[start+j] = number(o) * scale
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		end, width := int(number(w[i+1])), number(w[i+2])*scale
+		for c := start; c <= end && c-start < 65536; c++ {
+			f.widths[c] = width
+		}
+		i += 3
+	}
+}
+
+// encodingDifferences returns the glyph names set by the Differences array
+// of an encoding dictionary
+func encodingDifferences(xRefTable *model.XRefTable, enc types.Dict) map[int]string {
+	diffs, err := xRefTable.DereferenceArray(enc["Differences"])
+	if err != nil || diffs == nil {
+		return nil
+	}
+	names := map[int]string{}
+	code := 0
+	for _, o := range diffs {
+		switch o := o.(type) {
+		case types.Integer:
+			code = o.Value()
+		case types.Name:
+			names[code] = string(o)
+			code++
+		}
+	}
+	return names
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a ToUnicode CMap.
+// It also reports whether codes are two bytes long.
+func parseToUnicode(data []byte, twoByte bool) (map[int]string, bool) {
+	ops, err := parseContent(data)
+	if err != nil {
+		return nil, twoByte
+	}
+	m := map[int]string{}
+	for _, op := range ops {
+		switch op.Operator {
+		case "endcodespacerange":
+			if len(op.Operands) >= 1 {
+				twoByte = len(stringBytes(op.Operands[0])) == 2
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(op.Operands); i += 2 {
+				m[codeValue(stringBytes(op.Operands[i]))] = unicodeText(stringBytes(op.Operands[i+1]))
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(op.Operands); i += 3 {
+				lo, hi := codeValue(stringBytes(op.Operands[i])), codeValue(stringBytes(op.Operands[i+1]))
+				if hi < lo || hi-lo > 65535 {
+					continue
+				}
+				if arr, ok := op.Operands[i+2].(types.Array); ok {
+					for j, o := range arr {
+						m[lo+j] = unicodeText(stringBytes(o))
+					}
+					continue
+				}
+				dst := stringBytes(op.Operands[i+2])
+				if len(dst) < 2 {
+					continue
+				}
+				for c := lo; c <= hi; c++ {
+					// The last byte is incremented across the range
+					b := append([]byte(nil), dst...)
+					v := int(b[len(b)-2])<<8 | int(b[len(b)-1]) + c - lo
+					b[len(b)-2], b[len(b)-1] = byte(v>>8), byte(v)
+					m[c] = unicodeText(b)
+				}
+			}
+		}
+	}
+	return m, twoByte
+}
+
+func codeValue(b []byte) int {
+	v := 0
+	for _, c := range b {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+// unicodeText decodes UTF-16BE as used in ToUnicode CMaps
+func unicodeText(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+// glyphs splits the bytes of a shown string into glyphs
+func (f *fontInfo) glyphs(s []byte) []glyph {
+	n := 1
+	if f.twoByte {
+		n = 2
+	}
+	var out []glyph
+	for i := 0; i+n <= len(s); i += n {
+		code := s[i : i+n]
+		c := codeValue(code)
+		g := glyph{code: code, text: f.text(c)}
+		if w, ok := f.widths[c]; ok {
+			g.width = w
+		} else if f.coreFont != "" && c < 256 {
+			g.width = float64(font.CharWidth(f.coreFont, rune(c))) / 1000
+		} else {
+			g.width = f.defaultWidth
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+// text returns the Unicode text of a character code
+func (f *fontInfo) text(c int) string {
+	if s, ok := f.toUnicode[c]; ok {
+		return s
+	}
+	if name, ok := f.names[c]; ok {
+		if s := glyphNameText(name); s != "" {
+			return s
+		}
+	}
+	if f.twoByte {
+		return ""
+	}
+	// Assume a Latin encoding
+	return string(rune(c))
+}
+
+// glyphNames maps common glyph names that are not a single character
+var glyphNames = map[string]string{
+	"space": " ", "period": ".", "comma": ",", "hyphen": "-", "minus": "-",
+	"colon": ":", "semicolon": ";", "slash": "/", "underscore": "_",
+	"parenleft": "(", "parenright": ")", "at": "@", "numbersign": "#",
+	"zero": "0", "one": "1", "two": "2", "three": "3", "four": "4",
+	"five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+	"fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl",
+}
+
+// glyphNameText returns the text of a glyph name such as "A", "uni0041" or "comma"
+func glyphNameText(name string) string {
+	if len([]rune(name)) == 1 {
+		return name
+	}
+	if s, ok := glyphNames[name]; ok {
+		return s
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if b, err := hex.DecodeString(name[3:]); err == nil {
+			return unicodeText(b)
+		}
+	}
+	if strings.HasPrefix(name, "u") && len(name) >= 5 && len(name) <= 7 {
+		if v, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return string(rune(v))
+		}
+	}
+	return ""
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"os"
+
+	"pdf-toolbox/internal/cli"
 	"pdf-toolbox/internal/gui"
 )
 
 func main() {
+	// A command runs without the graphical interface; other arguments, such
+	// as a file opened from a file manager, still start the window
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	app := gui.NewApp()
 	app.Run()
 }
-
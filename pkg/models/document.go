@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Document represents a PDF or image file
 type Document struct {
 	Path      string
@@ -30,12 +32,16 @@ const (
 type SplitConfig struct {
 	PagesPerFile int
 	OutputDir    string
+	// NUp optionally imposes pages before splitting; PagesPerFile then counts sheets
+	NUp NUpOptions
 }
 
 // MergeConfig holds configuration for merging PDFs
 type MergeConfig struct {
 	InputFiles []string
 	OutputFile string
+	// Normalize optionally scales all pages to one paper size; empty PaperSize disables it
+	Normalize ResizeOptions
 }
 
 // DeletePagesConfig holds configuration for deleting pages
@@ -45,3 +51,457 @@ type DeletePagesConfig struct {
 	PagesToKeep []int
 }
 
+// PaperSize names a target page size understood by pdfcpu (e.g. "A4", "Letter")
+type PaperSize string
+
+const (
+	PaperA4     PaperSize = "A4"
+	PaperLetter PaperSize = "Letter"
+	PaperImage  PaperSize = "Image" // page matches the image size
+)
+
+// Orientation represents the page orientation
+type Orientation int
+
+const (
+	OrientationAuto Orientation = iota // follow the image aspect ratio
+	OrientationPortrait
+	OrientationLandscape
+)
+
+// FitMode controls how an image is placed inside the page area
+type FitMode int
+
+const (
+	FitContain FitMode = iota // scale to fit inside the margins, keep aspect ratio
+	FitFill                   // scale to cover the margins, cropping the overflow
+	FitStretch                // scale to the margins, ignoring aspect ratio
+	FitCenter                 // keep natural size (per DPI), centered
+)
+
+// ImagesToPDFConfig holds configuration for converting images to PDF
+type ImagesToPDFConfig struct {
+	InputFiles  []string
+	OutputFile  string
+	PageSize    PaperSize
+	Orientation Orientation
+	FitMode     FitMode
+	Margin      float64 // in points (1/72 inch)
+	DPI         int     // image resolution; 0 means 72
+	IgnoreEXIF  bool    // do not apply the EXIF orientation of JPEGs
+	Enhance     ScanEnhancement
+}
+
+// ScanEnhancement selects pre-processing steps that turn photos of paper
+// documents into clean scanned-looking pages
+type ScanEnhancement struct {
+	AutoCrop  bool // crop to the page edge
+	Deskew    bool // straighten rotated text lines
+	Contrast  bool // flatten shadows and stretch levels
+	Grayscale bool
+	Threshold bool // black-and-white (implies grayscale)
+}
+
+// Position anchors stamped text on the page
+type Position string
+
+const (
+	PosTopLeft      Position = "tl"
+	PosTopCenter    Position = "tc"
+	PosTopRight     Position = "tr"
+	PosBottomLeft   Position = "bl"
+	PosBottomCenter Position = "bc"
+	PosBottomRight  Position = "br"
+)
+
+// PageNumberingConfig holds configuration for stamping page or Bates numbers.
+// Format placeholders: {n} running counter, {page} page number, {pages} page count.
+type PageNumberingConfig struct {
+	InputFiles []string // numbered in order; {n} continues across files
+	OutputDir  string
+	Format     string // e.g. "Page {page} of {pages}" or "ACME{n}"
+	Start      int    // first value of {n}
+	Digits     int    // zero-pad {n} to this width
+	Position   Position
+	FontName   string
+	FontSize   int
+	Pages      []int // pages to number in each file; empty means all
+}
+
+// HeaderFooterConfig holds configuration for header and footer text.
+// Text placeholders: {filename}, {date}, {page}, {pages}, {title}.
+type HeaderFooterConfig struct {
+	InputFile  string
+	OutputFile string
+	Text       map[Position]string // top positions are the header, bottom ones the footer
+	DateFormat string              // Go time layout; empty means "2006-01-02"
+	FontName   string
+	FontSize   int
+	Pages      []int // pages to stamp; empty means all
+}
+
+// NUpOptions describes an N-up layout of several pages per sheet
+type NUpOptions struct {
+	PagesPerSheet int       // 2, 4, 6, 9 or 16; 0 or 1 means no imposition
+	PaperSize     PaperSize // sheet size; append "L" for landscape (e.g. "A4L")
+	ColumnMajor   bool      // fill columns first instead of rows
+	Border        bool      // draw a border around each page
+	Margin        float64   // in points around each page
+}
+
+// NUpConfig holds configuration for N-up imposition
+type NUpConfig struct {
+	InputFile  string
+	OutputFile string
+	NUpOptions
+}
+
+// BookletConfig holds configuration for saddle-stitch booklet imposition
+type BookletConfig struct {
+	InputFile          string
+	OutputFile         string
+	PaperSize          PaperSize // sheet size; sheets are always landscape
+	SheetsPerSignature int       // 0 keeps the whole document in one signature
+}
+
+// PageBox names a page boundary box
+type PageBox string
+
+const (
+	BoxMedia PageBox = "MediaBox"
+	BoxCrop  PageBox = "CropBox"
+	BoxTrim  PageBox = "TrimBox"
+)
+
+// Margins are distances from the page edges in points
+type Margins struct {
+	Top    float64
+	Right  float64
+	Bottom float64
+	Left   float64
+}
+
+// CropConfig holds configuration for cropping pages
+type CropConfig struct {
+	InputFile  string
+	OutputFile string
+	Pages      []int     // empty means all pages
+	Margins    Margins   // trimmed from the visible area of each page
+	Auto       bool      // crop to the detected content bounds instead of Margins
+	Padding    float64   // space kept around detected content, in points
+	Boxes      []PageBox // boxes to set; empty means CropBox only
+}
+
+// ResizeOptions describes how pages are scaled to a paper size
+type ResizeOptions struct {
+	PaperSize   PaperSize
+	Orientation Orientation // auto follows the orientation of each page
+	Center      bool        // center on the sheet; otherwise align to the top-left corner
+}
+
+// ResizeConfig holds configuration for resizing pages
+type ResizeConfig struct {
+	InputFile  string
+	OutputFile string
+	Pages      []int // empty means all pages
+	ResizeOptions
+}
+
+// InsertConfig holds configuration for inserting pages into a PDF
+type InsertConfig struct {
+	InputFile  string
+	OutputFile string
+	Page       int  // reference page in InputFile
+	After      bool // insert after Page instead of before it
+	// BlankPages inserts this many empty pages sized like Page when SourceFile is empty
+	BlankPages  int
+	SourceFile  string // PDF to take the inserted pages from
+	SourcePages []int  // pages of SourceFile to insert; empty means all
+}
+
+// ReplacePagesConfig holds configuration for swapping pages with pages of another PDF
+type ReplacePagesConfig struct {
+	InputFile   string
+	OutputFile  string
+	Pages       []int  // pages of InputFile to replace, in order
+	SourceFile  string // PDF to take the replacement pages from
+	SourcePages []int  // pages of SourceFile, one per entry of Pages; empty means all
+	// IgnoreSizes allows replacement pages whose size differs from the originals
+	IgnoreSizes bool
+}
+
+// Attachment describes a file embedded in a PDF
+type Attachment struct {
+	ID          string // key in the document's embedded files
+	FileName    string
+	Description string
+	Size        int64
+	Modified    time.Time // zero when not recorded
+}
+
+// FormField describes an interactive form field
+type FormField struct {
+	Name    string
+	Type    string   // Text, Date, CheckBox, ComboBox, ListBox or RadioButtonGroup
+	Options []string // choices of combo boxes, list boxes and radio buttons
+	Value   string   // check boxes use "true" and "false"; list box selections are comma separated
+	Pages   []int
+	Locked  bool
+}
+
+// FormFillConfig holds configuration for filling a form
+type FormFillConfig struct {
+	InputFile  string
+	OutputFile string
+	Values     map[string]string // field name to value, formatted as in FormField
+	Flatten    bool              // turn the filled fields into static page content
+}
+
+// FormBulkFillConfig holds configuration for filling a form once per CSV row
+type FormBulkFillConfig struct {
+	InputFile string
+	CSVFile   string // header row holds field names, each further row one filled copy
+	OutputDir string
+	Flatten   bool
+}
+
+// FlattenConfig holds configuration for burning interactive content into pages
+type FlattenConfig struct {
+	InputFile  string
+	OutputFile string
+	Forms      bool // form fields; the interactive form is removed
+	Comments   bool // notes, highlights, stamps, drawings and other markup
+	Links      bool
+}
+
+// Annotation describes an annotation on a page, such as a comment or highlight
+type Annotation struct {
+	Page     int
+	Type     string // PDF subtype, e.g. Text, Highlight, Ink, Link
+	Author   string
+	Contents string
+	Rect     [4]float64 // lower-left x, y and upper-right x, y in points
+	Modified time.Time  // zero when not recorded
+}
+
+// RemoveAnnotationsConfig holds configuration for deleting annotations.
+// An annotation is removed when it matches all non-empty filters.
+type RemoveAnnotationsConfig struct {
+	InputFile  string
+	OutputFile string
+	Types      []string
+	Authors    []string
+}
+
+// RedactArea is a rectangle to redact, in points from the bottom-left corner
+// of the page as displayed
+type RedactArea struct {
+	Page int
+	Rect [4]float64 // left, bottom, right, top
+}
+
+// RedactConfig holds configuration for permanently removing content
+type RedactConfig struct {
+	InputFile  string
+	OutputFile string
+	Areas      []RedactArea
+	Patterns   []string // regular expressions searched in the page text
+	Pages      []int    // pages searched for Patterns; empty means all
+	ReportFile string   // optional JSON report of what was removed
+}
+
+// Redaction is one redacted area in a RedactionReport
+type Redaction struct {
+	Page    int
+	Rect    [4]float64 // as in RedactArea
+	Pattern string     // the matching pattern, empty for areas given directly
+}
+
+// RedactionReport summarizes a redaction
+type RedactionReport struct {
+	Redactions         []Redaction
+	GlyphsRemoved      int
+	ImagesRemoved      int // images lying under a redaction, dropped entirely
+	ImagesCleared      int // images partly under a redaction, with those pixels blacked out
+	AnnotationsRemoved int
+}
+
+// SignatureInfo describes a digital signature and the result of verifying it
+type SignatureInfo struct {
+	Field       string
+	Kind        string // e.g. "form signature (authoritative, visible, signed) on page 1"
+	Signer      string // subject of the signer certificate
+	Issuer      string
+	SigningTime time.Time // zero when not recorded
+	Reason      string
+	Location    string
+	SubFilter   string
+	ByteRange   []int64 // offset and length pairs of the signed bytes
+	// WholeFile is true when the signed bytes reach the end of the file,
+	// i.e. nothing was appended after signing
+	WholeFile bool
+	Modified  string // "yes", "no" or "unknown"
+	Status    string // "valid", "invalid" or "unknown"
+	Details   string // why the status was reached
+	Trusted   bool   // the certificate chain leads to the trust store
+	Problems  []string
+}
+
+// SignatureAppearance places a visible signature on a page
+type SignatureAppearance struct {
+	Page      int
+	Rect      [4]float64 // left, bottom, right, top in points
+	ImageFile string     // optional picture drawn next to the signer details
+}
+
+// TimestampConfig configures the local time-stamping authority that stands
+// in for an RFC 3161 service. Its certificate should allow time stamping.
+type TimestampConfig struct {
+	CertFile string // .p12 or .pfx
+	Password string
+}
+
+// SignConfig holds configuration for digitally signing a PDF
+type SignConfig struct {
+	InputFile  string
+	OutputFile string
+	CertFile   string // .p12 or .pfx holding the key and certificate chain
+	Password   string
+	Reason     string
+	Location   string
+	Appearance *SignatureAppearance // nil for an invisible signature
+	Timestamp  *TimestampConfig     // nil to sign without a timestamp
+}
+
+// ValidationLevel selects how strictly a PDF is checked
+type ValidationLevel string
+
+const (
+	ValidationRelaxed ValidationLevel = "relaxed"
+	ValidationStrict  ValidationLevel = "strict"
+)
+
+// ValidationProblem is a structural problem found in a PDF
+type ValidationProblem struct {
+	Kind    string // "header", "xref", "offset", "missing", "trailer", "read" or "structure"
+	Object  int    // object number concerned, 0 when not object specific
+	Offset  int64  // byte offset concerned, -1 when unknown
+	Message string
+}
+
+// ValidationReport lists the problems found by validating a PDF
+type ValidationReport struct {
+	File     string
+	Level    ValidationLevel
+	Objects  int  // objects listed in the cross-reference data
+	Readable bool // the file can be opened despite any problems
+	Problems []ValidationProblem
+}
+
+// RepairReport describes a repaired copy of a PDF
+type RepairReport struct {
+	ObjectsRecovered   int   // objects found by scanning the file
+	StreamLengthsFixed int   // streams whose Length did not match their data
+	ReferencesDropped  []int // referenced objects that are missing or were left empty
+	// Remaining lists the problems a relaxed validation still finds in the
+	// repaired copy
+	Remaining []ValidationProblem
+}
+
+// PDFAViolation is a PDF/A-2b requirement a document does not meet
+type PDFAViolation struct {
+	Rule    string // "fonts", "encryption", "metadata", "output-intent", "transparency" or "actions"
+	Page    int    // page concerned, 0 when not page specific
+	Object  int    // object number concerned, 0 when unknown or direct
+	Message string
+}
+
+// PDFAReport lists the PDF/A-2b violations found in a PDF
+type PDFAReport struct {
+	File       string
+	Violations []PDFAViolation
+}
+
+// PDFAConversion describes a copy of a PDF converted towards PDF/A-2b
+type PDFAConversion struct {
+	Changes   []string        // what was changed, in order
+	Remaining []PDFAViolation // violations the conversion could not fix
+}
+
+// Font describes a font used in a PDF
+type Font struct {
+	Name     string // BaseFont without the subset prefix
+	Type     string // font subtype; Type0 fonts add their descendant, e.g. Type0/CIDFontType2
+	Encoding string
+	Embedded bool
+	Subset   bool  // only the glyphs used are embedded
+	Object   int   // object number of the font dictionary, 0 when direct
+	Pages    []int // pages drawing with the font, directly or through forms and annotations
+}
+
+// GrayscaleReport describes a PDF converted to grayscale
+type GrayscaleReport struct {
+	Pages      int   // pages in the document
+	ColorPages []int // pages that contained colour before the conversion
+	Skipped    int   // images and shadings that could not be converted and were left in colour
+}
+
+// ColorPageMapping places one page of a document in the colour or mono set
+type ColorPageMapping struct {
+	Page     int    // page in the original document
+	Color    bool   // whether the page is in the colour set
+	File     string // output file holding the page
+	FilePage int    // page within File
+}
+
+// ColorSplit describes a PDF split into colour and mono pages
+type ColorSplit struct {
+	Pages      int    // pages in the document
+	ColorPages []int  // pages that print in colour
+	ColorFile  string // colour pages, empty if there are none
+	MonoFile   string // mono pages, empty if there are none
+	MapFile    string // CSV page map for collating the printed sheets
+	Map        []ColorPageMapping
+}
+
+// SanitizeConfig holds configuration for removing active content
+type SanitizeConfig struct {
+	InputFile  string
+	OutputFile string
+	ReportFile string // optional JSON report of what was removed
+}
+
+// SanitizeRemoval is one piece of active content removed from a PDF
+type SanitizeRemoval struct {
+	Kind     string // JavaScript, Launch, URI, SubmitForm, ImportData, GoToR, GoToE, Rendition, EmbeddedFile or XFA
+	Location string // what carried it, e.g. "document open action" or "link annotation"
+	Page     int    // page of the annotation or page action, 0 for the document
+	Object   int    // object number of the action, file or annotation, 0 if direct
+	Detail   string // script excerpt, target or file name
+}
+
+// SanitizeReport lists the active content removed by Sanitize
+type SanitizeReport struct {
+	Removed []SanitizeRemoval
+}
+
+// PageLabelStyle is the numbering style of a page label range, named as in
+// the PDF PageLabels entry
+type PageLabelStyle string
+
+const (
+	LabelNone         PageLabelStyle = ""  // prefix only
+	LabelDecimal      PageLabelStyle = "D" // 1, 2, 3
+	LabelRomanUpper   PageLabelStyle = "R" // I, II, III
+	LabelRomanLower   PageLabelStyle = "r" // i, ii, iii
+	LabelLettersUpper PageLabelStyle = "A" // A to Z, then AA to ZZ
+	LabelLettersLower PageLabelStyle = "a" // a to z, then aa to zz
+)
+
+// PageLabelRange labels the pages from FirstPage up to the next range
+type PageLabelRange struct {
+	FirstPage int // physical page the range starts on, from 1
+	Style     PageLabelStyle
+	Prefix    string
+	Start     int // number of the first page of the range, from 1
+}